@@ -8,6 +8,7 @@ require (
 	github.com/grokify/gogithub v0.12.1
 	github.com/grokify/mogo v0.74.4
 	github.com/plexusone/dashforge v0.2.0
+	golang.org/x/mod v0.40.0
 	golang.org/x/oauth2 v0.36.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,320 @@
+// Package campaign rolls remediation pull requests out across many
+// repositories in waves.
+package campaign
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// Config configures a remediation campaign.
+type Config struct {
+	Name string `json:"name"`
+	// Canary lists repositories (full names or path.Match patterns such
+	// as "myorg/api-*") remediated in the first wave.
+	Canary []string `json:"canary,omitempty"`
+	// Waves are cumulative percentages of the remaining repositories,
+	// such as [10, 50, 100]. A final 100 is implied.
+	Waves []int `json:"waves,omitempty"`
+	// MaxOpenPRsPerOrg caps the campaign's open pull requests in each
+	// organization. Zero is unlimited.
+	MaxOpenPRsPerOrg int `json:"maxOpenPrsPerOrg,omitempty"`
+	// Allow restricts the campaign to matching repositories. Empty allows
+	// every repository.
+	Allow []string `json:"allow,omitempty"`
+	// Block excludes matching repositories. It wins over Allow.
+	Block []string `json:"block,omitempty"`
+	// HaltOnFailure stops the rollout after a wave with failed repositories.
+	HaltOnFailure bool `json:"haltOnFailure,omitempty"`
+	DryRun        bool `json:"dryRun,omitempty"`
+}
+
+// Status is the state of one repository in a campaign.
+type Status string
+
+// Status constants.
+const (
+	StatusPending Status = "pending" // Not yet remediated
+	StatusOpen    Status = "open"    // Pull request open
+	StatusMerged  Status = "merged"
+	StatusClosed  Status = "closed" // Pull request closed without merging
+	StatusFailed  Status = "failed"
+	StatusDryRun  Status = "dry-run" // Planned in a dry run
+	// StatusRolledBack marks a remediation that was rolled back.
+	StatusRolledBack Status = "rolled-back"
+)
+
+// done reports whether a repository needs no further action.
+func (s Status) done() bool {
+	return s != StatusPending && s != StatusOpen
+}
+
+// RepoState tracks one repository in a campaign.
+type RepoState struct {
+	Repo      model.Repo              `json:"repo"`
+	Missing   []model.MissingWorkflow `json:"missing,omitempty"`
+	Status    Status                  `json:"status"`
+	PRURL     string                  `json:"prUrl,omitempty"`
+	PRNumber  int                     `json:"prNumber,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+	UpdatedAt time.Time               `json:"updatedAt,omitzero"`
+	// Applied is the result of the remediation, with the commits and
+	// pre-change contents needed to roll it back.
+	Applied *model.RemediationResult `json:"applied,omitempty"`
+	// Rollback is the result of rolling the remediation back.
+	Rollback *model.RemediationResult `json:"rollback,omitempty"`
+}
+
+// Wave is a group of repositories remediated together.
+type Wave struct {
+	Number int         `json:"number"`
+	Canary bool        `json:"canary,omitempty"`
+	Repos  []RepoState `json:"repos"`
+}
+
+// Excluded is a non-compliant repository left out of a campaign.
+type Excluded struct {
+	Repo   string `json:"repo"`
+	Reason string `json:"reason"`
+}
+
+// State is the persisted state of a campaign.
+type State struct {
+	Config    Config     `json:"config"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Waves     []Wave     `json:"waves"`
+	Excluded  []Excluded `json:"excluded,omitempty"`
+}
+
+// Plan creates a campaign for the non-compliant repositories in a check
+// result. Canary repositories form the first wave; the rest are sorted by
+// name and split by the wave percentages. Skipped and errored repositories,
+// and those with no missing workflows to remediate, are not included.
+func Plan(cfg Config, result *model.CheckResult) (*State, error) {
+	for _, patterns := range [][]string{cfg.Canary, cfg.Allow, cfg.Block} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid repository pattern %q: %w", p, err)
+			}
+		}
+	}
+	waves, err := normalizeWaves(cfg.Waves)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s := &State{Config: cfg, CreatedAt: now, UpdatedAt: now}
+
+	var canary, rest []RepoState
+	for _, r := range result.Repos {
+		if r.Compliant || r.Skipped || r.Error != "" || len(r.Missing) == 0 {
+			continue
+		}
+		switch {
+		case matchAny(cfg.Block, r.FullName):
+			s.Excluded = append(s.Excluded, Excluded{Repo: r.FullName, Reason: "blocklisted"})
+			continue
+		case len(cfg.Allow) > 0 && !matchAny(cfg.Allow, r.FullName):
+			s.Excluded = append(s.Excluded, Excluded{Repo: r.FullName, Reason: "not allowlisted"})
+			continue
+		}
+
+		rs := RepoState{
+			Repo: model.Repo{
+				Owner:         r.Owner,
+				Name:          r.Name,
+				FullName:      r.FullName,
+				HTMLURL:       r.HTMLURL,
+				DefaultBranch: r.DefaultBranch,
+				Languages:     r.Languages,
+			},
+			Missing: r.Missing,
+			Status:  StatusPending,
+		}
+		if matchAny(cfg.Canary, r.FullName) {
+			canary = append(canary, rs)
+		} else {
+			rest = append(rest, rs)
+		}
+	}
+
+	byName := func(a, b RepoState) int { return strings.Compare(a.Repo.FullName, b.Repo.FullName) }
+	slices.SortFunc(canary, byName)
+	slices.SortFunc(rest, byName)
+
+	if len(canary) > 0 {
+		s.Waves = append(s.Waves, Wave{Canary: true, Repos: canary})
+	}
+	start := 0
+	for _, pct := range waves {
+		end := int(math.Ceil(float64(len(rest)) * float64(pct) / 100))
+		if end > start {
+			s.Waves = append(s.Waves, Wave{Repos: rest[start:end]})
+			start = end
+		}
+	}
+	for i := range s.Waves {
+		s.Waves[i].Number = i + 1
+	}
+
+	return s, nil
+}
+
+func normalizeWaves(waves []int) ([]int, error) {
+	prev := 0
+	for _, pct := range waves {
+		if pct <= prev || pct > 100 {
+			return nil, fmt.Errorf("wave percentages must increase within 1-100: %v", waves)
+		}
+		prev = pct
+	}
+	if prev < 100 {
+		waves = append(slices.Clone(waves), 100)
+	}
+	return waves, nil
+}
+
+func matchAny(patterns []string, fullName string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, fullName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads campaign state from a JSON file.
+func Load(file string) (*State, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading campaign state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing campaign state %s: %w", file, err)
+	}
+	return &s, nil
+}
+
+// Save writes campaign state to a JSON file. The file is replaced
+// atomically so an interrupted save leaves the previous state intact.
+func (s *State) Save(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding campaign state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".campaign-*.json")
+	if err != nil {
+		return fmt.Errorf("saving campaign state: %w", err)
+	}
+	_, werr := tmp.Write(append(data, '\n'))
+	cerr := tmp.Close()
+	if err := errors.Join(werr, cerr); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("saving campaign state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("saving campaign state: %w", err)
+	}
+	return nil
+}
+
+// Wave status constants.
+const (
+	WaveWaiting    = "waiting"     // An earlier wave has not finished
+	WaveInProgress = "in-progress" // Some repositories are pending or open
+	WaveComplete   = "complete"
+	WaveHalted     = "halted" // Stopped by a failed earlier wave
+)
+
+// WaveSummary counts the repository states of a wave.
+type WaveSummary struct {
+	Number  int    `json:"number"`
+	Canary  bool   `json:"canary,omitempty"`
+	Status  string `json:"status"`
+	Total   int    `json:"total"`
+	Pending int    `json:"pending"`
+	Open    int    `json:"open"`
+	Merged  int    `json:"merged"`
+	Closed  int    `json:"closed"`
+	Failed  int    `json:"failed"`
+	DryRun  int    `json:"dryRun,omitempty"`
+	// RolledBack counts rolled back remediations.
+	RolledBack int `json:"rolledBack,omitempty"`
+}
+
+// Summary reports the merge and failure status of each wave.
+func (s *State) Summary() []WaveSummary {
+	current := s.currentWave()
+	var out []WaveSummary
+	for i, w := range s.Waves {
+		ws := WaveSummary{Number: w.Number, Canary: w.Canary, Total: len(w.Repos)}
+		for _, r := range w.Repos {
+			switch r.Status {
+			case StatusPending:
+				ws.Pending++
+			case StatusOpen:
+				ws.Open++
+			case StatusMerged:
+				ws.Merged++
+			case StatusDryRun:
+				ws.DryRun++
+			case StatusClosed:
+				ws.Closed++
+			case StatusFailed:
+				ws.Failed++
+			case StatusRolledBack:
+				ws.RolledBack++
+			}
+		}
+		switch {
+		case ws.Pending+ws.Open == 0:
+			ws.Status = WaveComplete
+		case i >= current && s.halted(current):
+			ws.Status = WaveHalted
+		case i == current:
+			ws.Status = WaveInProgress
+		default:
+			ws.Status = WaveWaiting
+		}
+		out = append(out, ws)
+	}
+	return out
+}
+
+// currentWave returns the index of the first wave with pending or open
+// repositories, or -1 when every wave is done.
+func (s *State) currentWave() int {
+	for i, w := range s.Waves {
+		if slices.ContainsFunc(w.Repos, func(r RepoState) bool { return !r.Status.done() }) {
+			return i
+		}
+	}
+	return -1
+}
+
+// halted reports whether a failure in a wave before i stops the rollout.
+func (s *State) halted(i int) bool {
+	if !s.Config.HaltOnFailure || i < 0 {
+		return false
+	}
+	for _, w := range s.Waves[:i] {
+		if slices.ContainsFunc(w.Repos, func(r RepoState) bool { return r.Status == StatusFailed }) {
+			return true
+		}
+	}
+	return false
+}
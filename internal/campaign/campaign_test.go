@@ -0,0 +1,270 @@
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/internal/tracker"
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+func checkResult(names ...string) *model.CheckResult {
+	result := &model.CheckResult{}
+	for _, name := range names {
+		owner, repo, _ := strings.Cut(name, "/")
+		result.Repos = append(result.Repos, model.RepoCheckResult{
+			Owner:    owner,
+			Name:     repo,
+			FullName: name,
+			Missing:  []model.MissingWorkflow{{WorkflowType: "go-ci"}},
+		})
+	}
+	return result
+}
+
+func waveRepos(s *State) [][]string {
+	var out [][]string
+	for _, w := range s.Waves {
+		var names []string
+		for _, r := range w.Repos {
+			names = append(names, r.Repo.FullName)
+		}
+		out = append(out, names)
+	}
+	return out
+}
+
+func TestPlan(t *testing.T) {
+	result := checkResult("a/r1", "a/r2", "a/r3", "a/r4", "a/canary", "b/r5", "b/legacy-1", "c/r6")
+	result.Repos = append(result.Repos,
+		model.RepoCheckResult{Owner: "a", Name: "ok", FullName: "a/ok", Compliant: true},
+		model.RepoCheckResult{Owner: "a", Name: "skip", FullName: "a/skip", Skipped: true},
+		model.RepoCheckResult{Owner: "a", Name: "partial", FullName: "a/partial", ComplianceLevel: model.ComplianceLevelPartial},
+	)
+	result.Repos[4].DefaultBranch = "master"
+
+	s, err := Plan(Config{
+		Canary: []string{"a/canary"},
+		Waves:  []int{25, 50},
+		Allow:  []string{"a/*", "b/*"},
+		Block:  []string{"b/legacy-*"},
+	}, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"a/canary"},
+		{"a/r1", "a/r2"},
+		{"a/r3"},
+		{"a/r4", "b/r5"},
+	}
+	if got := waveRepos(s); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("waves = %v, want %v", got, want)
+	}
+	if !s.Waves[0].Canary || s.Waves[3].Number != 4 {
+		t.Errorf("waves = %+v", s.Waves)
+	}
+	if got := s.Waves[0].Repos[0].Repo.DefaultBranch; got != "master" {
+		t.Errorf("canary default branch = %q, want master", got)
+	}
+	wantExcluded := []Excluded{{Repo: "b/legacy-1", Reason: "blocklisted"}, {Repo: "c/r6", Reason: "not allowlisted"}}
+	if !slices.Equal(s.Excluded, wantExcluded) {
+		t.Errorf("excluded = %v, want %v", s.Excluded, wantExcluded)
+	}
+
+	for _, cfg := range []Config{{Waves: []int{50, 20}}, {Waves: []int{120}}, {Allow: []string{"["}}} {
+		if _, err := Plan(cfg, result); err == nil {
+			t.Errorf("Plan(%+v) expected error", cfg)
+		}
+	}
+}
+
+type fakeApplier struct {
+	applied []string
+	fail    map[string]bool
+}
+
+func (f *fakeApplier) Apply(_ context.Context, plan model.RemediationPlan) (*model.RemediationResult, error) {
+	result := &model.RemediationResult{Repo: plan.Repo, DryRun: plan.DryRun}
+	if plan.DryRun {
+		result.Success = true
+		return result, nil
+	}
+	f.applied = append(f.applied, plan.Repo.FullName)
+	if f.fail[plan.Repo.FullName] {
+		result.Error = "boom"
+		return result, errors.New("boom")
+	}
+	result.Success = true
+	result.PRNumber = len(f.applied)
+	result.PRURL = fmt.Sprintf("https://github.com/%s/pull/%d", plan.Repo.FullName, result.PRNumber)
+	return result, nil
+}
+
+type fakeStatus map[string]string
+
+func (f fakeStatus) PRState(_ context.Context, repo model.Repo, _ int) (string, error) {
+	if state, ok := f[repo.FullName]; ok {
+		return state, nil
+	}
+	return "open", nil
+}
+
+func planner(_ context.Context, repo model.Repo, _ []model.MissingWorkflow) (model.RemediationPlan, error) {
+	return model.RemediationPlan{Repo: repo, Patches: []model.Patch{{Path: "x", Operation: "create"}}}, nil
+}
+
+func TestRunnerWavesAndCaps(t *testing.T) {
+	s, err := Plan(Config{
+		Name:             "rollout",
+		Canary:           []string{"a/canary"},
+		MaxOpenPRsPerOrg: 2,
+	}, checkResult("a/canary", "a/r1", "a/r2", "a/r3", "b/r4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applier := &fakeApplier{}
+	status := fakeStatus{}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "campaign.json")
+	prs := tracker.New(filepath.Join(dir, "remediation.json"))
+	runner := &Runner{Planner: planner, Applier: applier, Status: status, StateFile: file, Tracker: prs}
+	ctx := context.Background()
+
+	// Only the canary wave runs until it is merged.
+	if err := runner.Run(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Run(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(applier.applied, []string{"a/canary"}) {
+		t.Fatalf("applied = %v, want canary only", applier.applied)
+	}
+
+	// Resume from disk after the canary merges; org a is capped at 2 open PRs.
+	status["a/canary"] = "merged"
+	s, err = Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Run(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(applier.applied, []string{"a/canary", "a/r1", "a/r2", "b/r4"}) {
+		t.Fatalf("applied = %v", applier.applied)
+	}
+
+	status["a/r1"] = "closed"
+	if err := runner.Run(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if applier.applied[len(applier.applied)-1] != "a/r3" {
+		t.Fatalf("applied = %v, want a/r3 after a PR closed", applier.applied)
+	}
+
+	if got := len(prs.Status.PRs); got != 5 || prs.Status.PRs[0].Campaign != "rollout" {
+		t.Errorf("tracked %d PRs (%+v), want 5", got, prs.Status.PRs)
+	}
+
+	summary := s.Summary()
+	want := []WaveSummary{
+		{Number: 1, Canary: true, Status: WaveComplete, Total: 1, Merged: 1},
+		{Number: 2, Status: WaveInProgress, Total: 4, Open: 3, Closed: 1},
+	}
+	if !slices.Equal(summary, want) {
+		t.Errorf("Summary() = %+v, want %+v", summary, want)
+	}
+}
+
+func TestRunnerHaltOnFailure(t *testing.T) {
+	s, err := Plan(Config{Canary: []string{"a/canary"}, HaltOnFailure: true}, checkResult("a/canary", "a/r1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	applier := &fakeApplier{fail: map[string]bool{"a/canary": true}}
+	runner := &Runner{Planner: planner, Applier: applier}
+
+	for range 2 {
+		if err := runner.Run(context.Background(), s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(applier.applied, []string{"a/canary"}) {
+		t.Errorf("applied = %v, want rollout halted after canary", applier.applied)
+	}
+	if rs := s.Waves[0].Repos[0]; rs.Status != StatusFailed || rs.Error == "" {
+		t.Errorf("canary = %+v, want failed", rs)
+	}
+	if got := s.Summary()[1].Status; got != WaveHalted {
+		t.Errorf("wave 2 status = %q, want %q", got, WaveHalted)
+	}
+}
+
+func TestRunnerDryRun(t *testing.T) {
+	s, err := Plan(Config{DryRun: true}, checkResult("a/r1", "a/r2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	applier := &fakeApplier{}
+	if err := (&Runner{Planner: planner, Applier: applier}).Run(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+	if len(applier.applied) != 0 {
+		t.Errorf("dry run applied %v", applier.applied)
+	}
+	if got := s.Summary()[0]; got.DryRun != 2 || got.Status != WaveComplete {
+		t.Errorf("summary = %+v", got)
+	}
+}
+
+type fakeRollbacker struct {
+	rolledBack []string
+}
+
+func (f *fakeRollbacker) Rollback(_ context.Context, applied *model.RemediationResult) (*model.RemediationResult, error) {
+	f.rolledBack = append(f.rolledBack, applied.Repo.FullName)
+	return &model.RemediationResult{Repo: applied.Repo, Success: true, Rollback: true}, nil
+}
+
+func TestRunnerRollback(t *testing.T) {
+	s, err := Plan(Config{}, checkResult("a/r1", "a/r2", "b/r3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb := &fakeRollbacker{}
+	status := fakeStatus{"a/r2": "merged"}
+	runner := &Runner{Planner: planner, Applier: &fakeApplier{}, Status: status, Rollbacker: rb}
+	ctx := context.Background()
+	for range 2 {
+		if err := runner.Run(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := runner.Rollback(ctx, s, "a/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rb.rolledBack, []string{"a/r1", "a/r2"}) || len(results) != 2 || !results[0].Rollback {
+		t.Fatalf("rolled back %v, results %+v", rb.rolledBack, results)
+	}
+	if got := s.Summary()[0]; got.RolledBack != 2 || got.Open != 1 {
+		t.Errorf("summary = %+v", got)
+	}
+
+	// Rolled back repositories are not rolled back again.
+	if _, err := runner.Rollback(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rb.rolledBack, []string{"a/r1", "a/r2", "b/r3"}) {
+		t.Errorf("rolled back %v", rb.rolledBack)
+	}
+}
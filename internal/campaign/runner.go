@@ -0,0 +1,215 @@
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/plexusone/pipelineconductor/internal/tracker"
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// Planner builds the remediation plan for a repository.
+type Planner func(ctx context.Context, repo model.Repo, missing []model.MissingWorkflow) (model.RemediationPlan, error)
+
+// Applier applies a remediation plan. remediator.PRApplier satisfies it.
+type Applier interface {
+	Apply(ctx context.Context, plan model.RemediationPlan) (*model.RemediationResult, error)
+}
+
+// StatusChecker reports the state of a pull request: "open", "merged" or
+// "closed". remediator.PRApplier satisfies it.
+type StatusChecker interface {
+	PRState(ctx context.Context, repo model.Repo, number int) (string, error)
+}
+
+// Rollbacker rolls back an applied remediation. remediator.PRApplier
+// satisfies it.
+type Rollbacker interface {
+	Rollback(ctx context.Context, applied *model.RemediationResult) (*model.RemediationResult, error)
+}
+
+// Runner advances campaigns.
+type Runner struct {
+	Planner Planner
+	Applier Applier
+	// Status refreshes open pull requests. Nil leaves them open.
+	Status StatusChecker
+	// StateFile, when set, is saved after every change so an interrupted
+	// run can be resumed with Load.
+	StateFile string
+	// Tracker, when set, records every opened pull request under the
+	// campaign's name and is saved along with the campaign state.
+	Tracker *tracker.Tracker
+	// Rollbacker rolls back remediations for Rollback.
+	Rollbacker Rollbacker
+}
+
+// Run advances a campaign as far as it can: it refreshes open pull
+// requests, then remediates the pending repositories of the current wave
+// within the per-org cap. A wave starts only once every repository in the
+// previous wave is merged, closed or failed. Run is idempotent and is
+// meant to be called repeatedly until every wave is complete.
+func (r *Runner) Run(ctx context.Context, s *State) error {
+	if err := r.refresh(ctx, s); err != nil {
+		return err
+	}
+
+	current := s.currentWave()
+	if current < 0 || s.halted(current) {
+		return nil
+	}
+
+	open := s.openPRsByOrg()
+	wave := &s.Waves[current]
+	for i := range wave.Repos {
+		rs := &wave.Repos[i]
+		if rs.Status != StatusPending {
+			continue
+		}
+		if limit := s.Config.MaxOpenPRsPerOrg; limit > 0 && open[rs.Repo.Owner] >= limit {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.remediate(ctx, s, rs)
+		if rs.Status == StatusOpen {
+			open[rs.Repo.Owner]++
+		}
+		if err := r.save(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Runner) remediate(ctx context.Context, s *State, rs *RepoState) {
+	defer func() { rs.UpdatedAt = time.Now() }()
+
+	plan, err := r.Planner(ctx, rs.Repo, rs.Missing)
+	if err != nil {
+		rs.Status = StatusFailed
+		rs.Error = fmt.Sprintf("planning: %v", err)
+		return
+	}
+	plan.DryRun = plan.DryRun || s.Config.DryRun
+
+	result, err := r.Applier.Apply(ctx, plan)
+	switch {
+	case err != nil:
+		rs.Status = StatusFailed
+		rs.Error = err.Error()
+	case result.DryRun:
+		rs.Status = StatusDryRun
+	default:
+		rs.Status = StatusOpen
+		rs.PRURL = result.PRURL
+		rs.PRNumber = result.PRNumber
+		rs.Error = ""
+		rs.Applied = result
+		if r.Tracker != nil {
+			r.Tracker.Record(plan, result, s.Config.Name)
+		}
+	}
+}
+
+// Rollback rolls back the campaign's open and merged remediations, or
+// only those of repositories matching repos (full names or patterns) when
+// any are given. Rolled back repositories are marked rolled-back and keep
+// the rollback result; failures are recorded and do not stop the
+// rollback. It returns the rollback results.
+func (r *Runner) Rollback(ctx context.Context, s *State, repos ...string) ([]model.RemediationResult, error) {
+	if r.Rollbacker == nil {
+		return nil, errors.New("runner has no rollbacker")
+	}
+	var results []model.RemediationResult
+	for w := range s.Waves {
+		for i := range s.Waves[w].Repos {
+			rs := &s.Waves[w].Repos[i]
+			if rs.Status != StatusOpen && rs.Status != StatusMerged || rs.Applied == nil {
+				continue
+			}
+			if len(repos) > 0 && !matchAny(repos, rs.Repo.FullName) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+
+			result, err := r.Rollbacker.Rollback(ctx, rs.Applied)
+			rs.Rollback = result
+			rs.UpdatedAt = time.Now()
+			if err != nil {
+				rs.Error = err.Error()
+			} else {
+				rs.Status = StatusRolledBack
+				rs.Error = ""
+			}
+			if result != nil {
+				results = append(results, *result)
+			}
+			if err := r.save(s); err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// refresh updates the status of open pull requests.
+func (r *Runner) refresh(ctx context.Context, s *State) error {
+	if r.Status == nil {
+		return nil
+	}
+	changed := false
+	for w := range s.Waves {
+		for i := range s.Waves[w].Repos {
+			rs := &s.Waves[w].Repos[i]
+			if rs.Status != StatusOpen || rs.PRNumber == 0 {
+				continue
+			}
+			state, err := r.Status.PRState(ctx, rs.Repo, rs.PRNumber)
+			if err != nil {
+				return fmt.Errorf("refreshing %s: %w", rs.Repo.FullName, err)
+			}
+			if next := Status(state); next != rs.Status && (next == StatusMerged || next == StatusClosed) {
+				rs.Status = next
+				rs.UpdatedAt = time.Now()
+				changed = true
+			}
+		}
+	}
+	if changed {
+		return r.save(s)
+	}
+	return nil
+}
+
+func (r *Runner) save(s *State) error {
+	s.UpdatedAt = time.Now()
+	if r.Tracker != nil {
+		if err := r.Tracker.Save(); err != nil {
+			return err
+		}
+	}
+	if r.StateFile == "" {
+		return nil
+	}
+	return s.Save(r.StateFile)
+}
+
+// openPRsByOrg counts the campaign's open pull requests per organization.
+func (s *State) openPRsByOrg() map[string]int {
+	open := make(map[string]int)
+	for _, w := range s.Waves {
+		for _, rs := range w.Repos {
+			if rs.Status == StatusOpen {
+				open[rs.Repo.Owner]++
+			}
+		}
+	}
+	return open
+}
@@ -28,7 +28,12 @@ type Collector interface {
 	// GetLatestWorkflowRun returns the most recent workflow run.
 	GetLatestWorkflowRun(ctx context.Context, repo model.Repo, workflowID int64) (*model.WorkflowRun, error)
 
+	// ListWorkflowRuns returns up to limit completed runs of a workflow on
+	// a branch, newest first.
+	ListWorkflowRuns(ctx context.Context, repo model.Repo, workflowID int64, branch string, limit int) ([]model.WorkflowRun, error)
+
 	// GetFileContent returns the content of a file from a repository.
+	// Errors for missing files match fs.ErrNotExist.
 	GetFileContent(ctx context.Context, repo model.Repo, path string) (string, error)
 
 	// GetLanguages returns the languages used in a repository.
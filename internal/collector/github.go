@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -271,15 +273,22 @@ func (c *GitHubCollector) GetWorkflows(ctx context.Context, repo model.Repo) ([]
 	var result []model.Workflow
 	for _, wf := range workflows.Workflows {
 		workflow := model.Workflow{
+			ID:    wf.GetID(),
 			Name:  wf.GetName(),
 			Path:  wf.GetPath(),
 			State: wf.GetState(),
 		}
 
-		// Fetch workflow content
+		// Fetch and parse workflow content
 		content, err := c.GetFileContent(ctx, repo, wf.GetPath())
 		if err == nil {
 			workflow.Content = content
+			if parsed, err := ParseWorkflow([]byte(content), wf.GetPath()); err == nil {
+				workflow.Triggers = parsed.Triggers
+				workflow.Jobs = parsed.Jobs
+				workflow.UsesReusableWorkflow = parsed.UsesReusableWorkflow
+				workflow.ReusableWorkflowRefs = parsed.ReusableWorkflowRefs
+			}
 		}
 
 		result = append(result, workflow)
@@ -288,12 +297,50 @@ func (c *GitHubCollector) GetWorkflows(ctx context.Context, repo model.Repo) ([]
 	return result, nil
 }
 
-// GetBranchProtection returns branch protection settings.
+// GetBranchProtection returns the effective branch protection, merging
+// classic branch protection with the repository, organization, and
+// enterprise rulesets that apply to the branch.
 func (c *GitHubCollector) GetBranchProtection(ctx context.Context, repo model.Repo, branch string) (*model.BranchProtection, error) {
+	bp, err := c.getClassicProtection(ctx, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rulesets may not be readable with the token's permissions, so
+	// failures to read them are recorded as warnings rather than failing
+	// the collection.
+	rules, resp, err := c.client.Repositories.GetRulesForBranch(ctx, repo.Owner, repo.Name, branch, nil)
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			bp.Warnings = append(bp.Warnings, fmt.Sprintf("getting rules for branch: %v", err))
+		}
+		return bp, nil
+	}
+
+	// Ruleset details provide names and bypass actors. Bypass actors are
+	// only visible to admins.
+	rulesets := make(map[int64]*github.RepositoryRuleset)
+	for _, id := range rulesetIDs(rules) {
+		rs, _, err := c.client.Repositories.GetRuleset(ctx, repo.Owner, repo.Name, id, true)
+		if err != nil {
+			bp.Warnings = append(bp.Warnings, fmt.Sprintf("getting ruleset %d, bypass actors unknown: %v", id, err))
+			continue
+		}
+		rulesets[id] = rs
+	}
+
+	applyBranchRules(bp, rules, rulesets)
+	return bp, nil
+}
+
+// getClassicProtection returns classic branch protection settings.
+func (c *GitHubCollector) getClassicProtection(ctx context.Context, repo model.Repo, branch string) (*model.BranchProtection, error) {
 	protection, resp, err := c.client.Repositories.GetBranchProtection(ctx, repo.Owner, repo.Name, branch)
 	if err != nil {
 		if resp != nil && resp.StatusCode == 404 {
-			return &model.BranchProtection{Branch: branch, Enabled: false}, nil
+			// An unprotected branch allows force pushes and deletions
+			// unless a ruleset blocks them.
+			return &model.BranchProtection{Branch: branch, AllowForcePushes: true, AllowDeletions: true}, nil
 		}
 		return nil, fmt.Errorf("getting branch protection: %w", err)
 	}
@@ -302,10 +349,16 @@ func (c *GitHubCollector) GetBranchProtection(ctx context.Context, repo model.Re
 		Branch:  branch,
 		Enabled: true,
 	}
+	classic := func(rule string) {
+		bp.Rules = append(bp.Rules, model.ProtectionRule{Rule: rule, SourceType: model.ProtectionSourceClassic})
+	}
 
 	if protection.RequiredPullRequestReviews != nil {
 		bp.RequireReviews = true
 		bp.RequiredReviewers = protection.RequiredPullRequestReviews.RequiredApprovingReviewCount
+		bp.RequireCodeOwnerReviews = protection.RequiredPullRequestReviews.RequireCodeOwnerReviews
+		bp.DismissStaleReviews = protection.RequiredPullRequestReviews.DismissStaleReviews
+		classic(ruleTypePullRequest)
 	}
 
 	if protection.RequiredStatusChecks != nil {
@@ -313,6 +366,7 @@ func (c *GitHubCollector) GetBranchProtection(ctx context.Context, repo model.Re
 		if protection.RequiredStatusChecks.Contexts != nil {
 			bp.RequiredStatusChecks = *protection.RequiredStatusChecks.Contexts
 		}
+		classic(ruleTypeRequiredStatusChecks)
 	}
 
 	if protection.EnforceAdmins != nil {
@@ -321,19 +375,174 @@ func (c *GitHubCollector) GetBranchProtection(ctx context.Context, repo model.Re
 
 	if protection.RequiredSignatures != nil && protection.RequiredSignatures.Enabled != nil {
 		bp.RequireSignedCommits = *protection.RequiredSignatures.Enabled
+		if bp.RequireSignedCommits {
+			classic(ruleTypeRequiredSignatures)
+		}
+	}
+
+	if protection.RequiredConversationResolution != nil {
+		bp.RequireConversationResolution = protection.RequiredConversationResolution.Enabled
+	}
+
+	if protection.RequireLinearHistory != nil && protection.RequireLinearHistory.Enabled {
+		bp.RequireLinearHistory = true
+		classic(ruleTypeRequiredLinearHistory)
 	}
 
 	if protection.AllowForcePushes != nil {
 		bp.AllowForcePushes = protection.AllowForcePushes.Enabled
 	}
+	if !bp.AllowForcePushes {
+		classic(ruleTypeNonFastForward)
+	}
 
 	if protection.AllowDeletions != nil {
 		bp.AllowDeletions = protection.AllowDeletions.Enabled
 	}
+	if !bp.AllowDeletions {
+		classic(ruleTypeDeletion)
+	}
 
 	return bp, nil
 }
 
+// Ruleset rule types recorded in model.ProtectionRule.
+const (
+	ruleTypePullRequest           = "pull_request"
+	ruleTypeRequiredStatusChecks  = "required_status_checks"
+	ruleTypeRequiredSignatures    = "required_signatures"
+	ruleTypeRequiredLinearHistory = "required_linear_history"
+	ruleTypeNonFastForward        = "non_fast_forward"
+	ruleTypeDeletion              = "deletion"
+)
+
+// rulesetIDs returns the IDs of rulesets contributing branch rules, in
+// the order they first appear.
+func rulesetIDs(rules *github.BranchRules) []int64 {
+	if rules == nil {
+		return nil
+	}
+
+	var metas []github.BranchRuleMetadata
+	for _, r := range rules.PullRequest {
+		metas = append(metas, r.BranchRuleMetadata)
+	}
+	for _, r := range rules.RequiredStatusChecks {
+		metas = append(metas, r.BranchRuleMetadata)
+	}
+	for _, list := range [][]*github.BranchRuleMetadata{
+		rules.RequiredSignatures, rules.RequiredLinearHistory, rules.NonFastForward, rules.Deletion,
+	} {
+		for _, r := range list {
+			metas = append(metas, *r)
+		}
+	}
+
+	var ids []int64
+	for _, m := range metas {
+		if !slices.Contains(ids, m.RulesetID) {
+			ids = append(ids, m.RulesetID)
+		}
+	}
+	return ids
+}
+
+// applyBranchRules merges ruleset rules into classic protection. The most
+// restrictive setting wins: requirements are combined and a ruleset can
+// only tighten what classic protection allows.
+func applyBranchRules(bp *model.BranchProtection, rules *github.BranchRules, rulesets map[int64]*github.RepositoryRuleset) {
+	if rules == nil {
+		return
+	}
+	classicEnabled := bp.Enabled
+
+	record := func(rule string, meta github.BranchRuleMetadata) {
+		bp.Enabled = true
+		pr := model.ProtectionRule{
+			Rule:       rule,
+			SourceType: string(meta.RulesetSourceType),
+			Source:     meta.RulesetSource,
+			RulesetID:  meta.RulesetID,
+		}
+		if rs := rulesets[meta.RulesetID]; rs != nil {
+			pr.RulesetName = rs.Name
+		}
+		bp.Rules = append(bp.Rules, pr)
+	}
+
+	for _, r := range rules.PullRequest {
+		record(ruleTypePullRequest, r.BranchRuleMetadata)
+		bp.RequireReviews = true
+		bp.RequiredReviewers = max(bp.RequiredReviewers, r.Parameters.RequiredApprovingReviewCount)
+		bp.RequireCodeOwnerReviews = bp.RequireCodeOwnerReviews || r.Parameters.RequireCodeOwnerReview
+		bp.DismissStaleReviews = bp.DismissStaleReviews || r.Parameters.DismissStaleReviewsOnPush
+		bp.RequireConversationResolution = bp.RequireConversationResolution || r.Parameters.RequiredReviewThreadResolution
+	}
+	for _, r := range rules.RequiredStatusChecks {
+		record(ruleTypeRequiredStatusChecks, r.BranchRuleMetadata)
+		bp.RequireStatusChecks = true
+		for _, check := range r.Parameters.RequiredStatusChecks {
+			if !slices.Contains(bp.RequiredStatusChecks, check.Context) {
+				bp.RequiredStatusChecks = append(bp.RequiredStatusChecks, check.Context)
+			}
+		}
+	}
+	for _, r := range rules.RequiredSignatures {
+		record(ruleTypeRequiredSignatures, *r)
+		bp.RequireSignedCommits = true
+	}
+	for _, r := range rules.RequiredLinearHistory {
+		record(ruleTypeRequiredLinearHistory, *r)
+		bp.RequireLinearHistory = true
+	}
+	for _, r := range rules.NonFastForward {
+		record(ruleTypeNonFastForward, *r)
+	}
+	for _, r := range rules.Deletion {
+		record(ruleTypeDeletion, *r)
+	}
+
+	if !bp.Enabled {
+		return
+	}
+
+	if classicEnabled {
+		bp.AllowForcePushes = bp.AllowForcePushes && len(rules.NonFastForward) == 0
+		bp.AllowDeletions = bp.AllowDeletions && len(rules.Deletion) == 0
+	} else {
+		bp.AllowForcePushes = len(rules.NonFastForward) == 0
+		bp.AllowDeletions = len(rules.Deletion) == 0
+	}
+
+	for _, id := range rulesetIDs(rules) {
+		rs := rulesets[id]
+		if rs == nil {
+			continue
+		}
+		for _, actor := range rs.BypassActors {
+			ba := model.BypassActor{
+				ID:          actor.GetActorID(),
+				RulesetID:   id,
+				RulesetName: rs.Name,
+			}
+			if actor.ActorType != nil {
+				ba.Type = string(*actor.ActorType)
+			}
+			if actor.BypassMode != nil {
+				ba.Mode = string(*actor.BypassMode)
+			}
+			bp.BypassActors = append(bp.BypassActors, ba)
+		}
+	}
+
+	// Rulesets have no admin enforcement toggle; admins are only exempt
+	// when listed as bypass actors. Admins are only reported as enforced
+	// when every ruleset's bypass actors could be read.
+	if !classicEnabled {
+		bp.EnforceAdmins = len(bp.BypassActors) == 0 && len(rulesets) == len(rulesetIDs(rules))
+	}
+}
+
 // GetLatestWorkflowRun returns the most recent workflow run.
 func (c *GitHubCollector) GetLatestWorkflowRun(ctx context.Context, repo model.Repo, workflowID int64) (*model.WorkflowRun, error) {
 	runs, _, err := c.client.Actions.ListWorkflowRunsByID(ctx, repo.Owner, repo.Name, workflowID, &github.ListWorkflowRunsOptions{
@@ -347,24 +556,63 @@ func (c *GitHubCollector) GetLatestWorkflowRun(ctx context.Context, repo model.R
 		return nil, nil
 	}
 
-	run := runs.WorkflowRuns[0]
-	return &model.WorkflowRun{
-		ID:         run.GetID(),
-		WorkflowID: run.GetWorkflowID(),
-		Name:       run.GetName(),
-		Status:     run.GetStatus(),
-		Conclusion: run.GetConclusion(),
-		Branch:     run.GetHeadBranch(),
-		HeadSHA:    run.GetHeadSHA(),
-		CreatedAt:  run.GetCreatedAt().Time,
-		UpdatedAt:  run.GetUpdatedAt().Time,
-		HTMLURL:    run.GetHTMLURL(),
-	}, nil
+	run := toWorkflowRun(runs.WorkflowRuns[0])
+	return &run, nil
+}
+
+// ListWorkflowRuns returns up to limit completed runs of a workflow on a
+// branch, newest first.
+func (c *GitHubCollector) ListWorkflowRuns(ctx context.Context, repo model.Repo, workflowID int64, branch string, limit int) ([]model.WorkflowRun, error) {
+	opts := &github.ListWorkflowRunsOptions{
+		Branch:      branch,
+		Status:      "completed",
+		ListOptions: github.ListOptions{PerPage: min(limit, 100)},
+	}
+
+	var result []model.WorkflowRun
+	for len(result) < limit {
+		runs, resp, err := c.client.Actions.ListWorkflowRunsByID(ctx, repo.Owner, repo.Name, workflowID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("listing workflow runs: %w", err)
+		}
+		for _, run := range runs.WorkflowRuns {
+			if len(result) == limit {
+				break
+			}
+			result = append(result, toWorkflowRun(run))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+func toWorkflowRun(run *github.WorkflowRun) model.WorkflowRun {
+	return model.WorkflowRun{
+		ID:           run.GetID(),
+		WorkflowID:   run.GetWorkflowID(),
+		Name:         run.GetName(),
+		Status:       run.GetStatus(),
+		Conclusion:   run.GetConclusion(),
+		Branch:       run.GetHeadBranch(),
+		HeadSHA:      run.GetHeadSHA(),
+		RunAttempt:   run.GetRunAttempt(),
+		CreatedAt:    run.GetCreatedAt().Time,
+		RunStartedAt: run.GetRunStartedAt().Time,
+		UpdatedAt:    run.GetUpdatedAt().Time,
+		HTMLURL:      run.GetHTMLURL(),
+	}
 }
 
 // GetFileContent returns the content of a file from a repository.
 func (c *GitHubCollector) GetFileContent(ctx context.Context, repo model.Repo, path string) (string, error) {
-	content, _, _, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, path, nil)
+	content, _, resp, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, path, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("getting file content %s: %w", path, fs.ErrNotExist)
+	}
 	if err != nil {
 		return "", fmt.Errorf("getting file content: %w", err)
 	}
@@ -0,0 +1,226 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v84/github"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// newTestGitHubCollector returns a collector backed by an httptest server
+// serving the given path -> JSON body routes. Unknown paths return 404.
+func newTestGitHubCollector(t *testing.T, routes map[string]string) *GitHubCollector {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = baseURL
+	return NewGitHubCollectorWithClient(client)
+}
+
+const testBranchRules = `[
+  {"type": "pull_request", "ruleset_source_type": "Organization", "ruleset_source": "myorg", "ruleset_id": 7,
+   "parameters": {"required_approving_review_count": 2, "dismiss_stale_reviews_on_push": true,
+                  "require_code_owner_review": true, "require_last_push_approval": false,
+                  "required_review_thread_resolution": false}},
+  {"type": "required_status_checks", "ruleset_source_type": "Organization", "ruleset_source": "myorg", "ruleset_id": 7,
+   "parameters": {"required_status_checks": [{"context": "test"}, {"context": "lint"}],
+                  "strict_required_status_checks_policy": false}},
+  {"type": "required_signatures", "ruleset_source_type": "Repository", "ruleset_source": "myorg/api", "ruleset_id": 9},
+  {"type": "required_linear_history", "ruleset_source_type": "Repository", "ruleset_source": "myorg/api", "ruleset_id": 9},
+  {"type": "non_fast_forward", "ruleset_source_type": "Organization", "ruleset_source": "myorg", "ruleset_id": 7}
+]`
+
+const testRuleset7 = `{"id": 7, "name": "org-default", "source": "myorg", "enforcement": "active",
+  "bypass_actors": [{"actor_id": 1, "actor_type": "OrganizationAdmin", "bypass_mode": "always"}]}`
+
+func TestGitHubCollectorGetBranchProtectionRulesetsOnly(t *testing.T) {
+	c := newTestGitHubCollector(t, map[string]string{
+		"/repos/myorg/api/rules/branches/main": testBranchRules,
+		"/repos/myorg/api/rulesets/7":          testRuleset7,
+		// Ruleset 9 is not visible; its rules still apply without a name.
+	})
+
+	bp, err := c.GetBranchProtection(context.Background(), model.Repo{Owner: "myorg", Name: "api"}, "main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+
+	if !bp.Enabled {
+		t.Error("Enabled = false, want true for ruleset-protected branch")
+	}
+	if !bp.RequireReviews || bp.RequiredReviewers != 2 {
+		t.Errorf("reviews = %v/%d, want true/2", bp.RequireReviews, bp.RequiredReviewers)
+	}
+	if !bp.RequireStatusChecks || !slices.Equal(bp.RequiredStatusChecks, []string{"test", "lint"}) {
+		t.Errorf("status checks = %v %v", bp.RequireStatusChecks, bp.RequiredStatusChecks)
+	}
+	if !bp.RequireCodeOwnerReviews || !bp.DismissStaleReviews || bp.RequireConversationResolution {
+		t.Errorf("codeOwners=%v dismissStale=%v conversations=%v, want true/true/false",
+			bp.RequireCodeOwnerReviews, bp.DismissStaleReviews, bp.RequireConversationResolution)
+	}
+	if !bp.RequireSignedCommits || !bp.RequireLinearHistory {
+		t.Errorf("signed=%v linear=%v, want both true", bp.RequireSignedCommits, bp.RequireLinearHistory)
+	}
+	if bp.AllowForcePushes {
+		t.Error("AllowForcePushes = true, want false (non_fast_forward rule)")
+	}
+	if !bp.AllowDeletions {
+		t.Error("AllowDeletions = false, want true (no deletion rule)")
+	}
+	if bp.EnforceAdmins {
+		t.Error("EnforceAdmins = true, want false (org admins can bypass)")
+	}
+
+	if len(bp.BypassActors) != 1 || bp.BypassActors[0].Type != "OrganizationAdmin" || bp.BypassActors[0].RulesetName != "org-default" {
+		t.Errorf("BypassActors = %+v", bp.BypassActors)
+	}
+	if got := bp.RuleSources("pull_request"); !slices.Equal(got, []string{"Organization"}) {
+		t.Errorf("RuleSources(pull_request) = %v", got)
+	}
+	if got := bp.RuleSources("required_signatures"); !slices.Equal(got, []string{"Repository"}) {
+		t.Errorf("RuleSources(required_signatures) = %v", got)
+	}
+	if bp.Rules[0].RulesetName != "org-default" || bp.Rules[2].RulesetName != "" {
+		t.Errorf("ruleset names = %q, %q", bp.Rules[0].RulesetName, bp.Rules[2].RulesetName)
+	}
+	if len(bp.Warnings) != 1 || !strings.Contains(bp.Warnings[0], "ruleset 9") {
+		t.Errorf("Warnings = %v, want unreadable ruleset 9", bp.Warnings)
+	}
+}
+
+func TestGitHubCollectorGetBranchProtectionHiddenBypassActors(t *testing.T) {
+	c := newTestGitHubCollector(t, map[string]string{
+		"/repos/myorg/api/rules/branches/main": `[
+  {"type": "required_signatures", "ruleset_source_type": "Repository", "ruleset_source": "myorg/api", "ruleset_id": 9}
+]`,
+	})
+
+	bp, err := c.GetBranchProtection(context.Background(), model.Repo{Owner: "myorg", Name: "api"}, "main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	if bp.EnforceAdmins {
+		t.Error("EnforceAdmins = true, want false when bypass actors are unknown")
+	}
+	if len(bp.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want one", bp.Warnings)
+	}
+}
+
+func TestGitHubCollectorGetBranchProtectionRulesError(t *testing.T) {
+	c := newTestGitHubCollector(t, map[string]string{
+		"/repos/myorg/api/rules/branches/main": `not json`,
+	})
+
+	bp, err := c.GetBranchProtection(context.Background(), model.Repo{Owner: "myorg", Name: "api"}, "main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	if len(bp.Warnings) != 1 || !strings.Contains(bp.Warnings[0], "rules for branch") {
+		t.Errorf("Warnings = %v, want rules error recorded", bp.Warnings)
+	}
+}
+
+func TestGitHubCollectorGetBranchProtectionMerged(t *testing.T) {
+	c := newTestGitHubCollector(t, map[string]string{
+		"/repos/myorg/api/branches/main/protection": `{
+			"required_status_checks": {"strict": false, "contexts": ["build"]},
+			"required_pull_request_reviews": {"required_approving_review_count": 1, "dismiss_stale_reviews": false},
+			"required_conversation_resolution": {"enabled": true},
+			"enforce_admins": {"enabled": true},
+			"allow_force_pushes": {"enabled": true},
+			"allow_deletions": {"enabled": false}
+		}`,
+		"/repos/myorg/api/rules/branches/main": testBranchRules,
+		"/repos/myorg/api/rulesets/7":          testRuleset7,
+	})
+
+	bp, err := c.GetBranchProtection(context.Background(), model.Repo{Owner: "myorg", Name: "api"}, "main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+
+	if bp.RequiredReviewers != 2 {
+		t.Errorf("RequiredReviewers = %d, want the stricter 2", bp.RequiredReviewers)
+	}
+	if want := []string{"build", "test", "lint"}; !slices.Equal(bp.RequiredStatusChecks, want) {
+		t.Errorf("RequiredStatusChecks = %v, want %v", bp.RequiredStatusChecks, want)
+	}
+	if !bp.RequireConversationResolution || !bp.DismissStaleReviews {
+		t.Error("classic conversation resolution and ruleset stale review dismissal should both apply")
+	}
+	if bp.AllowForcePushes {
+		t.Error("AllowForcePushes = true, want ruleset to override classic allowance")
+	}
+	if !bp.EnforceAdmins {
+		t.Error("EnforceAdmins = false, want classic setting kept")
+	}
+	if got := bp.RuleSources("pull_request"); !slices.Equal(got, []string{"classic", "Organization"}) {
+		t.Errorf("RuleSources(pull_request) = %v", got)
+	}
+}
+
+func TestGitHubCollectorGetBranchProtectionUnprotected(t *testing.T) {
+	c := newTestGitHubCollector(t, map[string]string{
+		"/repos/myorg/api/rules/branches/main": `[]`,
+	})
+
+	bp, err := c.GetBranchProtection(context.Background(), model.Repo{Owner: "myorg", Name: "api"}, "main")
+	if err != nil {
+		t.Fatalf("GetBranchProtection() error = %v", err)
+	}
+	if bp.Enabled || len(bp.Rules) != 0 {
+		t.Errorf("GetBranchProtection() = %+v, want unprotected", bp)
+	}
+	if !bp.AllowForcePushes || !bp.AllowDeletions {
+		t.Errorf("allowForcePushes=%v allowDeletions=%v, want both true for an unprotected branch", bp.AllowForcePushes, bp.AllowDeletions)
+	}
+}
+
+func TestGitHubCollectorListWorkflowRuns(t *testing.T) {
+	c := newTestGitHubCollector(t, map[string]string{
+		"/repos/myorg/api/actions/workflows/42/runs": `{"total_count": 3, "workflow_runs": [
+  {"id": 3, "workflow_id": 42, "status": "completed", "conclusion": "success", "head_branch": "main",
+   "head_sha": "abc", "run_attempt": 2, "created_at": "2026-03-02T10:00:00Z",
+   "run_started_at": "2026-03-02T11:00:00Z", "updated_at": "2026-03-02T11:05:00Z"},
+  {"id": 2, "workflow_id": 42, "status": "completed", "conclusion": "failure", "head_branch": "main", "head_sha": "abc"},
+  {"id": 1, "workflow_id": 42, "status": "completed", "conclusion": "success", "head_branch": "main", "head_sha": "def"}
+]}`,
+	})
+
+	runs, err := c.ListWorkflowRuns(context.Background(), model.Repo{Owner: "myorg", Name: "api"}, 42, "main", 2)
+	if err != nil {
+		t.Fatalf("ListWorkflowRuns() error = %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("ListWorkflowRuns() returned %d runs, want 2", len(runs))
+	}
+	if runs[0].RunAttempt != 2 || runs[0].Duration() != 5*time.Minute {
+		t.Errorf("runs[0] attempt=%d duration=%v, want 2 and 5m", runs[0].RunAttempt, runs[0].Duration())
+	}
+	if runs[1].Conclusion != "failure" || runs[1].HeadSHA != "abc" {
+		t.Errorf("runs[1] = %+v", runs[1])
+	}
+}
@@ -3,15 +3,16 @@
 package collector
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 
+	"golang.org/x/mod/modfile"
 	"gopkg.in/yaml.v3"
 
 	"github.com/plexusone/pipelineconductor/pkg/model"
@@ -319,11 +320,17 @@ type workflowYAML struct {
 }
 
 type workflowJob struct {
-	Name   string         `yaml:"name"`
-	Uses   string         `yaml:"uses"`
-	RunsOn any            `yaml:"runs-on"`
-	Steps  []workflowStep `yaml:"steps"`
-	Needs  any            `yaml:"needs"`
+	Name     string           `yaml:"name"`
+	Uses     string           `yaml:"uses"`
+	RunsOn   any              `yaml:"runs-on"`
+	Strategy workflowStrategy `yaml:"strategy"`
+	Steps    []workflowStep   `yaml:"steps"`
+	Needs    any              `yaml:"needs"`
+}
+
+type workflowStrategy struct {
+	Matrix   yaml.Node `yaml:"matrix"`
+	FailFast *bool     `yaml:"fail-fast"`
 }
 
 type workflowStep struct {
@@ -340,6 +347,12 @@ func (c *LocalCollector) parseWorkflow(path, filename string) (*model.Workflow,
 		return nil, err
 	}
 
+	return ParseWorkflow(content, ".github/workflows/"+filename)
+}
+
+// ParseWorkflow parses GitHub Actions workflow YAML into a Workflow,
+// including triggers, jobs, steps, matrices, and reusable workflow references.
+func ParseWorkflow(content []byte, path string) (*model.Workflow, error) {
 	var wf workflowYAML
 	if err := yaml.Unmarshal(content, &wf); err != nil {
 		return nil, fmt.Errorf("parsing YAML: %w", err)
@@ -347,7 +360,7 @@ func (c *LocalCollector) parseWorkflow(path, filename string) (*model.Workflow,
 
 	workflow := &model.Workflow{
 		Name:    wf.Name,
-		Path:    ".github/workflows/" + filename,
+		Path:    path,
 		Content: string(content),
 	}
 
@@ -370,8 +383,9 @@ func (c *LocalCollector) parseWorkflow(path, filename string) (*model.Workflow,
 			workflow.ReusableWorkflowRefs = append(workflow.ReusableWorkflowRefs, *ref)
 		}
 
-		// Extract runs-on
+		// Extract runs-on and matrix
 		wfJob.RunsOn = extractRunsOn(job.RunsOn)
+		wfJob.Matrix = extractMatrix(job.Strategy)
 
 		// Extract steps
 		for _, step := range job.Steps {
@@ -386,6 +400,11 @@ func (c *LocalCollector) parseWorkflow(path, filename string) (*model.Workflow,
 		workflow.Jobs = append(workflow.Jobs, wfJob)
 	}
 
+	// Map iteration order is random; keep jobs stable for reports and diffs.
+	slices.SortFunc(workflow.Jobs, func(a, b model.WorkflowJob) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
 	return workflow, nil
 }
 
@@ -429,6 +448,87 @@ func extractRunsOn(runsOn any) []string {
 	return result
 }
 
+// extractMatrix extracts a job's strategy matrix. Matrices computed from
+// expressions (e.g. fromJSON) cannot be resolved statically and yield nil.
+func extractMatrix(strategy workflowStrategy) *model.MatrixConfig {
+	node := strategy.Matrix
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var m map[string]any
+	if err := node.Decode(&m); err != nil {
+		return nil
+	}
+
+	matrix := &model.MatrixConfig{
+		OS:            toStrings(m["os"]),
+		GoVersion:     toStrings(firstOf(m, "go-version", "go")),
+		PythonVersion: toStrings(firstOf(m, "python-version", "python")),
+		NodeVersion:   toStrings(firstOf(m, "node-version", "node")),
+		Include:       toStringMaps(m["include"]),
+		Exclude:       toStringMaps(m["exclude"]),
+		FailFast:      strategy.FailFast == nil || *strategy.FailFast,
+	}
+
+	// Mapping node content alternates keys and values in declaration order.
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if key == "include" || key == "exclude" {
+			continue
+		}
+		matrix.Dimensions = append(matrix.Dimensions, model.MatrixDimension{
+			Key:    key,
+			Values: toStrings(m[key]),
+		})
+	}
+	return matrix
+}
+
+func firstOf(m map[string]any, keys ...string) any {
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// toStrings converts a scalar or list YAML value to strings.
+func toStrings(v any) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		var result []string
+		for _, item := range v {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func toStringMaps(v any) []map[string]string {
+	list, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	var result []map[string]string
+	for _, item := range list {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		m := make(map[string]string, len(entry))
+		for k, val := range entry {
+			m[k] = fmt.Sprint(val)
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
 // GetBranchProtection returns branch protection settings.
 // Not applicable for local filesystem.
 func (c *LocalCollector) GetBranchProtection(_ context.Context, _ model.Repo, _ string) (*model.BranchProtection, error) {
@@ -441,6 +541,12 @@ func (c *LocalCollector) GetLatestWorkflowRun(_ context.Context, _ model.Repo, _
 	return nil, nil
 }
 
+// ListWorkflowRuns returns completed workflow runs.
+// Not applicable for local filesystem.
+func (c *LocalCollector) ListWorkflowRuns(_ context.Context, _ model.Repo, _ int64, _ string, _ int) ([]model.WorkflowRun, error) {
+	return nil, nil
+}
+
 // GetFileContent returns the content of a file from a repository.
 func (c *LocalCollector) GetFileContent(_ context.Context, repo model.Repo, path string) (string, error) {
 	repoPath := repo.LocalPath
@@ -469,81 +575,22 @@ func (c *LocalCollector) GetLanguages(_ context.Context, repo model.Repo) ([]str
 
 // ParseGoMod parses a go.mod file and returns module name, replace count, and dependencies.
 func ParseGoMod(goModPath string) (moduleName string, replaceCount int, dependencies []string) {
-	file, err := os.Open(goModPath)
+	content, err := os.ReadFile(goModPath)
 	if err != nil {
 		return "", 0, nil
 	}
-	defer func() { _ = file.Close() }()
-
-	s := bufio.NewScanner(file)
-	inReplaceBlock := false
-	inRequireBlock := false
-
-	for s.Scan() {
-		line := strings.TrimSpace(s.Text())
-
-		// Get module name
-		if mod, found := strings.CutPrefix(line, "module "); found {
-			moduleName = strings.TrimSpace(mod)
-		}
-
-		// Count replace directives
-		if strings.HasPrefix(line, "replace ") && !strings.HasPrefix(line, "replace (") {
-			replaceCount++
-		}
-
-		// Handle replace block
-		if strings.HasPrefix(line, "replace (") {
-			inReplaceBlock = true
-			continue
-		}
-		if inReplaceBlock {
-			if line == ")" {
-				inReplaceBlock = false
-				continue
-			}
-			if line != "" && !strings.HasPrefix(line, "//") {
-				replaceCount++
-			}
-		}
-
-		// Parse single-line require
-		if strings.HasPrefix(line, "require ") && !strings.HasPrefix(line, "require (") {
-			if dep := parseRequireLine(strings.TrimPrefix(line, "require ")); dep != "" {
-				dependencies = append(dependencies, dep)
-			}
-		}
-
-		// Handle require block
-		if strings.HasPrefix(line, "require (") {
-			inRequireBlock = true
-			continue
-		}
-		if inRequireBlock {
-			if line == ")" {
-				inRequireBlock = false
-				continue
-			}
-			if dep := parseRequireLine(line); dep != "" {
-				dependencies = append(dependencies, dep)
-			}
-		}
+	f, err := modfile.Parse(goModPath, content, nil)
+	if err != nil {
+		return "", 0, nil
 	}
 
-	return moduleName, replaceCount, dependencies
-}
-
-// parseRequireLine extracts the module path from a require line.
-func parseRequireLine(line string) string {
-	line = strings.TrimSpace(line)
-	if line == "" || strings.HasPrefix(line, "//") {
-		return ""
+	if f.Module != nil {
+		moduleName = f.Module.Mod.Path
 	}
-	parts := strings.Fields(line)
-	if len(parts) >= 1 {
-		return parts[0]
+	for _, r := range f.Require {
+		dependencies = append(dependencies, r.Mod.Path)
 	}
-	return ""
+	return moduleName, len(f.Replace), dependencies
 }
 
 // Helper functions
@@ -454,6 +454,63 @@ func TestExtractRunsOn(t *testing.T) {
 	}
 }
 
+func TestParseWorkflowMatrix(t *testing.T) {
+	content := `name: CI
+on: [push]
+jobs:
+  test:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      fail-fast: false
+      matrix:
+        os: [ubuntu-latest, macos-latest]
+        go-version: ["1.24", "1.25"]
+        include:
+          - os: windows-latest
+            go-version: "1.25"
+    steps:
+      - uses: actions/checkout@v4
+      - run: go test -race ./...
+  dynamic:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix: ${{ fromJSON(needs.setup.outputs.matrix) }}
+`
+	wf, err := ParseWorkflow([]byte(content), ".github/workflows/ci.yaml")
+	if err != nil {
+		t.Fatalf("ParseWorkflow() error = %v", err)
+	}
+	if len(wf.Jobs) != 2 || wf.Jobs[0].ID != "dynamic" || wf.Jobs[1].ID != "test" {
+		t.Fatalf("Jobs = %+v, want dynamic and test in order", wf.Jobs)
+	}
+	if wf.Jobs[0].Matrix != nil {
+		t.Errorf("dynamic matrix = %+v, want nil", wf.Jobs[0].Matrix)
+	}
+
+	job := wf.Jobs[1]
+	if job.Matrix == nil {
+		t.Fatal("test job matrix is nil")
+	}
+	if want := []string{"ubuntu-latest", "macos-latest"}; !slices.Equal(job.Matrix.OS, want) {
+		t.Errorf("Matrix.OS = %v, want %v", job.Matrix.OS, want)
+	}
+	if want := []string{"1.24", "1.25"}; !slices.Equal(job.Matrix.GoVersion, want) {
+		t.Errorf("Matrix.GoVersion = %v, want %v", job.Matrix.GoVersion, want)
+	}
+	if len(job.Matrix.Include) != 1 || job.Matrix.Include[0]["os"] != "windows-latest" {
+		t.Errorf("Matrix.Include = %v", job.Matrix.Include)
+	}
+	if job.Matrix.FailFast {
+		t.Error("Matrix.FailFast = true, want false")
+	}
+	if len(job.Matrix.Dimensions) != 2 || job.Matrix.Dimensions[0].Key != "os" || job.Matrix.Dimensions[1].Key != "go-version" {
+		t.Errorf("Matrix.Dimensions = %+v, want os then go-version", job.Matrix.Dimensions)
+	}
+	if len(job.Steps) != 2 || job.Steps[1].Run != "go test -race ./..." {
+		t.Errorf("Steps = %+v", job.Steps)
+	}
+}
+
 func TestParseGoMod(t *testing.T) {
 	tempDir := t.TempDir()
 	goModPath := filepath.Join(tempDir, "go.mod")
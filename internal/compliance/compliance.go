@@ -15,14 +15,18 @@ type Checker struct {
 	RefRepo   *ReferenceRepo
 	Strict    bool
 	Verbose   bool
+	// InactiveAfter marks workflows without a run in this window as
+	// inactive. Zero only checks the workflow state.
+	InactiveAfter time.Duration
 }
 
 // CheckerConfig configures the compliance checker.
 type CheckerConfig struct {
-	RefRepo   string
-	RefBranch string
-	Strict    bool
-	Verbose   bool
+	RefRepo       string
+	RefBranch     string
+	Strict        bool
+	Verbose       bool
+	InactiveAfter time.Duration
 }
 
 // NewChecker creates a new compliance checker.
@@ -36,10 +40,11 @@ func NewChecker(c collector.Collector, cfg CheckerConfig) (*Checker, error) {
 	}
 
 	return &Checker{
-		Collector: c,
-		RefRepo:   refRepo,
-		Strict:    cfg.Strict,
-		Verbose:   cfg.Verbose,
+		Collector:     c,
+		RefRepo:       refRepo,
+		Strict:        cfg.Strict,
+		Verbose:       cfg.Verbose,
+		InactiveAfter: cfg.InactiveAfter,
 	}, nil
 }
 
@@ -64,10 +69,11 @@ func (c *Checker) CheckRepos(ctx context.Context, repos []model.Repo, languages
 		Timestamp:     time.Now().Format(time.RFC3339),
 		Repos:         make([]model.RepoCheckResult, 0, len(repos)),
 		Config: model.CheckConfig{
-			RefRepo:   c.RefRepo.FullName(),
-			RefBranch: c.RefRepo.Branch,
-			Languages: languages,
-			Strict:    c.Strict,
+			RefRepo:           c.RefRepo.FullName(),
+			RefBranch:         c.RefRepo.Branch,
+			Languages:         languages,
+			Strict:            c.Strict,
+			InactiveAfterDays: int(c.InactiveAfter.Hours() / 24),
 		},
 	}
 
@@ -93,6 +99,7 @@ func (c *Checker) checkRepo(ctx context.Context, repo model.Repo, rules []Workfl
 		Name:              repo.Name,
 		FullName:          repo.FullName,
 		HTMLURL:           repo.HTMLURL,
+		DefaultBranch:     repo.DefaultBranch,
 		Languages:         repo.Languages,
 		RequiredWorkflows: make([]model.WorkflowCheck, 0),
 		ActualWorkflows:   make([]model.WorkflowInfo, 0),
@@ -120,6 +127,9 @@ func (c *Checker) checkRepo(ctx context.Context, repo model.Repo, rules []Workfl
 		result.ActualWorkflows = append(result.ActualWorkflows, info)
 	}
 
+	// Split workflows by activity so active matches are preferred
+	active, inactive, activity := c.partitionByActivity(ctx, repo, workflows)
+
 	// Get relevant rules for this repo's languages
 	repoRules := c.filterRulesForRepo(rules, repo.Languages)
 
@@ -137,9 +147,17 @@ func (c *Checker) checkRepo(ctx context.Context, repo model.Repo, rules []Workfl
 	exactMatches := 0
 	equivalentMatches := 0
 	missingCount := 0
+	inactiveCount := 0
 
 	for _, rule := range repoRules {
-		matchResult := matcher.MatchWorkflow(workflows, rule)
+		matchResult := matcher.MatchWorkflow(active, rule)
+		var inactiveMatch *workflowActivity
+		if matchResult.MatchType == model.MatchTypeNone && len(inactive) > 0 {
+			if m := matcher.MatchWorkflow(inactive, rule); m.MatchType != model.MatchTypeNone {
+				matchResult = m
+				inactiveMatch = activity[m.ActualWorkflow]
+			}
+		}
 
 		check := model.WorkflowCheck{
 			WorkflowType:     rule.Type,
@@ -154,13 +172,22 @@ func (c *Checker) checkRepo(ctx context.Context, repo model.Repo, rules []Workfl
 			ExpectedFilename: matchResult.ExpectedFilename,
 			ActualFilename:   matchResult.ActualFilename,
 		}
+		if inactiveMatch != nil {
+			check.MatchType = model.MatchTypeInactive
+			check.Inactive = true
+			check.InactiveReason = inactiveMatch.reason
+			check.State = inactiveMatch.state
+			check.LastRunAt = inactiveMatch.lastRunAt
+		}
 		result.RequiredWorkflows = append(result.RequiredWorkflows, check)
 
-		switch matchResult.MatchType {
+		switch check.MatchType {
 		case model.MatchTypeExact:
 			exactMatches++
 		case model.MatchTypeEquivalent:
 			equivalentMatches++
+		case model.MatchTypeInactive:
+			inactiveCount++
 		case model.MatchTypeNone:
 			missingCount++
 			result.Missing = append(result.Missing, model.MissingWorkflow{
@@ -175,13 +202,13 @@ func (c *Checker) checkRepo(ctx context.Context, repo model.Repo, rules []Workfl
 
 	// Determine compliance level
 	totalRules := len(repoRules)
-	if missingCount == 0 && equivalentMatches == 0 {
+	if missingCount == 0 && equivalentMatches == 0 && inactiveCount == 0 {
 		result.ComplianceLevel = model.ComplianceLevelFull
 		result.Compliant = true
 	} else if missingCount == 0 {
 		result.ComplianceLevel = model.ComplianceLevelPartial
 		result.Compliant = false
-	} else if exactMatches+equivalentMatches > 0 {
+	} else if exactMatches+equivalentMatches+inactiveCount > 0 {
 		result.ComplianceLevel = model.ComplianceLevelPartial
 		result.Compliant = false
 	} else if missingCount == totalRules {
@@ -196,6 +223,59 @@ func (c *Checker) checkRepo(ctx context.Context, repo model.Repo, rules []Workfl
 	return result
 }
 
+// workflowActivity describes why a workflow is inactive.
+type workflowActivity struct {
+	reason    string
+	state     string
+	lastRunAt string
+}
+
+// partitionByActivity splits workflows into active and inactive ones. A
+// workflow is inactive when GitHub reports it disabled or, if InactiveAfter
+// is set, when its latest run is older than the window or it never ran.
+// Workflows without a GitHub ID (local scans) are only checked by state.
+func (c *Checker) partitionByActivity(ctx context.Context, repo model.Repo, workflows []model.Workflow) (active, inactive []model.Workflow, activity map[string]*workflowActivity) {
+	activity = make(map[string]*workflowActivity)
+
+	for _, wf := range workflows {
+		a := &workflowActivity{state: wf.State}
+
+		switch wf.State {
+		case "", "active":
+		case "disabled_manually":
+			a.reason = "disabled manually"
+		case "disabled_inactivity":
+			a.reason = "disabled by GitHub after repository inactivity"
+		default:
+			a.reason = fmt.Sprintf("workflow state is %s", wf.State)
+		}
+
+		if a.reason == "" && c.InactiveAfter > 0 && wf.ID != 0 {
+			run, err := c.Collector.GetLatestWorkflowRun(ctx, repo, wf.ID)
+			switch {
+			case err != nil:
+				// Run history unavailable; judge by state alone.
+			case run == nil:
+				a.reason = "never run"
+			default:
+				a.lastRunAt = run.CreatedAt.Format(time.RFC3339)
+				if time.Since(run.CreatedAt) > c.InactiveAfter {
+					a.reason = fmt.Sprintf("no runs in the last %d days", int(c.InactiveAfter.Hours()/24))
+				}
+			}
+		}
+
+		if a.reason == "" {
+			active = append(active, wf)
+			continue
+		}
+		inactive = append(inactive, wf)
+		activity[wf.Path] = a
+	}
+
+	return active, inactive, activity
+}
+
 // filterRulesForRepo returns rules that apply to the repo's languages.
 func (c *Checker) filterRulesForRepo(allRules []WorkflowRule, repoLanguages []string) []WorkflowRule {
 	// Create a set of repo languages for quick lookup
@@ -262,6 +342,12 @@ func (c *Checker) calculateSummary(repos []model.RepoCheckResult, languages []st
 			continue
 		}
 
+		for _, wf := range repo.RequiredWorkflows {
+			if wf.Inactive {
+				summary.InactiveWorkflows++
+			}
+		}
+
 		switch repo.ComplianceLevel {
 		case model.ComplianceLevelFull:
 			summary.CompliantRepos++
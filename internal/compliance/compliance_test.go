@@ -0,0 +1,139 @@
+package compliance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plexusone/pipelineconductor/internal/collector"
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// fakeCollector serves fixed workflows and latest runs. Other Collector
+// methods are not used by checkRepo.
+type fakeCollector struct {
+	collector.Collector
+	workflows []model.Workflow
+	runs      map[int64]*model.WorkflowRun
+}
+
+func (f *fakeCollector) GetWorkflows(_ context.Context, _ model.Repo) ([]model.Workflow, error) {
+	return f.workflows, nil
+}
+
+func (f *fakeCollector) GetLatestWorkflowRun(_ context.Context, _ model.Repo, workflowID int64) (*model.WorkflowRun, error) {
+	return f.runs[workflowID], nil
+}
+
+func reusableWorkflow(id int64, name, state string) model.Workflow {
+	path := ".github/workflows/" + name + ".yaml"
+	return model.Workflow{
+		ID:      id,
+		Name:    name,
+		Path:    path,
+		State:   state,
+		Content: "jobs:\n  call:\n    uses: testorg/.github/" + path + "@main\n",
+	}
+}
+
+func TestCheckerInactiveWorkflows(t *testing.T) {
+	refRepo := &ReferenceRepo{Owner: "testorg", Name: ".github", Branch: "main"}
+	rules := GetRequiredWorkflows([]string{"Go"})
+	repo := model.Repo{FullName: "testorg/svc", Languages: []string{"Go"}}
+
+	fc := &fakeCollector{
+		workflows: []model.Workflow{
+			reusableWorkflow(1, "go-ci", "disabled_inactivity"),
+			reusableWorkflow(2, "go-lint", "active"),
+			reusableWorkflow(3, "go-sast-codeql", "active"),
+		},
+		runs: map[int64]*model.WorkflowRun{
+			2: {CreatedAt: time.Now().AddDate(0, 0, -100)},
+			3: {CreatedAt: time.Now().AddDate(0, 0, -2)},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		inactiveAfter time.Duration
+		want          map[string]string
+	}{
+		{
+			name: "state only",
+			want: map[string]string{
+				"go-ci":          model.MatchTypeInactive,
+				"go-lint":        model.MatchTypeExact,
+				"go-sast-codeql": model.MatchTypeExact,
+			},
+		},
+		{
+			name:          "run window",
+			inactiveAfter: 60 * 24 * time.Hour,
+			want: map[string]string{
+				"go-ci":          model.MatchTypeInactive,
+				"go-lint":        model.MatchTypeInactive,
+				"go-sast-codeql": model.MatchTypeExact,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Checker{Collector: fc, RefRepo: refRepo, InactiveAfter: tt.inactiveAfter}
+			result := c.checkRepo(context.Background(), repo, rules, NewWorkflowMatcher(refRepo, false))
+
+			for _, check := range result.RequiredWorkflows {
+				if check.MatchType != tt.want[check.WorkflowType] {
+					t.Errorf("%s MatchType = %q, want %q", check.WorkflowType, check.MatchType, tt.want[check.WorkflowType])
+				}
+				if check.Inactive && (!check.Present || check.InactiveReason == "") {
+					t.Errorf("%s inactive check = %+v, want present with a reason", check.WorkflowType, check)
+				}
+			}
+			if result.Compliant || result.ComplianceLevel != model.ComplianceLevelPartial || len(result.Missing) != 0 {
+				t.Errorf("level = %s compliant = %v missing = %v, want partial", result.ComplianceLevel, result.Compliant, result.Missing)
+			}
+		})
+	}
+
+	c := &Checker{Collector: fc, RefRepo: refRepo, InactiveAfter: 60 * 24 * time.Hour}
+	result := c.checkRepo(context.Background(), repo, rules, NewWorkflowMatcher(refRepo, false))
+	lint := result.RequiredWorkflows[1]
+	if lint.InactiveReason != "no runs in the last 60 days" || lint.LastRunAt == "" || lint.State != "active" {
+		t.Errorf("go-lint = %+v", lint)
+	}
+	if summary := c.calculateSummary([]model.RepoCheckResult{result}, []string{"Go"}); summary.InactiveWorkflows != 2 {
+		t.Errorf("InactiveWorkflows = %d, want 2", summary.InactiveWorkflows)
+	}
+}
+
+func TestCheckerPrefersActiveWorkflow(t *testing.T) {
+	refRepo := &ReferenceRepo{Owner: "testorg", Name: ".github", Branch: "main"}
+	repo := model.Repo{FullName: "testorg/svc", Languages: []string{"Go"}}
+	rules := []WorkflowRule{LanguageRules["Go"][0]}
+
+	fc := &fakeCollector{
+		workflows: []model.Workflow{
+			reusableWorkflow(1, "go-ci", "disabled_manually"),
+			{ID: 2, Name: "CI", Path: ".github/workflows/ci.yml", State: "active",
+				Content: "jobs:\n  test:\n    steps:\n      - uses: actions/setup-go@v5\n      - run: go test ./...\n"},
+		},
+		runs: map[int64]*model.WorkflowRun{},
+	}
+
+	c := &Checker{Collector: fc, RefRepo: refRepo, InactiveAfter: 30 * 24 * time.Hour}
+	result := c.checkRepo(context.Background(), repo, rules, NewWorkflowMatcher(refRepo, false))
+	// Workflow 2 never ran, so every workflow is inactive; the exact
+	// match among inactive workflows is reported.
+	check := result.RequiredWorkflows[0]
+	if check.MatchType != model.MatchTypeInactive || check.ActualWorkflow != ".github/workflows/go-ci.yaml" || check.InactiveReason != "disabled manually" {
+		t.Errorf("check = %+v", check)
+	}
+
+	fc.runs[2] = &model.WorkflowRun{CreatedAt: time.Now()}
+	result = c.checkRepo(context.Background(), repo, rules, NewWorkflowMatcher(refRepo, false))
+	check = result.RequiredWorkflows[0]
+	if check.MatchType != model.MatchTypeEquivalent || check.ActualWorkflow != ".github/workflows/ci.yml" {
+		t.Errorf("check = %+v, want active equivalent ci.yml", check)
+	}
+}
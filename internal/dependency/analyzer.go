@@ -0,0 +1,193 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// DefaultConcurrency is the default number of concurrent version lookups.
+const DefaultConcurrency = 8
+
+// FileGetter reads files from a repository. collector.Collector satisfies it.
+// Errors for missing files must match fs.ErrNotExist.
+type FileGetter interface {
+	GetFileContent(ctx context.Context, repo model.Repo, path string) (string, error)
+}
+
+// Analyzer computes how far a repository's dependencies lag behind their
+// latest releases.
+type Analyzer struct {
+	// Sources maps an ecosystem (model.EcosystemGo, model.EcosystemNPM)
+	// to its registry. Dependencies of other ecosystems are not analyzed.
+	Sources map[string]VersionSource
+	// Transitive includes indirect go.mod requirements and nested
+	// package-lock.json packages when set.
+	Transitive  bool
+	Concurrency int
+}
+
+// NewAnalyzer creates an analyzer using $GOPROXY (or proxy.golang.org) and
+// the public npm registry.
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{
+		Sources: map[string]VersionSource{
+			model.EcosystemGo:  NewGoProxy(""),
+			model.EcosystemNPM: NewNPMRegistry(""),
+		},
+		Concurrency: DefaultConcurrency,
+	}
+}
+
+// Collect reads go.mod, package.json, and package-lock.json from a
+// repository and returns the declared dependencies. Missing files are
+// skipped; other read errors are returned.
+func (a *Analyzer) Collect(ctx context.Context, files FileGetter, repo model.Repo) ([]model.Dependency, error) {
+	return CollectDependencies(ctx, files, repo, a.Transitive)
+}
+
+// CollectDependencies reads the dependency manifests of a repository. When
+// transitive is false only direct dependencies are returned; otherwise
+// indirect go.mod requirements and every package-lock.json package are
+// included, with Direct reporting whether package.json declares them.
+func CollectDependencies(ctx context.Context, files FileGetter, repo model.Repo, transitive bool) ([]model.Dependency, error) {
+	var deps []model.Dependency
+
+	content, ok, err := readOptional(ctx, files, repo, GoModFile)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for _, dep := range ParseGoMod([]byte(content)) {
+			if dep.Direct || transitive {
+				deps = append(deps, dep)
+			}
+		}
+	}
+
+	content, ok, err = readOptional(ctx, files, repo, PackageJSONFile)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		lock, _, err := readOptional(ctx, files, repo, PackageLockFile)
+		if err != nil {
+			return nil, err
+		}
+		npmDeps, err := ParsePackageJSON([]byte(content), []byte(lock))
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, npmDeps...)
+
+		if transitive && lock != "" {
+			installed, err := ParsePackageLock([]byte(lock))
+			if err != nil {
+				return nil, err
+			}
+			for _, dep := range installed {
+				if !slices.ContainsFunc(npmDeps, func(d model.Dependency) bool {
+					return d.Name == dep.Name && d.Version == dep.Version
+				}) {
+					deps = append(deps, dep)
+				}
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// readOptional reads a file that may not exist. ok is false for a missing
+// file; other read errors are returned.
+func readOptional(ctx context.Context, files FileGetter, repo model.Repo, path string) (content string, ok bool, err error) {
+	content, err = files.GetFileContent(ctx, repo, path)
+	switch {
+	case err == nil:
+		return content, true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("reading %s: %w", path, err)
+}
+
+// Analyze fills in the latest version and days behind for each
+// dependency. Lookup failures are recorded on the dependency rather than
+// returned, so one unavailable module does not fail the repository.
+func (a *Analyzer) Analyze(ctx context.Context, deps []model.Dependency) []model.Dependency {
+	result := make([]model.Dependency, len(deps))
+	copy(result, deps)
+
+	concurrency := a.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := range result {
+		source, ok := a.Sources[result[i].Ecosystem]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(dep *model.Dependency) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			analyzeOne(ctx, source, dep)
+		}(&result[i])
+	}
+	wg.Wait()
+
+	return result
+}
+
+func analyzeOne(ctx context.Context, source VersionSource, dep *model.Dependency) {
+	latest, err := source.Latest(ctx, dep.Name)
+	if err != nil {
+		dep.Error = err.Error()
+		return
+	}
+	dep.Latest = latest.Version
+	dep.LatestTime = latest.Time
+
+	if latest.Version == dep.Version {
+		dep.VersionTime = latest.Time
+		return
+	}
+
+	current, err := source.Info(ctx, dep.Name, dep.Version)
+	if err != nil {
+		dep.Error = err.Error()
+		return
+	}
+	dep.VersionTime = current.Time
+	dep.DaysBehind = daysBetween(current.Time, latest.Time)
+}
+
+func daysBetween(from, to time.Time) int {
+	if from.IsZero() || to.IsZero() || !to.After(from) {
+		return 0
+	}
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// Summarize populates the freshness fields of a policy dependencies context.
+func Summarize(deps []model.Dependency, dc *model.DependenciesContext) {
+	dc.DependencyCount = len(deps)
+	dc.OldestDependencyDays = 0
+	dc.OutdatedCount = 0
+	for _, dep := range deps {
+		if dep.Outdated() {
+			dc.OutdatedCount++
+		}
+		dc.OldestDependencyDays = max(dc.OldestDependencyDays, dep.DaysBehind)
+	}
+}
@@ -0,0 +1,140 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// fakeFiles serves repository files by path. Reading the path stored
+// under "error:<path>" fails with that value as the error message.
+type fakeFiles map[string]string
+
+func (f fakeFiles) GetFileContent(_ context.Context, _ model.Repo, path string) (string, error) {
+	if msg, ok := f["error:"+path]; ok {
+		return "", errors.New(msg)
+	}
+	if content, ok := f[path]; ok {
+		return content, nil
+	}
+	return "", fs.ErrNotExist
+}
+
+func TestAnalyzerGoProxy(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml/@latest"), `{"Version":"v1.4.0","Time":"2024-06-01T00:00:00Z"}`)
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml/@v/v1.2.0.info"), `{"Version":"v1.2.0","Time":"2024-01-03T00:00:00Z"}`)
+	// Like $GOMODCACHE/cache/download, cobra has a version list but no @latest.
+	writeFile(t, filepath.Join(dir, "github.com/spf13/cobra/@v/list"), "v1.7.0\nv1.9.0-rc.1\nv1.8.0\n")
+	writeFile(t, filepath.Join(dir, "github.com/spf13/cobra/@v/v1.8.0.info"), `{"Version":"v1.8.0","Time":"2023-11-01T00:00:00Z"}`)
+
+	a := &Analyzer{Sources: map[string]VersionSource{
+		model.EcosystemGo: NewGoProxy("file://" + filepath.ToSlash(dir)),
+	}}
+
+	files := fakeFiles{GoModFile: `module example.com/app
+
+require (
+	github.com/BurntSushi/toml v1.2.0
+	github.com/spf13/cobra v1.8.0
+	example.com/missing v0.1.0
+	golang.org/x/sys v0.20.0 // indirect
+)
+`}
+
+	deps, err := a.Collect(context.Background(), files, model.Repo{FullName: "myorg/app"})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("Collect() = %+v, want 3 direct deps", deps)
+	}
+
+	deps = a.Analyze(context.Background(), deps)
+
+	toml := deps[0]
+	if toml.Latest != "v1.4.0" || toml.DaysBehind != 150 || !toml.Outdated() {
+		t.Errorf("toml = latest %s, %d days behind, outdated=%v", toml.Latest, toml.DaysBehind, toml.Outdated())
+	}
+	if cobra := deps[1]; cobra.Outdated() || cobra.DaysBehind != 0 || cobra.Error != "" {
+		t.Errorf("cobra = %+v, want up to date", cobra)
+	}
+	if missing := deps[2]; missing.Error == "" {
+		t.Error("missing module should record a lookup error")
+	}
+
+	var dc model.DependenciesContext
+	Summarize(deps, &dc)
+	if dc.DependencyCount != 3 || dc.OutdatedCount != 1 || dc.OldestDependencyDays != 150 {
+		t.Errorf("Summarize() = %+v", dc)
+	}
+}
+
+func TestCollectDependenciesReadErrors(t *testing.T) {
+	for _, path := range []string{GoModFile, PackageJSONFile, PackageLockFile} {
+		files := fakeFiles{PackageJSONFile: `{"dependencies":{"react":"^18.3.1"}}`, "error:" + path: "rate limited"}
+		_, err := CollectDependencies(context.Background(), files, model.Repo{FullName: "myorg/app"}, false)
+		if err == nil || !strings.Contains(err.Error(), "rate limited") {
+			t.Errorf("CollectDependencies() with %s unreadable error = %v, want read error", path, err)
+		}
+	}
+}
+
+func TestAnalyzerNPMRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/react":
+			_, _ = w.Write([]byte(`{"dist-tags":{"latest":"19.0.0"},
+				"time":{"18.3.1":"2024-04-26T00:00:00Z","19.0.0":"2024-12-05T00:00:00Z"}}`))
+		case "/@types%2Fnode":
+			_, _ = w.Write([]byte(`{"dist-tags":{"latest":"20.1.0"},"time":{"20.1.0":"2023-05-01T00:00:00Z"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	a := &Analyzer{Sources: map[string]VersionSource{
+		model.EcosystemNPM: NewNPMRegistry(server.URL),
+	}}
+	deps := a.Analyze(context.Background(), []model.Dependency{
+		{Name: "react", Version: "18.3.1", Ecosystem: model.EcosystemNPM},
+		{Name: "@types/node", Version: "20.1.0", Ecosystem: model.EcosystemNPM},
+		{Name: "other", Version: "1.0.0", Ecosystem: "pypi"},
+	})
+
+	if deps[0].Latest != "19.0.0" || deps[0].DaysBehind != 223 {
+		t.Errorf("react = latest %s, %d days behind", deps[0].Latest, deps[0].DaysBehind)
+	}
+	if deps[1].Outdated() || deps[1].Error != "" {
+		t.Errorf("@types/node = %+v, want up to date", deps[1])
+	}
+	if deps[2].Latest != "" || deps[2].Error != "" {
+		t.Errorf("unsupported ecosystem should be left untouched: %+v", deps[2])
+	}
+}
+
+func TestGoproxyFromEnv(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", DefaultGoProxy},
+		{"direct", DefaultGoProxy},
+		{"https://goproxy.example.com,direct", "https://goproxy.example.com"},
+		{"off|file:///tmp/proxy", "file:///tmp/proxy"},
+	}
+	for _, tt := range tests {
+		t.Setenv("GOPROXY", tt.env)
+		if got := goproxyFromEnv(); got != tt.want {
+			t.Errorf("goproxyFromEnv() with %q = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Package dependency provides dependency manifest parsing, freshness
+// analysis, offline vulnerability matching, and Renovate and Dependabot
+// configuration checks.
+package dependency
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// Manifest and lockfile names read from repositories.
+const (
+	GoModFile       = "go.mod"
+	PackageJSONFile = "package.json"
+	PackageLockFile = "package-lock.json"
+)
+
+// ParseGoMod parses the require directives of a go.mod file. Modules
+// replaced by a local path are omitted, since they have no upstream
+// version. Files that do not parse have no dependencies.
+func ParseGoMod(content []byte) []model.Dependency {
+	f, err := modfile.Parse(GoModFile, content, nil)
+	if err != nil {
+		return nil
+	}
+
+	localReplaced := make(map[string]bool)
+	for _, r := range f.Replace {
+		if modfile.IsDirectoryPath(r.New.Path) {
+			localReplaced[r.Old.Path] = true
+		}
+	}
+
+	var deps []model.Dependency
+	for _, r := range f.Require {
+		if localReplaced[r.Mod.Path] {
+			continue
+		}
+		deps = append(deps, model.Dependency{
+			Name:      r.Mod.Path,
+			Version:   r.Mod.Version,
+			Ecosystem: model.EcosystemGo,
+			Direct:    !r.Indirect,
+			File:      GoModFile,
+		})
+	}
+	return deps
+}
+
+type packageJSON struct {
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+type packageLock struct {
+	LockfileVersion int `json:"lockfileVersion"`
+	// Packages is used by lockfile v2 and v3, keyed by install path such
+	// as "node_modules/a/node_modules/b".
+	Packages map[string]struct {
+		Version string `json:"version"`
+	} `json:"packages"`
+	// Dependencies is used by lockfile v1.
+	Dependencies map[string]lockV1Dependency `json:"dependencies"`
+}
+
+type lockV1Dependency struct {
+	Version      string                      `json:"version"`
+	Dependencies map[string]lockV1Dependency `json:"dependencies"`
+}
+
+// ParsePackageLock parses every installed package of a package-lock.json
+// file, including nested ones. All returned dependencies have Direct unset.
+func ParsePackageLock(lock []byte) ([]model.Dependency, error) {
+	var pl packageLock
+	if err := json.Unmarshal(lock, &pl); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", PackageLockFile, err)
+	}
+
+	seen := make(map[string]bool)
+	var deps []model.Dependency
+	add := func(name, version string) {
+		key := name + "@" + version
+		if name == "" || version == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		deps = append(deps, model.Dependency{
+			Name:      name,
+			Version:   version,
+			Ecosystem: model.EcosystemNPM,
+			File:      PackageLockFile,
+		})
+	}
+
+	for key, p := range pl.Packages {
+		if i := strings.LastIndex(key, "node_modules/"); i >= 0 {
+			add(key[i+len("node_modules/"):], p.Version)
+		}
+	}
+	var walk func(map[string]lockV1Dependency)
+	walk = func(m map[string]lockV1Dependency) {
+		for name, d := range m {
+			add(name, d.Version)
+			walk(d.Dependencies)
+		}
+	}
+	walk(pl.Dependencies)
+
+	slices.SortFunc(deps, func(a, b model.Dependency) int {
+		return strings.Compare(a.Name+"@"+a.Version, b.Name+"@"+b.Version)
+	})
+	return deps, nil
+}
+
+// ParsePackageJSON parses the direct dependencies of a package.json file.
+// If lock is non-empty, versions are taken from package-lock.json;
+// otherwise the lowest version allowed by each range is used.
+func ParsePackageJSON(content, lock []byte) ([]model.Dependency, error) {
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", PackageJSONFile, err)
+	}
+
+	locked := make(map[string]string)
+	if len(lock) > 0 {
+		var pl packageLock
+		if err := json.Unmarshal(lock, &pl); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", PackageLockFile, err)
+		}
+		// Only top-level installs are the versions resolved for the
+		// package's own dependencies.
+		for key, p := range pl.Packages {
+			if name, ok := strings.CutPrefix(key, "node_modules/"); ok && !strings.Contains(name, "/node_modules/") {
+				locked[name] = p.Version
+			}
+		}
+		for name, d := range pl.Dependencies {
+			if _, ok := locked[name]; !ok {
+				locked[name] = d.Version
+			}
+		}
+	}
+
+	var deps []model.Dependency
+	for _, group := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+		names := make([]string, 0, len(group))
+		for name := range group {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			dep := model.Dependency{
+				Name:      name,
+				Ecosystem: model.EcosystemNPM,
+				Direct:    true,
+				File:      PackageJSONFile,
+			}
+			if v, ok := locked[name]; ok {
+				dep.Version = v
+				dep.File = PackageLockFile
+			} else {
+				dep.Version = baseVersion(group[name])
+			}
+			if dep.Version == "" {
+				continue
+			}
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// baseVersion returns the lowest version of a simple npm range such as
+// "^1.2.3" or ">=2.0.0". Ranges it cannot reduce (tags, URLs, unions)
+// return "".
+func baseVersion(r string) string {
+	r = strings.TrimSpace(r)
+	r = strings.TrimLeft(r, "^~>=v ")
+	if r == "" || strings.ContainsAny(r, " |:/*xX") {
+		return ""
+	}
+	if r[0] < '0' || r[0] > '9' {
+		return ""
+	}
+	return r
+}
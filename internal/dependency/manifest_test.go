@@ -0,0 +1,105 @@
+package dependency
+
+import (
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+func TestParseGoMod(t *testing.T) {
+	content := `module github.com/example/test
+
+go 1.26
+
+require (
+	github.com/spf13/cobra v1.8.0
+	golang.org/x/sys v0.20.0 // indirect
+	example.com/local v1.0.0
+)
+
+require github.com/stretchr/testify v1.9.0
+
+replace example.com/local => ../local
+
+replace (
+	github.com/old/pkg => github.com/new/pkg v1.0.0
+)
+`
+	deps := ParseGoMod([]byte(content))
+
+	want := []model.Dependency{
+		{Name: "github.com/spf13/cobra", Version: "v1.8.0", Direct: true},
+		{Name: "golang.org/x/sys", Version: "v0.20.0", Direct: false},
+		{Name: "github.com/stretchr/testify", Version: "v1.9.0", Direct: true},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("ParseGoMod() = %+v, want %d deps", deps, len(want))
+	}
+	for i, w := range want {
+		got := deps[i]
+		if got.Name != w.Name || got.Version != w.Version || got.Direct != w.Direct {
+			t.Errorf("deps[%d] = %s %s direct=%v, want %s %s direct=%v",
+				i, got.Name, got.Version, got.Direct, w.Name, w.Version, w.Direct)
+		}
+		if got.Ecosystem != model.EcosystemGo || got.File != GoModFile {
+			t.Errorf("deps[%d] ecosystem/file = %s/%s", i, got.Ecosystem, got.File)
+		}
+	}
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	pkg := []byte(`{
+  "dependencies": {"react": "^18.2.0", "left-pad": "latest"},
+  "devDependencies": {"@types/node": "~20.1.0"}
+}`)
+	lock := []byte(`{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/react": {"version": "18.3.1"},
+    "node_modules/react/node_modules/loose-envify": {"version": "1.4.0"}
+  }
+}`)
+
+	tests := []struct {
+		name string
+		lock []byte
+		want map[string]string
+	}{
+		{
+			name: "without lockfile",
+			want: map[string]string{"react": "18.2.0", "@types/node": "20.1.0"},
+		},
+		{
+			name: "with lockfile",
+			lock: lock,
+			want: map[string]string{"react": "18.3.1", "@types/node": "20.1.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, err := ParsePackageJSON(pkg, tt.lock)
+			if err != nil {
+				t.Fatalf("ParsePackageJSON() error = %v", err)
+			}
+			if len(deps) != len(tt.want) {
+				t.Fatalf("ParsePackageJSON() = %+v, want %v", deps, tt.want)
+			}
+			for _, dep := range deps {
+				if dep.Version != tt.want[dep.Name] {
+					t.Errorf("%s version = %s, want %s", dep.Name, dep.Version, tt.want[dep.Name])
+				}
+				if dep.Ecosystem != model.EcosystemNPM || !dep.Direct {
+					t.Errorf("%s ecosystem/direct = %s/%v", dep.Name, dep.Ecosystem, dep.Direct)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePackageJSONInvalid(t *testing.T) {
+	if _, err := ParsePackageJSON([]byte(`{`), nil); err == nil {
+		t.Error("ParsePackageJSON() expected error for invalid JSON")
+	}
+}
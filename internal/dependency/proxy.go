@@ -0,0 +1,251 @@
+package dependency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// Default upstream registries.
+const (
+	DefaultGoProxy     = "https://proxy.golang.org"
+	DefaultNPMRegistry = "https://registry.npmjs.org"
+)
+
+// VersionInfo describes a released version.
+type VersionInfo struct {
+	Version string
+	Time    time.Time
+}
+
+// VersionSource looks up release information for packages of one ecosystem.
+type VersionSource interface {
+	// Info returns release information for a specific version.
+	Info(ctx context.Context, name, version string) (VersionInfo, error)
+	// Latest returns the latest released version.
+	Latest(ctx context.Context, name string) (VersionInfo, error)
+}
+
+// GoProxy queries a Go module proxy. URL may be an http(s) URL or a
+// file:// URL pointing to a directory laid out like a proxy, as accepted
+// by GOPROXY.
+type GoProxy struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewGoProxy creates a Go module proxy client. If proxyURL is empty, the
+// first usable entry of $GOPROXY is used, falling back to proxy.golang.org.
+func NewGoProxy(proxyURL string) *GoProxy {
+	if proxyURL == "" {
+		proxyURL = goproxyFromEnv()
+	}
+	return &GoProxy{
+		URL:    strings.TrimSuffix(proxyURL, "/"),
+		Client: http.DefaultClient,
+	}
+}
+
+// goproxyFromEnv returns the first proxy URL in $GOPROXY, skipping the
+// "direct" and "off" keywords.
+func goproxyFromEnv() string {
+	for entry := range strings.FieldsFuncSeq(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+		if entry != "direct" && entry != "off" {
+			return entry
+		}
+	}
+	return DefaultGoProxy
+}
+
+type goproxyInfo struct {
+	Version string    `json:"Version"`
+	Time    time.Time `json:"Time"`
+}
+
+// Info implements VersionSource.
+func (p *GoProxy) Info(ctx context.Context, module, version string) (VersionInfo, error) {
+	escapedVersion, err := escapePath(version)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	return p.fetchInfo(ctx, module, "@v/"+escapedVersion+".info")
+}
+
+// Latest implements VersionSource. Proxies without @latest, such as the
+// file-based $GOMODCACHE/cache/download, are asked for @v/list instead,
+// and the highest release, or pre-release if there is none, is used as
+// cmd/go does.
+func (p *GoProxy) Latest(ctx context.Context, module string) (VersionInfo, error) {
+	info, err := p.fetchInfo(ctx, module, "@latest")
+	if err == nil {
+		return info, nil
+	}
+	version, listErr := p.latestListed(ctx, module)
+	if listErr != nil {
+		return VersionInfo{}, errors.Join(err, listErr)
+	}
+	return p.Info(ctx, module, version)
+}
+
+// latestListed returns the highest version in the module's @v/list,
+// preferring releases over pre-releases.
+func (p *GoProxy) latestListed(ctx context.Context, module string) (string, error) {
+	escaped, err := escapePath(module)
+	if err != nil {
+		return "", err
+	}
+	data, err := fetch(ctx, p.Client, p.URL+"/"+escaped+"/@v/list")
+	if err != nil {
+		return "", fmt.Errorf("fetching %s @v/list: %w", module, err)
+	}
+
+	var release, prerelease string
+	for _, v := range strings.Fields(string(data)) {
+		switch {
+		case !semver.IsValid(v):
+		case semver.Prerelease(v) == "":
+			if release == "" || semver.Compare(v, release) > 0 {
+				release = v
+			}
+		case prerelease == "" || semver.Compare(v, prerelease) > 0:
+			prerelease = v
+		}
+	}
+	switch {
+	case release != "":
+		return release, nil
+	case prerelease != "":
+		return prerelease, nil
+	}
+	return "", fmt.Errorf("%s has no listed versions", module)
+}
+
+func (p *GoProxy) fetchInfo(ctx context.Context, module, suffix string) (VersionInfo, error) {
+	escaped, err := escapePath(module)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+
+	data, err := fetch(ctx, p.Client, p.URL+"/"+escaped+"/"+suffix)
+	if err != nil {
+		return VersionInfo{}, fmt.Errorf("fetching %s %s: %w", module, suffix, err)
+	}
+
+	var info goproxyInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return VersionInfo{}, fmt.Errorf("parsing %s %s: %w", module, suffix, err)
+	}
+	return VersionInfo(info), nil
+}
+
+// escapePath applies the module proxy case encoding: each upper-case
+// letter becomes "!" followed by the lower-case letter.
+func escapePath(s string) (string, error) {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '!':
+			return "", fmt.Errorf("invalid module path or version %q", s)
+		case r >= 'A' && r <= 'Z':
+			sb.WriteByte('!')
+			sb.WriteRune(r + ('a' - 'A'))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String(), nil
+}
+
+// NPMRegistry queries an npm registry.
+type NPMRegistry struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewNPMRegistry creates an npm registry client. If registryURL is empty,
+// the public npm registry is used.
+func NewNPMRegistry(registryURL string) *NPMRegistry {
+	if registryURL == "" {
+		registryURL = DefaultNPMRegistry
+	}
+	return &NPMRegistry{
+		URL:    strings.TrimSuffix(registryURL, "/"),
+		Client: http.DefaultClient,
+	}
+}
+
+type npmPackument struct {
+	DistTags map[string]string    `json:"dist-tags"`
+	Time     map[string]time.Time `json:"time"`
+}
+
+func (r *NPMRegistry) packument(ctx context.Context, name string) (*npmPackument, error) {
+	// Scoped packages keep "@" but escape the "/" separator.
+	data, err := fetch(ctx, r.Client, r.URL+"/"+strings.Replace(url.PathEscape(name), "%40", "@", 1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", name, err)
+	}
+	var p npmPackument
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return &p, nil
+}
+
+// Info implements VersionSource.
+func (r *NPMRegistry) Info(ctx context.Context, name, version string) (VersionInfo, error) {
+	p, err := r.packument(ctx, name)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	t, ok := p.Time[version]
+	if !ok {
+		return VersionInfo{}, fmt.Errorf("%s has no version %s", name, version)
+	}
+	return VersionInfo{Version: version, Time: t}, nil
+}
+
+// Latest implements VersionSource.
+func (r *NPMRegistry) Latest(ctx context.Context, name string) (VersionInfo, error) {
+	p, err := r.packument(ctx, name)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	latest, ok := p.DistTags["latest"]
+	if !ok {
+		return VersionInfo{}, fmt.Errorf("%s has no latest dist-tag", name)
+	}
+	return VersionInfo{Version: latest, Time: p.Time[latest]}, nil
+}
+
+// fetch reads an http(s) or file URL.
+func fetch(ctx context.Context, client *http.Client, rawURL string) ([]byte, error) {
+	if path, ok := strings.CutPrefix(rawURL, "file://"); ok {
+		return os.ReadFile(filepath.FromSlash(path))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
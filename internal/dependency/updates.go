@@ -0,0 +1,446 @@
+package dependency
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// renovateConfigFiles lists Renovate config locations in the order Renovate
+// itself searches them.
+var renovateConfigFiles = []string{
+	"renovate.json",
+	"renovate.json5",
+	".github/renovate.json",
+	".github/renovate.json5",
+	".gitlab/renovate.json",
+	".gitlab/renovate.json5",
+	".renovaterc",
+	".renovaterc.json",
+	".renovaterc.json5",
+}
+
+var dependabotConfigFiles = []string{
+	".github/dependabot.yml",
+	".github/dependabot.yaml",
+}
+
+// languageEcosystems maps GitHub linguist languages to the package
+// ecosystem an update tool must cover for them.
+var languageEcosystems = map[string]string{
+	"Go":         "gomod",
+	"JavaScript": "npm",
+	"TypeScript": "npm",
+	"Python":     "pip",
+	"Ruby":       "bundler",
+	"Rust":       "cargo",
+	"PHP":        "composer",
+	"C#":         "nuget",
+	"Dockerfile": "docker",
+	"HCL":        "terraform",
+}
+
+// renovateManagers maps Renovate manager names that differ from the
+// Dependabot ecosystem names.
+var renovateManagers = map[string]string{
+	"pip_requirements": "pip",
+	"pip_setup":        "pip",
+	"pip-compile":      "pip",
+	"pipenv":           "pip",
+	"poetry":           "pip",
+	"pep621":           "pip",
+	"dockerfile":       "docker",
+	"docker-compose":   "docker",
+}
+
+// DetectUpdateConfigs reads the Renovate and Dependabot configurations of
+// a repository. Files that exist but cannot be parsed are returned with
+// Error set. Read errors other than a missing file are returned, since
+// the tool cannot be reported as absent.
+func DetectUpdateConfigs(ctx context.Context, files FileGetter, repo model.Repo) ([]model.UpdateConfig, error) {
+	var configs []model.UpdateConfig
+
+	for _, file := range renovateConfigFiles {
+		content, ok, err := readOptional(ctx, files, repo, file)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		cfg, err := ParseRenovateConfig([]byte(content), file)
+		if err != nil {
+			cfg = &model.UpdateConfig{Tool: model.UpdateToolRenovate, File: file, Error: err.Error()}
+		}
+		configs = append(configs, *cfg)
+		break
+	}
+
+	for _, file := range dependabotConfigFiles {
+		content, ok, err := readOptional(ctx, files, repo, file)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		cfg, err := ParseDependabotConfig([]byte(content), file)
+		if err != nil {
+			cfg = &model.UpdateConfig{Tool: model.UpdateToolDependabot, File: file, Error: err.Error()}
+		}
+		configs = append(configs, *cfg)
+		break
+	}
+
+	return configs, nil
+}
+
+type renovateConfig struct {
+	Extends         []string  `yaml:"extends"`
+	Automerge       bool      `yaml:"automerge"`
+	Schedule        yaml.Node `yaml:"schedule"`
+	EnabledManagers []string  `yaml:"enabledManagers"`
+	PackageRules    []struct {
+		Automerge bool      `yaml:"automerge"`
+		Schedule  yaml.Node `yaml:"schedule"`
+	} `yaml:"packageRules"`
+}
+
+// ParseRenovateConfig parses a Renovate JSON or JSON5 configuration.
+func ParseRenovateConfig(content []byte, file string) (*model.UpdateConfig, error) {
+	// JSON5 without comments is accepted by the YAML flow syntax, which
+	// allows unquoted keys, single quotes, and trailing commas.
+	var rc renovateConfig
+	if err := yaml.Unmarshal(stripComments(content), &rc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+
+	cfg := &model.UpdateConfig{
+		Tool:          model.UpdateToolRenovate,
+		File:          file,
+		Extends:       rc.Extends,
+		AllEcosystems: len(rc.EnabledManagers) == 0,
+		Automerge:     rc.Automerge,
+		Schedule:      hasSchedule(rc.Schedule),
+	}
+	for _, rule := range rc.PackageRules {
+		cfg.Automerge = cfg.Automerge || rule.Automerge
+		cfg.Schedule = cfg.Schedule || hasSchedule(rule.Schedule)
+	}
+	for _, manager := range rc.EnabledManagers {
+		ecosystem := manager
+		if mapped, ok := renovateManagers[manager]; ok {
+			ecosystem = mapped
+		}
+		if !slices.Contains(cfg.Ecosystems, ecosystem) {
+			cfg.Ecosystems = append(cfg.Ecosystems, ecosystem)
+		}
+	}
+	slices.Sort(cfg.Ecosystems)
+
+	return cfg, nil
+}
+
+// hasSchedule reports whether a Renovate schedule (a string or list of
+// strings) restricts when updates run.
+func hasSchedule(node yaml.Node) bool {
+	var values []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		values = []string{node.Value}
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			values = append(values, n.Value)
+		}
+	}
+	return slices.ContainsFunc(values, func(v string) bool {
+		return v != "" && v != "at any time"
+	})
+}
+
+// stripComments removes // and /* */ comments outside of string literals.
+func stripComments(content []byte) []byte {
+	out := make([]byte, 0, len(content))
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(content) {
+				i++
+				out = append(out, content[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			out = append(out, c)
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			if i < len(content) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(string(content[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+type dependabotConfig struct {
+	Version int `yaml:"version"`
+	Updates []struct {
+		PackageEcosystem string `yaml:"package-ecosystem"`
+		Schedule         struct {
+			Interval string `yaml:"interval"`
+		} `yaml:"schedule"`
+	} `yaml:"updates"`
+}
+
+// ParseDependabotConfig parses and validates a dependabot.yml file.
+// Dependabot has no automerge setting; see UpdateReport for how
+// auto-merge workflows are detected.
+func ParseDependabotConfig(content []byte, file string) (*model.UpdateConfig, error) {
+	var dc dependabotConfig
+	if err := yaml.Unmarshal(content, &dc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	if dc.Version != 2 {
+		return nil, fmt.Errorf("%s: unsupported version %d, expected 2", file, dc.Version)
+	}
+	if len(dc.Updates) == 0 {
+		return nil, fmt.Errorf("%s: no updates configured", file)
+	}
+
+	cfg := &model.UpdateConfig{
+		Tool:     model.UpdateToolDependabot,
+		File:     file,
+		Schedule: true,
+	}
+	for i, u := range dc.Updates {
+		if u.PackageEcosystem == "" {
+			return nil, fmt.Errorf("%s: update %d has no package-ecosystem", file, i+1)
+		}
+		if u.Schedule.Interval == "" {
+			return nil, fmt.Errorf("%s: %s update has no schedule.interval", file, u.PackageEcosystem)
+		}
+		if !slices.Contains(cfg.Ecosystems, u.PackageEcosystem) {
+			cfg.Ecosystems = append(cfg.Ecosystems, u.PackageEcosystem)
+		}
+	}
+	slices.Sort(cfg.Ecosystems)
+
+	return cfg, nil
+}
+
+// UpdateReport summarizes how a repository keeps its dependencies updated.
+type UpdateReport struct {
+	Repo    model.Repo
+	Configs []model.UpdateConfig
+	// OrgPreset is the Renovate preset repositories should extend. When
+	// empty, any github>, local>, or npm preset of the repository owner
+	// counts.
+	OrgPreset string
+	// Expected lists the ecosystems implied by the repository's languages,
+	// plus github-actions when it has workflows.
+	Expected         []string
+	Covered          []string
+	Uncovered        []string
+	Automerge        bool
+	Schedule         bool
+	ExtendsOrgPreset bool
+}
+
+// AnalyzeUpdates checks update tool configurations against a repository's
+// languages and workflows.
+func AnalyzeUpdates(repo model.Repo, workflows []model.Workflow, configs []model.UpdateConfig, orgPreset string) *UpdateReport {
+	r := &UpdateReport{
+		Repo:      repo,
+		Configs:   configs,
+		OrgPreset: orgPreset,
+	}
+
+	for _, lang := range append([]string{repo.PrimaryLanguage}, repo.Languages...) {
+		if ecosystem, ok := languageEcosystems[lang]; ok && !slices.Contains(r.Expected, ecosystem) {
+			r.Expected = append(r.Expected, ecosystem)
+		}
+	}
+	if len(workflows) > 0 {
+		r.Expected = append(r.Expected, "github-actions")
+	}
+	slices.Sort(r.Expected)
+
+	allEcosystems := false
+	for _, cfg := range configs {
+		if cfg.Error != "" {
+			continue
+		}
+		allEcosystems = allEcosystems || cfg.AllEcosystems
+		for _, ecosystem := range cfg.Ecosystems {
+			if !slices.Contains(r.Covered, ecosystem) {
+				r.Covered = append(r.Covered, ecosystem)
+			}
+		}
+		r.Automerge = r.Automerge || cfg.Automerge
+		r.Schedule = r.Schedule || cfg.Schedule
+		if cfg.Tool == model.UpdateToolDependabot && slices.ContainsFunc(workflows, isDependabotAutomerge) {
+			r.Automerge = true
+		}
+		if cfg.Tool == model.UpdateToolRenovate && slices.ContainsFunc(cfg.Extends, r.isOrgPreset) {
+			r.ExtendsOrgPreset = true
+		}
+	}
+	if allEcosystems {
+		for _, ecosystem := range r.Expected {
+			if !slices.Contains(r.Covered, ecosystem) {
+				r.Covered = append(r.Covered, ecosystem)
+			}
+		}
+	}
+	slices.Sort(r.Covered)
+
+	for _, ecosystem := range r.Expected {
+		if !slices.Contains(r.Covered, ecosystem) {
+			r.Uncovered = append(r.Uncovered, ecosystem)
+		}
+	}
+
+	return r
+}
+
+// isDependabotAutomerge reports whether a workflow enables auto-merge for
+// Dependabot pull requests.
+func isDependabotAutomerge(wf model.Workflow) bool {
+	content := strings.ToLower(wf.Content)
+	return strings.Contains(content, "dependabot") &&
+		(strings.Contains(content, "merge --auto") || strings.Contains(content, "enable-pull-request-automerge"))
+}
+
+func (r *UpdateReport) isOrgPreset(preset string) bool {
+	preset = strings.ToLower(preset)
+	if r.OrgPreset != "" {
+		want := strings.ToLower(r.OrgPreset)
+		return preset == want || strings.HasPrefix(preset, want+":") || strings.HasPrefix(preset, want+"//")
+	}
+	owner := strings.ToLower(r.Repo.Owner)
+	if owner == "" {
+		return false
+	}
+	for _, prefix := range []string{"github>" + owner + "/", "local>" + owner + "/", "@" + owner + "/"} {
+		if strings.HasPrefix(preset, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply populates the update tool fields of a policy dependencies context.
+func (r *UpdateReport) Apply(dc *model.DependenciesContext) {
+	dc.HasRenovate = false
+	dc.HasDependabot = false
+	for _, cfg := range r.Configs {
+		switch cfg.Tool {
+		case model.UpdateToolRenovate:
+			dc.HasRenovate = true
+		case model.UpdateToolDependabot:
+			dc.HasDependabot = true
+		}
+	}
+	dc.UpdateEcosystems = r.Covered
+	dc.UncoveredEcosystems = r.Uncovered
+	dc.UpdateAutomerge = r.Automerge
+	dc.UpdateSchedule = r.Schedule
+	dc.ExtendsOrgPreset = r.ExtendsOrgPreset
+}
+
+// Violations converts the report into policy violations.
+func (r *UpdateReport) Violations() []model.Violation {
+	const policy = "dependencies/updates"
+
+	if len(r.Configs) == 0 {
+		return []model.Violation{{
+			Policy:      policy,
+			Rule:        "no-update-tool",
+			Message:     "No Renovate or Dependabot configuration found",
+			Severity:    model.SeverityMedium,
+			Remediation: "Add a renovate.json extending the organization preset or a .github/dependabot.yml",
+		}}
+	}
+
+	var violations []model.Violation
+	var valid *model.UpdateConfig
+	for i, cfg := range r.Configs {
+		if cfg.Error != "" {
+			violations = append(violations, model.Violation{
+				Policy:   policy,
+				Rule:     "invalid-config",
+				Message:  fmt.Sprintf("Invalid %s configuration: %s", cfg.Tool, cfg.Error),
+				Severity: model.SeverityHigh,
+				File:     cfg.File,
+			})
+			continue
+		}
+		if valid == nil {
+			valid = &r.Configs[i]
+		}
+	}
+	if valid == nil {
+		return violations
+	}
+
+	for _, ecosystem := range r.Uncovered {
+		violations = append(violations, model.Violation{
+			Policy:      policy,
+			Rule:        "ecosystem-not-covered",
+			Message:     fmt.Sprintf("%s dependencies are not updated by %s", ecosystem, valid.Tool),
+			Severity:    model.SeverityMedium,
+			Remediation: fmt.Sprintf("Add the %s ecosystem to %s", ecosystem, valid.File),
+			File:        valid.File,
+		})
+	}
+
+	if !r.Schedule {
+		violations = append(violations, model.Violation{
+			Policy:      policy,
+			Rule:        "no-schedule",
+			Message:     "Dependency updates have no schedule",
+			Severity:    model.SeverityLow,
+			Remediation: "Set a schedule to batch update pull requests",
+			File:        valid.File,
+		})
+	}
+
+	for _, cfg := range r.Configs {
+		if cfg.Tool != model.UpdateToolRenovate || cfg.Error != "" || r.ExtendsOrgPreset {
+			continue
+		}
+		remediation := "Extend the organization's shared Renovate preset"
+		if r.OrgPreset != "" {
+			remediation = fmt.Sprintf("Add %q to extends", r.OrgPreset)
+		}
+		violations = append(violations, model.Violation{
+			Policy:      policy,
+			Rule:        "org-preset-not-extended",
+			Message:     "Renovate configuration does not extend the organization preset",
+			Severity:    model.SeverityLow,
+			Remediation: remediation,
+			File:        cfg.File,
+		})
+	}
+
+	return violations
+}
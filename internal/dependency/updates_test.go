@@ -0,0 +1,250 @@
+package dependency
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+func TestParseRenovateConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantEcos      []string
+		wantAll       bool
+		wantAutomerge bool
+		wantSchedule  bool
+		wantExtends   []string
+		wantErr       bool
+	}{
+		{
+			name:        "json defaults",
+			content:     `{"$schema": "https://docs.renovatebot.com/renovate-schema.json", "extends": ["config:recommended"]}`,
+			wantAll:     true,
+			wantExtends: []string{"config:recommended"},
+		},
+		{
+			name: "json5 with comments",
+			content: `{
+  // shared org settings
+  extends: ['github>myorg/renovate-config'],
+  enabledManagers: ['gomod', 'dockerfile', 'github-actions',],
+  /* weekly batches */
+  schedule: ['before 6am on monday'],
+  packageRules: [{matchUpdateTypes: ['patch'], automerge: true}],
+}`,
+			wantEcos:      []string{"docker", "github-actions", "gomod"},
+			wantAutomerge: true,
+			wantSchedule:  true,
+			wantExtends:   []string{"github>myorg/renovate-config"},
+		},
+		{
+			name:    "at any time is not a schedule",
+			content: `{"schedule": "at any time", "automerge": true}`,
+			wantAll: true, wantAutomerge: true,
+		},
+		{
+			name:    "invalid",
+			content: `{"extends": [`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseRenovateConfig([]byte(tt.content), "renovate.json5")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRenovateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(cfg.Ecosystems, tt.wantEcos) || cfg.AllEcosystems != tt.wantAll {
+				t.Errorf("ecosystems = %v all=%v, want %v all=%v", cfg.Ecosystems, cfg.AllEcosystems, tt.wantEcos, tt.wantAll)
+			}
+			if cfg.Automerge != tt.wantAutomerge || cfg.Schedule != tt.wantSchedule {
+				t.Errorf("automerge=%v schedule=%v, want %v %v", cfg.Automerge, cfg.Schedule, tt.wantAutomerge, tt.wantSchedule)
+			}
+			if !slices.Equal(cfg.Extends, tt.wantExtends) {
+				t.Errorf("extends = %v, want %v", cfg.Extends, tt.wantExtends)
+			}
+		})
+	}
+}
+
+func TestParseDependabotConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantEcos []string
+		wantErr  bool
+	}{
+		{
+			name: "valid",
+			content: `version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: weekly
+  - package-ecosystem: github-actions
+    directory: /
+    schedule:
+      interval: monthly
+`,
+			wantEcos: []string{"github-actions", "gomod"},
+		},
+		{
+			name:    "wrong version",
+			content: "version: 1\nupdates: []\n",
+			wantErr: true,
+		},
+		{
+			name: "missing schedule",
+			content: `version: 2
+updates:
+  - package-ecosystem: npm
+    directory: /
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseDependabotConfig([]byte(tt.content), ".github/dependabot.yml")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDependabotConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(cfg.Ecosystems, tt.wantEcos) || !cfg.Schedule {
+				t.Errorf("ecosystems = %v schedule=%v, want %v", cfg.Ecosystems, cfg.Schedule, tt.wantEcos)
+			}
+		})
+	}
+}
+
+func TestAnalyzeUpdates(t *testing.T) {
+	repo := model.Repo{
+		Owner:           "myorg",
+		FullName:        "myorg/app",
+		PrimaryLanguage: "Go",
+		Languages:       []string{"Go", "TypeScript", "Shell"},
+	}
+	workflows := []model.Workflow{{
+		Path:    ".github/workflows/dependabot-automerge.yml",
+		Content: "if: github.actor == 'dependabot[bot]'\nrun: gh pr merge --auto --squash \"$PR_URL\"\n",
+	}}
+
+	t.Run("no tool", func(t *testing.T) {
+		r := AnalyzeUpdates(repo, workflows, nil, "")
+		v := r.Violations()
+		if len(v) != 1 || v[0].Rule != "no-update-tool" {
+			t.Errorf("Violations() = %+v, want no-update-tool", v)
+		}
+	})
+
+	t.Run("dependabot missing npm", func(t *testing.T) {
+		files := fakeFiles{".github/dependabot.yml": `version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule: {interval: weekly}
+  - package-ecosystem: github-actions
+    directory: /
+    schedule: {interval: weekly}
+`}
+		configs, err := DetectUpdateConfigs(context.Background(), files, repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := AnalyzeUpdates(repo, workflows, configs, "")
+
+		if !slices.Equal(r.Expected, []string{"github-actions", "gomod", "npm"}) {
+			t.Errorf("Expected = %v", r.Expected)
+		}
+		if !slices.Equal(r.Uncovered, []string{"npm"}) {
+			t.Errorf("Uncovered = %v, want [npm]", r.Uncovered)
+		}
+		if !r.Automerge || !r.Schedule {
+			t.Errorf("automerge=%v schedule=%v, want both", r.Automerge, r.Schedule)
+		}
+
+		var dc model.DependenciesContext
+		r.Apply(&dc)
+		if !dc.HasDependabot || dc.HasRenovate || !slices.Equal(dc.UncoveredEcosystems, []string{"npm"}) {
+			t.Errorf("Apply() = %+v", dc)
+		}
+
+		v := r.Violations()
+		if len(v) != 1 || v[0].Rule != "ecosystem-not-covered" || v[0].File != ".github/dependabot.yml" {
+			t.Errorf("Violations() = %+v, want ecosystem-not-covered", v)
+		}
+	})
+
+	t.Run("renovate without org preset", func(t *testing.T) {
+		files := fakeFiles{
+			".github/renovate.json5": `{extends: ['config:recommended']}`,
+			".renovaterc":            `{"extends": ["github>myorg/renovate-config"]}`,
+		}
+		configs, err := DetectUpdateConfigs(context.Background(), files, repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(configs) != 1 || configs[0].File != ".github/renovate.json5" {
+			t.Fatalf("DetectUpdateConfigs() = %+v, want .github/renovate.json5 only", configs)
+		}
+		r := AnalyzeUpdates(repo, nil, configs, "")
+		if len(r.Uncovered) != 0 || r.ExtendsOrgPreset {
+			t.Errorf("uncovered=%v extendsOrgPreset=%v", r.Uncovered, r.ExtendsOrgPreset)
+		}
+
+		var rules []string
+		for _, v := range r.Violations() {
+			rules = append(rules, v.Rule)
+		}
+		if !slices.Equal(rules, []string{"no-schedule", "org-preset-not-extended"}) {
+			t.Errorf("Violations() rules = %v", rules)
+		}
+	})
+
+	t.Run("renovate extends configured preset", func(t *testing.T) {
+		configs := []model.UpdateConfig{{
+			Tool:          model.UpdateToolRenovate,
+			File:          "renovate.json",
+			AllEcosystems: true,
+			Schedule:      true,
+			Extends:       []string{"github>platform/renovate-config:go"},
+		}}
+		r := AnalyzeUpdates(repo, nil, configs, "github>platform/renovate-config")
+		if !r.ExtendsOrgPreset {
+			t.Error("ExtendsOrgPreset = false, want true")
+		}
+		if v := r.Violations(); len(v) != 0 {
+			t.Errorf("Violations() = %+v, want none", v)
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		files := fakeFiles{"renovate.json": `{"extends": [`}
+		configs, err := DetectUpdateConfigs(context.Background(), files, repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := AnalyzeUpdates(repo, nil, configs, "").Violations()
+		if len(v) != 1 || v[0].Rule != "invalid-config" || v[0].Severity != model.SeverityHigh {
+			t.Errorf("Violations() = %+v, want invalid-config", v)
+		}
+	})
+
+	t.Run("unreadable config", func(t *testing.T) {
+		files := fakeFiles{"error:.github/dependabot.yml": "server error"}
+		if configs, err := DetectUpdateConfigs(context.Background(), files, repo); err == nil {
+			t.Errorf("DetectUpdateConfigs() = %+v, want read error", configs)
+		}
+	})
+}
@@ -0,0 +1,395 @@
+package dependency
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// osvEcosystems maps OSV ecosystem names to dependency ecosystems.
+var osvEcosystems = map[string]string{
+	"Go":  model.EcosystemGo,
+	"npm": model.EcosystemNPM,
+}
+
+// osvEntry is the subset of the OSV schema used for matching.
+// See https://ossf.github.io/osv-schema/.
+type osvEntry struct {
+	ID               string        `json:"id"`
+	Aliases          []string      `json:"aliases"`
+	Summary          string        `json:"summary"`
+	Details          string        `json:"details"`
+	Withdrawn        string        `json:"withdrawn"`
+	Affected         []osvAffected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string     `json:"type"`
+		Events []osvEvent `json:"events"`
+	} `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+// VulnDB is an in-memory vulnerability database loaded from an offline
+// OSV snapshot. It never makes network requests.
+type VulnDB struct {
+	// entries is keyed by ecosystem and package name.
+	entries map[string][]*osvEntry
+}
+
+func vulnKey(ecosystem, name string) string {
+	return ecosystem + "\x00" + name
+}
+
+// LoadVulnDB loads OSV advisories from path, which may be:
+//   - a JSON file holding one OSV entry or an array of entries,
+//   - a zip archive of OSV JSON files, as published by osv.dev, or
+//   - a directory of OSV JSON files, such as a govulncheck vulnerability
+//     database (its index directory is ignored).
+func LoadVulnDB(path string) (*VulnDB, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading vulnerability database: %w", err)
+	}
+
+	db := &VulnDB{entries: make(map[string][]*osvEntry)}
+
+	switch {
+	case info.IsDir():
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == "index" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(p) != ".json" || d.Name() == "index.json" || d.Name() == "aliases.json" {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return db.add(data, p)
+		})
+	case filepath.Ext(path) == ".zip":
+		err = db.addZip(path)
+	default:
+		var data []byte
+		data, err = os.ReadFile(path)
+		if err == nil {
+			err = db.add(data, path)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading vulnerability database: %w", err)
+	}
+
+	return db, nil
+}
+
+func (db *VulnDB) addZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || filepath.Ext(f.Name) != ".json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := db.add(data, f.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add indexes the OSV entry or array of entries in data.
+func (db *VulnDB) add(data []byte, name string) error {
+	var entries []*osvEntry
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("parsing %s: %w", name, err)
+		}
+	} else {
+		var entry osvEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("parsing %s: %w", name, err)
+		}
+		entries = []*osvEntry{&entry}
+	}
+
+	for _, entry := range entries {
+		if entry.ID == "" || entry.Withdrawn != "" {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, a := range entry.Affected {
+			ecosystem, ok := osvEcosystems[a.Package.Ecosystem]
+			if !ok {
+				continue
+			}
+			key := vulnKey(ecosystem, a.Package.Name)
+			if !seen[key] {
+				seen[key] = true
+				db.entries[key] = append(db.entries[key], entry)
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of indexed package advisories.
+func (db *VulnDB) Len() int {
+	n := 0
+	for _, entries := range db.entries {
+		n += len(entries)
+	}
+	return n
+}
+
+// Match returns the advisories affecting the given dependencies.
+func (db *VulnDB) Match(deps []model.Dependency) []model.Vulnerability {
+	var vulns []model.Vulnerability
+	for _, dep := range deps {
+		for _, entry := range db.entries[vulnKey(dep.Ecosystem, dep.Name)] {
+			fixed, affected := entry.affects(dep.Ecosystem, dep.Name, dep.Version)
+			if !affected {
+				continue
+			}
+			summary := entry.Summary
+			if summary == "" {
+				summary, _, _ = strings.Cut(entry.Details, "\n")
+			}
+			vulns = append(vulns, model.Vulnerability{
+				ID:           entry.ID,
+				Aliases:      entry.Aliases,
+				Summary:      summary,
+				Severity:     entry.severity(),
+				Package:      dep.Name,
+				Version:      dep.Version,
+				Ecosystem:    dep.Ecosystem,
+				File:         dep.File,
+				FixedVersion: fixed,
+			})
+		}
+	}
+	return vulns
+}
+
+// Scan collects all dependencies of a repository, including transitive
+// ones, and matches them against the database.
+func (db *VulnDB) Scan(ctx context.Context, files FileGetter, repo model.Repo) ([]model.Vulnerability, error) {
+	deps, err := CollectDependencies(ctx, files, repo, true)
+	if err != nil {
+		return nil, err
+	}
+	return db.Match(deps), nil
+}
+
+// severity maps the GitHub advisory severity to a model severity.
+// Advisories without one, such as Go vulnerability database entries,
+// are reported as high.
+func (e *osvEntry) severity() model.Severity {
+	switch strings.ToUpper(e.DatabaseSpecific.Severity) {
+	case "CRITICAL":
+		return model.SeverityCritical
+	case "MODERATE", "MEDIUM":
+		return model.SeverityMedium
+	case "LOW":
+		return model.SeverityLow
+	default:
+		return model.SeverityHigh
+	}
+}
+
+// affects reports whether version of the package is affected, and the
+// lowest fixed version above it.
+func (e *osvEntry) affects(ecosystem, name, version string) (string, bool) {
+	for _, a := range e.Affected {
+		if osvEcosystems[a.Package.Ecosystem] != ecosystem || a.Package.Name != name {
+			continue
+		}
+		if slices.ContainsFunc(a.Versions, func(v string) bool { return compareVersions(v, version) == 0 }) {
+			return fixedAfter(a, version), true
+		}
+		for _, r := range a.Ranges {
+			if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+				continue
+			}
+			if inRange(r.Events, version) {
+				return fixedAfter(a, version), true
+			}
+		}
+	}
+	return "", false
+}
+
+// inRange evaluates OSV range events against version.
+func inRange(events []osvEvent, version string) bool {
+	sorted := slices.Clone(events)
+	slices.SortStableFunc(sorted, func(a, b osvEvent) int {
+		return compareVersions(a.version(), b.version())
+	})
+
+	affected := false
+	for _, ev := range sorted {
+		switch {
+		case ev.Introduced != "":
+			if ev.Introduced == "0" || compareVersions(version, ev.Introduced) >= 0 {
+				affected = true
+			}
+		case ev.Fixed != "":
+			if compareVersions(version, ev.Fixed) >= 0 {
+				affected = false
+			}
+		case ev.LastAffected != "":
+			if compareVersions(version, ev.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func (ev osvEvent) version() string {
+	switch {
+	case ev.Introduced != "":
+		return ev.Introduced
+	case ev.Fixed != "":
+		return ev.Fixed
+	default:
+		return ev.LastAffected
+	}
+}
+
+// fixedAfter returns the lowest fixed version above version, keeping the
+// "v" prefix convention of version.
+func fixedAfter(a osvAffected, version string) string {
+	var fixed string
+	for _, r := range a.Ranges {
+		for _, ev := range r.Events {
+			if ev.Fixed == "" || compareVersions(ev.Fixed, version) <= 0 {
+				continue
+			}
+			if fixed == "" || compareVersions(ev.Fixed, fixed) < 0 {
+				fixed = ev.Fixed
+			}
+		}
+	}
+	if fixed != "" && strings.HasPrefix(version, "v") && !strings.HasPrefix(fixed, "v") {
+		fixed = "v" + fixed
+	}
+	return fixed
+}
+
+// compareVersions compares two semantic versions, ignoring a leading "v"
+// and build metadata. Missing minor or patch components count as zero.
+func compareVersions(a, b string) int {
+	a, _, _ = strings.Cut(strings.TrimPrefix(a, "v"), "+")
+	b, _, _ = strings.Cut(strings.TrimPrefix(b, "v"), "+")
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+
+	aParts := strings.Split(aCore, ".")
+	bParts := strings.Split(bCore, ".")
+	for i := range max(len(aParts), len(bParts), 3) {
+		if c := compareIdentifier(part(aParts, i), part(bParts, i)); c != 0 {
+			return c
+		}
+	}
+
+	// A release sorts after its prereleases.
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	aIDs := strings.Split(aPre, ".")
+	bIDs := strings.Split(bPre, ".")
+	for i := range min(len(aIDs), len(bIDs)) {
+		if c := compareIdentifier(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(aIDs) - len(bIDs)
+}
+
+func part(parts []string, i int) string {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return "0"
+}
+
+// compareIdentifier compares numeric identifiers numerically and others
+// lexically, with numeric identifiers sorting first.
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// SummarizeVulnerabilities populates the vulnerability fields of a policy
+// dependencies context.
+func SummarizeVulnerabilities(vulns []model.Vulnerability, dc *model.DependenciesContext) {
+	dc.VulnerabilityCount = len(vulns)
+	dc.HasVulnerabilities = len(vulns) > 0
+}
@@ -0,0 +1,203 @@
+package dependency
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+const goVulnEntry = `{
+  "id": "GO-2024-0001",
+  "aliases": ["CVE-2024-0001"],
+  "summary": "Panic on malformed input in example.com/parser",
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "example.com/parser"},
+    "ranges": [{"type": "SEMVER", "events": [
+      {"introduced": "0"}, {"fixed": "1.2.0"},
+      {"introduced": "1.3.0"}, {"fixed": "1.3.4"}
+    ]}]
+  }]
+}`
+
+const npmVulnEntries = `[
+  {
+    "id": "GHSA-aaaa-bbbb-cccc",
+    "summary": "Prototype pollution in lodash",
+    "database_specific": {"severity": "CRITICAL"},
+    "affected": [{
+      "package": {"ecosystem": "npm", "name": "lodash"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-dddd-eeee-ffff",
+    "summary": "ReDoS in minimist",
+    "database_specific": {"severity": "MODERATE"},
+    "affected": [{
+      "package": {"ecosystem": "npm", "name": "minimist"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "1.0.0"}, {"last_affected": "1.2.5"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-withdrawn",
+    "withdrawn": "2024-01-01T00:00:00Z",
+    "affected": [{"package": {"ecosystem": "npm", "name": "lodash"}, "versions": ["4.17.15"]}]
+  }
+]`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadVulnDB(t *testing.T) {
+	dir := t.TempDir()
+
+	// govulncheck database layout
+	vulndb := filepath.Join(dir, "vulndb")
+	writeFile(t, filepath.Join(vulndb, "ID", "GO-2024-0001.json"), goVulnEntry)
+	writeFile(t, filepath.Join(vulndb, "index", "db.json"), `{"modified":"2024-01-01T00:00:00Z"}`)
+	writeFile(t, filepath.Join(vulndb, "index", "modules.json"), `[{"path":"example.com/parser"}]`)
+
+	// single JSON export
+	export := filepath.Join(dir, "osv.json")
+	writeFile(t, export, npmVulnEntries)
+
+	// osv.dev zip export
+	archive := filepath.Join(dir, "all.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("GO-2024-0001.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(goVulnEntry)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"directory", vulndb, 1},
+		{"json", export, 2},
+		{"zip", archive, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := LoadVulnDB(tt.path)
+			if err != nil {
+				t.Fatalf("LoadVulnDB() error = %v", err)
+			}
+			if db.Len() != tt.want {
+				t.Errorf("Len() = %d, want %d", db.Len(), tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadVulnDB(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadVulnDB() expected error for missing path")
+	}
+}
+
+func TestVulnDBScan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "osv.json")
+	writeFile(t, path, "["+goVulnEntry+","+npmVulnEntries[1:])
+	db, err := LoadVulnDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := fakeFiles{
+		GoModFile: `module example.com/app
+
+require (
+	example.com/parser v1.3.1
+	example.com/other v1.0.0 // indirect
+)
+`,
+		PackageJSONFile: `{"dependencies": {"lodash": "^4.17.0"}}`,
+		PackageLockFile: `{"lockfileVersion": 3, "packages": {
+  "node_modules/lodash": {"version": "4.17.15"},
+  "node_modules/mkdirp/node_modules/minimist": {"version": "1.2.5"},
+  "node_modules/yargs/node_modules/minimist": {"version": "1.2.6"}
+}}`,
+	}
+
+	vulns, err := db.Scan(context.Background(), files, model.Repo{FullName: "myorg/app"})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	want := []model.Vulnerability{
+		{ID: "GO-2024-0001", Package: "example.com/parser", Version: "v1.3.1", Severity: model.SeverityHigh, FixedVersion: "v1.3.4", File: GoModFile},
+		{ID: "GHSA-aaaa-bbbb-cccc", Package: "lodash", Version: "4.17.15", Severity: model.SeverityCritical, FixedVersion: "4.17.21", File: PackageLockFile},
+		{ID: "GHSA-dddd-eeee-ffff", Package: "minimist", Version: "1.2.5", Severity: model.SeverityMedium, File: PackageLockFile},
+	}
+	if len(vulns) != len(want) {
+		t.Fatalf("Scan() = %+v, want %d vulnerabilities", vulns, len(want))
+	}
+	for i, w := range want {
+		got := vulns[i]
+		if got.ID != w.ID || got.Package != w.Package || got.Version != w.Version ||
+			got.Severity != w.Severity || got.FixedVersion != w.FixedVersion || got.File != w.File {
+			t.Errorf("vulns[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+
+	var dc model.DependenciesContext
+	SummarizeVulnerabilities(vulns, &dc)
+	if !dc.HasVulnerabilities || dc.VulnerabilityCount != 3 {
+		t.Errorf("SummarizeVulnerabilities() = %+v", dc)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.2", "1.0.0-alpha.10", -1},
+		{"1.0.0-beta", "1.0.0-alpha", 1},
+		{"1.0.0+build", "1.0.0", 0},
+		{"v0.0.0-20240101000000-abcdef", "0.0.1", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); sign(got) != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
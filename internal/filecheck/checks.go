@@ -0,0 +1,223 @@
+package filecheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// CODEOWNERS locations, in the order GitHub searches them.
+var codeownersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+var (
+	ownerHandle = regexp.MustCompile(`^@[A-Za-z0-9][A-Za-z0-9-]*(/[A-Za-z0-9._-]+)?$`)
+	ownerEmail  = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// CodeownersCheck checks that a CODEOWNERS file exists and uses valid
+// syntax: each rule is a pattern followed by @user, @org/team, or email
+// owners, without the negation and character range patterns GitHub does
+// not support.
+type CodeownersCheck struct{}
+
+// Name implements Check.
+func (CodeownersCheck) Name() string { return "codeowners" }
+
+// Run implements Check.
+func (c CodeownersCheck) Run(ctx context.Context, t *Target, fc *model.FilesContext) []model.Violation {
+	file, content, err := t.readFirst(ctx, codeownersFiles...)
+	if err != nil {
+		return []model.Violation{missing(c.Name(), err, "No CODEOWNERS file found",
+			"Add a .github/CODEOWNERS file assigning owners to the repository", model.SeverityMedium)}
+	}
+	fc.HasCodeowners = true
+
+	var violations []model.Violation
+	rules := 0
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		rules++
+
+		fields := strings.Fields(line)
+		if problem := codeownersProblem(fields); problem != "" {
+			violations = append(violations, model.Violation{
+				Policy:   "files/codeowners",
+				Rule:     "invalid",
+				Message:  fmt.Sprintf("CODEOWNERS line %d: %s", i+1, problem),
+				Severity: model.SeverityMedium,
+				File:     file,
+				Line:     i + 1,
+			})
+		}
+	}
+	if rules == 0 {
+		violations = append(violations, model.Violation{
+			Policy:   "files/codeowners",
+			Rule:     "invalid",
+			Message:  "CODEOWNERS has no rules",
+			Severity: model.SeverityMedium,
+			File:     file,
+		})
+	}
+
+	fc.CodeownersValid = len(violations) == 0
+	return violations
+}
+
+func codeownersProblem(fields []string) string {
+	pattern := fields[0]
+	switch {
+	case strings.HasPrefix(pattern, "!"):
+		return fmt.Sprintf("negated pattern %q is not supported", pattern)
+	case strings.ContainsAny(pattern, "[]"):
+		return fmt.Sprintf("character range in %q is not supported", pattern)
+	}
+	for _, owner := range fields[1:] {
+		if !ownerHandle.MatchString(owner) && !ownerEmail.MatchString(owner) {
+			return fmt.Sprintf("invalid owner %q", owner)
+		}
+	}
+	return ""
+}
+
+var licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "LICENCE.md", "COPYING"}
+
+// LicenseCheck checks that a LICENSE file exists and identifies its SPDX
+// license type.
+type LicenseCheck struct{}
+
+// Name implements Check.
+func (LicenseCheck) Name() string { return "license" }
+
+// Run implements Check.
+func (c LicenseCheck) Run(ctx context.Context, t *Target, fc *model.FilesContext) []model.Violation {
+	file, content, err := t.readFirst(ctx, licenseFiles...)
+	if err != nil {
+		return []model.Violation{missing(c.Name(), err, "No LICENSE file found",
+			"Add a LICENSE file with the organization's standard license", model.SeverityHigh)}
+	}
+	fc.HasLicense = true
+	fc.LicenseSPDX = DetectLicense(content)
+
+	switch {
+	case fc.LicenseSPDX == "":
+		return []model.Violation{{
+			Policy:      "files/license",
+			Rule:        "unrecognized",
+			Message:     "LICENSE does not match a known SPDX license",
+			Severity:    model.SeverityLow,
+			Remediation: "Use the unmodified license text or add an SPDX-License-Identifier line",
+			File:        file,
+		}}
+	case len(t.Config.AllowedLicenses) > 0 && !slices.ContainsFunc(t.Config.AllowedLicenses, func(id string) bool {
+		return NormalizeLicense(id) == fc.LicenseSPDX
+	}):
+		return []model.Violation{{
+			Policy:      "files/license",
+			Rule:        "not-allowed",
+			Message:     fmt.Sprintf("License %s is not one of %s", fc.LicenseSPDX, strings.Join(t.Config.AllowedLicenses, ", ")),
+			Severity:    model.SeverityMedium,
+			Remediation: "Relicense under an approved license",
+			File:        file,
+		}}
+	}
+	return nil
+}
+
+var securityPolicyFiles = []string{"SECURITY.md", ".github/SECURITY.md", "docs/SECURITY.md"}
+
+// SecurityPolicyCheck checks for a SECURITY.md vulnerability disclosure policy.
+type SecurityPolicyCheck struct{}
+
+// Name implements Check.
+func (SecurityPolicyCheck) Name() string { return "security-policy" }
+
+// Run implements Check.
+func (c SecurityPolicyCheck) Run(ctx context.Context, t *Target, fc *model.FilesContext) []model.Violation {
+	if _, _, err := t.readFirst(ctx, securityPolicyFiles...); err != nil {
+		return []model.Violation{missing(c.Name(), err, "No SECURITY.md found",
+			"Add a SECURITY.md describing how to report vulnerabilities", model.SeverityLow)}
+	}
+	fc.HasSecurityPolicy = true
+	return nil
+}
+
+// EditorconfigCheck checks for an .editorconfig file.
+type EditorconfigCheck struct{}
+
+// Name implements Check.
+func (EditorconfigCheck) Name() string { return "editorconfig" }
+
+// Run implements Check.
+func (c EditorconfigCheck) Run(ctx context.Context, t *Target, fc *model.FilesContext) []model.Violation {
+	if _, _, err := t.readFirst(ctx, ".editorconfig"); err != nil {
+		return []model.Violation{missing(c.Name(), err, "No .editorconfig found",
+			"Add an .editorconfig with root = true", model.SeverityInfo)}
+	}
+	fc.HasEditorconfig = true
+	return nil
+}
+
+var readmeFiles = []string{"README.md", "README", "README.markdown", "README.rst", "readme.md"}
+
+// ReadmeBadgeCheck checks that the README shows a status badge for each
+// configured workflow the repository has.
+type ReadmeBadgeCheck struct{}
+
+// Name implements Check.
+func (ReadmeBadgeCheck) Name() string { return "readme-badges" }
+
+// Run implements Check.
+func (c ReadmeBadgeCheck) Run(ctx context.Context, t *Target, fc *model.FilesContext) []model.Violation {
+	var wanted []string
+	for _, name := range t.Config.BadgeWorkflows {
+		if slices.ContainsFunc(t.Workflows, func(wf model.Workflow) bool {
+			return path.Base(wf.Path) == name
+		}) {
+			wanted = append(wanted, name)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	file, content, err := t.readFirst(ctx, readmeFiles...)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			fc.MissingReadmeBadges = wanted
+		}
+		return []model.Violation{missing(c.Name(), err, "No README found",
+			"Add a README.md with workflow status badges", model.SeverityLow)}
+	}
+
+	var violations []model.Violation
+	for _, name := range wanted {
+		if strings.Contains(content, "/actions/workflows/"+name+"/badge.svg") {
+			continue
+		}
+		fc.MissingReadmeBadges = append(fc.MissingReadmeBadges, name)
+		base := fmt.Sprintf("https://github.com/%s/actions/workflows/%s", t.Repo.FullName, name)
+		violations = append(violations, model.Violation{
+			Policy:      "files/readme-badges",
+			Rule:        "badge-missing",
+			Message:     fmt.Sprintf("README has no status badge for %s", name),
+			Severity:    model.SeverityLow,
+			Remediation: fmt.Sprintf("Add [![%s](%s/badge.svg)](%s)", strings.TrimSuffix(name, path.Ext(name)), base, base),
+			File:        file,
+		})
+	}
+	return violations
+}
@@ -0,0 +1,128 @@
+// Package filecheck checks the repository files that accompany CI
+// configuration, such as CODEOWNERS, LICENSE, and linter configs.
+package filecheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// FileGetter reads files from a repository. collector.Collector satisfies it.
+// Errors for missing files must match fs.ErrNotExist.
+type FileGetter interface {
+	GetFileContent(ctx context.Context, repo model.Repo, path string) (string, error)
+}
+
+// Config configures the file checks.
+type Config struct {
+	// AllowedLicenses restricts LICENSE files to these SPDX identifiers.
+	// Empty allows any recognized license.
+	AllowedLicenses []string
+	// OrgGolangciConfig is the content of the organization's
+	// .golangci.yml. Repository configs extend it when they enable every
+	// linter it enables. Empty skips the comparison.
+	OrgGolangciConfig string
+	// BadgeWorkflows lists workflow file names, such as "go-ci.yaml", that
+	// need a status badge in the README. Empty skips the check.
+	BadgeWorkflows []string
+}
+
+// Target is the repository a check runs against.
+type Target struct {
+	Repo      model.Repo
+	Workflows []model.Workflow
+	Files     FileGetter
+	Config    Config
+}
+
+// readFirst returns the path and content of the first existing file. The
+// error matches fs.ErrNotExist when none exists; other read errors are
+// returned as they occur.
+func (t *Target) readFirst(ctx context.Context, paths ...string) (string, string, error) {
+	for _, path := range paths {
+		content, err := t.Files.GetFileContent(ctx, t.Repo, path)
+		if err == nil {
+			return path, content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return path, "", err
+		}
+	}
+	return "", "", fs.ErrNotExist
+}
+
+// Check is a single repository file check. Run records its Cedar
+// attributes in fc and returns any violations.
+type Check interface {
+	Name() string
+	Run(ctx context.Context, t *Target, fc *model.FilesContext) []model.Violation
+}
+
+// DefaultChecks returns the built-in checks.
+func DefaultChecks() []Check {
+	return []Check{
+		CodeownersCheck{},
+		LicenseCheck{},
+		SecurityPolicyCheck{},
+		GolangciCheck{},
+		ReadmeBadgeCheck{},
+		EditorconfigCheck{},
+	}
+}
+
+// Runner runs a set of file checks.
+type Runner struct {
+	Checks []Check
+	Config Config
+}
+
+// NewRunner creates a runner with the built-in checks. Additional checks
+// can be appended to Checks.
+func NewRunner(cfg Config) *Runner {
+	return &Runner{
+		Checks: DefaultChecks(),
+		Config: cfg,
+	}
+}
+
+// Run runs every check against a repository.
+func (r *Runner) Run(ctx context.Context, files FileGetter, repo model.Repo, workflows []model.Workflow) (model.FilesContext, []model.Violation) {
+	t := &Target{
+		Repo:      repo,
+		Workflows: workflows,
+		Files:     files,
+		Config:    r.Config,
+	}
+
+	var fc model.FilesContext
+	var violations []model.Violation
+	for _, check := range r.Checks {
+		violations = append(violations, check.Run(ctx, t, &fc)...)
+	}
+	return fc, violations
+}
+
+// missing returns the violation for an absent file, or for a file that
+// could not be read when err is not a not-found error.
+func missing(check string, err error, message, remediation string, severity model.Severity) model.Violation {
+	if !errors.Is(err, fs.ErrNotExist) {
+		return model.Violation{
+			Policy:      fmt.Sprintf("files/%s", check),
+			Rule:        "unreadable",
+			Message:     fmt.Sprintf("Could not read file: %v", err),
+			Severity:    model.SeverityInfo,
+			Remediation: "Check the token's repository access and re-run the scan",
+		}
+	}
+	return model.Violation{
+		Policy:      fmt.Sprintf("files/%s", check),
+		Rule:        "missing",
+		Message:     message,
+		Severity:    severity,
+		Remediation: remediation,
+	}
+}
@@ -0,0 +1,244 @@
+package filecheck
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+type fakeFiles map[string]string
+
+func (f fakeFiles) GetFileContent(_ context.Context, _ model.Repo, path string) (string, error) {
+	if content, ok := f[path]; ok {
+		return content, nil
+	}
+	if content, ok := f["error:"+path]; ok {
+		return "", errors.New(content)
+	}
+	return "", fs.ErrNotExist
+}
+
+const mitLicense = `MIT License
+
+Copyright (c) 2025 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction...
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.`
+
+const orgGolangci = `version: "2"
+linters:
+  default: standard
+  enable:
+    - gosec
+    - revive
+`
+
+func rules(violations []model.Violation) []string {
+	var out []string
+	for _, v := range violations {
+		out = append(out, v.Policy+":"+v.Rule)
+	}
+	return out
+}
+
+func TestRunner(t *testing.T) {
+	goRepo := model.Repo{FullName: "testorg/app", PrimaryLanguage: "Go"}
+	workflows := []model.Workflow{{Path: ".github/workflows/go-ci.yaml"}}
+
+	tests := []struct {
+		name      string
+		repo      model.Repo
+		files     fakeFiles
+		config    Config
+		wantRules []string
+		check     func(t *testing.T, fc model.FilesContext)
+	}{
+		{
+			name: "all present",
+			repo: goRepo,
+			files: fakeFiles{
+				".github/CODEOWNERS": "# owners\n* @testorg/maintainers\n/docs/ docs@example.com @alice\n",
+				"LICENSE":            mitLicense,
+				"SECURITY.md":        "# Security",
+				".golangci.yml":      "version: \"2\"\nlinters:\n  enable: [gosec, revive, misspell]\n",
+				"README.md":          "[![CI](https://github.com/testorg/app/actions/workflows/go-ci.yaml/badge.svg)](...)",
+				".editorconfig":      "root = true",
+			},
+			config: Config{
+				AllowedLicenses:   []string{"MIT", "Apache-2.0"},
+				OrgGolangciConfig: orgGolangci,
+				BadgeWorkflows:    []string{"go-ci.yaml", "release.yaml"},
+			},
+			check: func(t *testing.T, fc model.FilesContext) {
+				want := model.FilesContext{
+					HasCodeowners:      true,
+					CodeownersValid:    true,
+					HasLicense:         true,
+					LicenseSPDX:        "MIT",
+					HasSecurityPolicy:  true,
+					HasGolangciConfig:  true,
+					GolangciExtendsOrg: true,
+					HasEditorconfig:    true,
+				}
+				if !reflect.DeepEqual(fc, want) {
+					t.Errorf("FilesContext = %+v, want %+v", fc, want)
+				}
+			},
+		},
+		{
+			name:   "nothing present",
+			repo:   goRepo,
+			files:  fakeFiles{},
+			config: Config{BadgeWorkflows: []string{"go-ci.yaml"}},
+			wantRules: []string{
+				"files/codeowners:missing",
+				"files/license:missing",
+				"files/security-policy:missing",
+				"files/golangci:missing",
+				"files/readme-badges:missing",
+				"files/editorconfig:missing",
+			},
+			check: func(t *testing.T, fc model.FilesContext) {
+				if !slices.Equal(fc.MissingReadmeBadges, []string{"go-ci.yaml"}) {
+					t.Errorf("MissingReadmeBadges = %v", fc.MissingReadmeBadges)
+				}
+			},
+		},
+		{
+			name: "invalid and divergent",
+			repo: goRepo,
+			files: fakeFiles{
+				"CODEOWNERS":    "* @testorg/maintainers\n!vendor/ @alice\n*.go alice\n",
+				"LICENSE.md":    "All rights reserved.",
+				"SECURITY.md":   "# Security",
+				".golangci.yml": "linters:\n  disable-all: true\n  enable: [govet, errcheck]\n",
+				"README.md":     "# App",
+				".editorconfig": "root = true",
+			},
+			config: Config{
+				OrgGolangciConfig: orgGolangci,
+				BadgeWorkflows:    []string{"go-ci.yaml"},
+			},
+			wantRules: []string{
+				"files/codeowners:invalid",
+				"files/codeowners:invalid",
+				"files/license:unrecognized",
+				"files/golangci:diverges",
+				"files/readme-badges:badge-missing",
+			},
+			check: func(t *testing.T, fc model.FilesContext) {
+				if !fc.HasCodeowners || fc.CodeownersValid {
+					t.Errorf("codeowners = %v/%v, want present and invalid", fc.HasCodeowners, fc.CodeownersValid)
+				}
+				if fc.GolangciExtendsOrg {
+					t.Error("GolangciExtendsOrg = true, want false")
+				}
+			},
+		},
+		{
+			name: "license not allowed and non-Go repo",
+			repo: model.Repo{FullName: "testorg/web", PrimaryLanguage: "TypeScript"},
+			files: fakeFiles{
+				"CODEOWNERS":    "* @alice",
+				"COPYING":       "SPDX-License-Identifier: GPL-3.0-only",
+				"SECURITY.md":   "# Security",
+				".editorconfig": "root = true",
+			},
+			config:    Config{AllowedLicenses: []string{"MIT"}},
+			wantRules: []string{"files/license:not-allowed"},
+			check: func(t *testing.T, fc model.FilesContext) {
+				if fc.LicenseSPDX != "GPL-3.0-only" {
+					t.Errorf("LicenseSPDX = %q", fc.LicenseSPDX)
+				}
+			},
+		},
+		{
+			name: "deprecated allowed license and read errors",
+			repo: model.Repo{FullName: "testorg/web", PrimaryLanguage: "TypeScript"},
+			files: fakeFiles{
+				"error:.github/CODEOWNERS": "server error",
+				"LICENSE":                  "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007",
+				"SECURITY.md":              "# Security",
+				".editorconfig":            "root = true",
+				"error:README.md":          "rate limited",
+			},
+			config:    Config{AllowedLicenses: []string{"GPL-3.0"}, BadgeWorkflows: []string{"go-ci.yaml"}},
+			wantRules: []string{"files/codeowners:unreadable", "files/readme-badges:unreadable"},
+			check: func(t *testing.T, fc model.FilesContext) {
+				if fc.HasCodeowners || len(fc.MissingReadmeBadges) != 0 {
+					t.Errorf("FilesContext = %+v, want unreadable files not recorded", fc)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc, violations := NewRunner(tt.config).Run(context.Background(), tt.files, tt.repo, workflows)
+			if got := rules(violations); !slices.Equal(got, tt.wantRules) {
+				t.Errorf("violations = %v, want %v", got, tt.wantRules)
+			}
+			if tt.check != nil {
+				tt.check(t, fc)
+			}
+		})
+	}
+}
+
+func TestReadmeBadgeCheckNoWorkflows(t *testing.T) {
+	target := &Target{Repo: model.Repo{FullName: "testorg/app"}, Files: fakeFiles{}, Config: Config{BadgeWorkflows: []string{"go-ci.yaml"}}}
+	var fc model.FilesContext
+	if v := (ReadmeBadgeCheck{}).Run(context.Background(), target, &fc); len(v) != 0 || len(fc.MissingReadmeBadges) != 0 {
+		t.Errorf("Run() = %+v, %v, want no badges required without workflows", v, fc.MissingReadmeBadges)
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{mitLicense, "MIT"},
+		{"Apache License\nVersion 2.0, January 2004", "Apache-2.0"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\nVersion 2.1, February 1999\n... GNU General Public License", "LGPL-2.1-only"},
+		{"GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007", "GPL-3.0-only"},
+		{"// SPDX-License-Identifier: GPL-3.0", "GPL-3.0-only"},
+		{"// SPDX-License-Identifier: GPL-3.0-or-later", "GPL-3.0-or-later"},
+		{"Redistribution and use in source and binary forms ... Neither the name of the copyright holder", "BSD-3-Clause"},
+		{"Redistribution and use in source and binary forms, with or without modification", "BSD-2-Clause"},
+		{"// SPDX-License-Identifier: MPL-2.0", "MPL-2.0"},
+		{"Proprietary. All rights reserved.", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectLicense(tt.text); got != tt.want {
+			t.Errorf("DetectLicense(%.30q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLicenseGNUTexts(t *testing.T) {
+	// The full texts mention other GNU licenses, such as the AGPL in GPL-3.0.
+	for _, file := range []string{"GPL-2.0.txt", "GPL-3.0.txt", "LGPL-2.1.txt", "LGPL-3.0.txt"} {
+		text, err := os.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.TrimSuffix(file, ".txt") + "-only"
+		if got := DetectLicense(string(text)); got != want {
+			t.Errorf("DetectLicense(%s) = %q, want %q", file, got, want)
+		}
+	}
+}
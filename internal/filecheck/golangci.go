@@ -0,0 +1,165 @@
+package filecheck
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+var golangciFiles = []string{".golangci.yml", ".golangci.yaml", ".golangci.json", ".golangci.toml"}
+
+// Linters enabled when a config does not disable the defaults.
+var (
+	golangciV1Defaults = []string{"errcheck", "gosimple", "govet", "ineffassign", "staticcheck", "unused"}
+	golangciV2Defaults = []string{"errcheck", "govet", "ineffassign", "staticcheck", "unused"}
+)
+
+// GolangciCheck checks that Go repositories have a golangci-lint config
+// and, when an organization config is set, that it enables at least the
+// linters the organization config enables.
+type GolangciCheck struct{}
+
+// Name implements Check.
+func (GolangciCheck) Name() string { return "golangci" }
+
+// Run implements Check.
+func (c GolangciCheck) Run(ctx context.Context, t *Target, fc *model.FilesContext) []model.Violation {
+	if !isGoRepo(t.Repo) {
+		return nil
+	}
+
+	file, content, err := t.readFirst(ctx, golangciFiles...)
+	if err != nil {
+		return []model.Violation{missing(c.Name(), err, "No golangci-lint config found",
+			"Add a .golangci.yml extending the organization config", model.SeverityMedium)}
+	}
+	fc.HasGolangciConfig = true
+
+	if t.Config.OrgGolangciConfig == "" || path.Ext(file) == ".toml" {
+		return nil
+	}
+	org, err := parseGolangci(t.Config.OrgGolangciConfig)
+	if err != nil {
+		return nil
+	}
+	repo, err := parseGolangci(content)
+	if err != nil {
+		return []model.Violation{{
+			Policy:   "files/golangci",
+			Rule:     "invalid",
+			Message:  fmt.Sprintf("Invalid golangci-lint config: %v", err),
+			Severity: model.SeverityMedium,
+			File:     file,
+		}}
+	}
+
+	missingLinters := repo.missing(org)
+	if len(missingLinters) == 0 {
+		fc.GolangciExtendsOrg = true
+		return nil
+	}
+	return []model.Violation{{
+		Policy:      "files/golangci",
+		Rule:        "diverges",
+		Message:     fmt.Sprintf("golangci-lint config does not enable org linters: %s", strings.Join(missingLinters, ", ")),
+		Severity:    model.SeverityLow,
+		Remediation: "Enable the organization's linters or copy its .golangci.yml",
+		File:        file,
+	}}
+}
+
+func isGoRepo(repo model.Repo) bool {
+	return repo.PrimaryLanguage == "Go" || slices.Contains(repo.Languages, "Go")
+}
+
+// golangciConfig is the linter selection of a golangci-lint v1 or v2 config.
+type golangciConfig struct {
+	Version string `yaml:"version"`
+	Linters struct {
+		Default    string   `yaml:"default"`
+		EnableAll  bool     `yaml:"enable-all"`
+		DisableAll bool     `yaml:"disable-all"`
+		Enable     []string `yaml:"enable"`
+		Disable    []string `yaml:"disable"`
+	} `yaml:"linters"`
+}
+
+// linterSet is the effective set of enabled linters. When all is set,
+// every linter except those in disabled is enabled.
+type linterSet struct {
+	all      bool
+	enabled  map[string]bool
+	disabled map[string]bool
+}
+
+// parseGolangci parses a YAML or JSON golangci-lint config.
+func parseGolangci(content string) (linterSet, error) {
+	var cfg golangciConfig
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return linterSet{}, err
+	}
+
+	s := linterSet{enabled: make(map[string]bool), disabled: make(map[string]bool)}
+	var defaults []string
+	if cfg.Version == "2" {
+		switch cfg.Linters.Default {
+		case "all":
+			s.all = true
+		case "none", "fast":
+		default:
+			defaults = golangciV2Defaults
+		}
+	} else {
+		switch {
+		case cfg.Linters.EnableAll:
+			s.all = true
+		case !cfg.Linters.DisableAll:
+			defaults = golangciV1Defaults
+		}
+	}
+
+	for _, name := range defaults {
+		s.enabled[name] = true
+	}
+	for _, name := range cfg.Linters.Enable {
+		s.enabled[name] = true
+	}
+	for _, name := range cfg.Linters.Disable {
+		delete(s.enabled, name)
+		s.disabled[name] = true
+	}
+	return s, nil
+}
+
+func (s linterSet) has(name string) bool {
+	return s.enabled[name] || (s.all && !s.disabled[name])
+}
+
+// missing returns the linters org enables that s does not.
+func (s linterSet) missing(org linterSet) []string {
+	var out []string
+	if org.all {
+		if !s.all {
+			return []string{"all"}
+		}
+		for name := range s.disabled {
+			if !org.disabled[name] {
+				out = append(out, name)
+			}
+		}
+	} else {
+		for name := range org.enabled {
+			if !s.has(name) {
+				out = append(out, name)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
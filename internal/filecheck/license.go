@@ -0,0 +1,78 @@
+package filecheck
+
+import (
+	"regexp"
+	"strings"
+)
+
+var spdxIdentifier = regexp.MustCompile(`SPDX-License-Identifier:\s*([A-Za-z0-9.+-]+)`)
+
+// licensePhrases identifies licenses by phrases from their standard text.
+// More specific licenses come first: the BSD-3-Clause text contains the
+// BSD-2-Clause conditions. The GNU licenses refer to each other, so they
+// are matched on their title and version header.
+var licensePhrases = []struct {
+	spdx    string
+	phrases []string
+}{
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"AGPL-3.0-only", []string{"gnu affero general public license version 3"}},
+	{"LGPL-3.0-only", []string{"gnu lesser general public license version 3"}},
+	{"LGPL-2.1-only", []string{"gnu lesser general public license version 2.1"}},
+	{"GPL-3.0-only", []string{"gnu general public license version 3"}},
+	{"GPL-2.0-only", []string{"gnu general public license version 2"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name of"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"MIT", []string{"permission is hereby granted, free of charge", "the above copyright notice and this permission notice shall be included"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+// deprecatedLicenses maps deprecated SPDX identifiers of the GNU licenses
+// to their current forms.
+var deprecatedLicenses = map[string]string{
+	"GPL-2.0":   "GPL-2.0-only",
+	"GPL-2.0+":  "GPL-2.0-or-later",
+	"GPL-3.0":   "GPL-3.0-only",
+	"GPL-3.0+":  "GPL-3.0-or-later",
+	"LGPL-2.1":  "LGPL-2.1-only",
+	"LGPL-2.1+": "LGPL-2.1-or-later",
+	"LGPL-3.0":  "LGPL-3.0-only",
+	"LGPL-3.0+": "LGPL-3.0-or-later",
+	"AGPL-3.0":  "AGPL-3.0-only",
+}
+
+// NormalizeLicense returns the current SPDX identifier for a deprecated
+// one, such as GPL-3.0-only for GPL-3.0, and other identifiers unchanged.
+func NormalizeLicense(id string) string {
+	if current, ok := deprecatedLicenses[id]; ok {
+		return current
+	}
+	return id
+}
+
+// DetectLicense returns the current SPDX identifier of a license text, or
+// "" when it is not recognized. An explicit SPDX-License-Identifier line
+// wins over the text itself. The GNU license texts do not say whether
+// later versions are allowed, so they are reported as the -only variants.
+func DetectLicense(text string) string {
+	if m := spdxIdentifier.FindStringSubmatch(text); m != nil {
+		return NormalizeLicense(m[1])
+	}
+
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	for _, l := range licensePhrases {
+		matched := true
+		for _, phrase := range l.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return l.spdx
+		}
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+// Package health analyzes the run history of CI workflows.
+package health
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// DefaultRunLimit is the default number of runs collected per workflow.
+const DefaultRunLimit = 20
+
+// RunLister lists workflow runs. collector.Collector satisfies it.
+type RunLister interface {
+	ListWorkflowRuns(ctx context.Context, repo model.Repo, workflowID int64, branch string, limit int) ([]model.WorkflowRun, error)
+}
+
+// Collect gathers the last limit completed runs of each active workflow
+// on the repository's default branch and summarizes them.
+func Collect(ctx context.Context, runs RunLister, repo model.Repo, workflows []model.Workflow, limit int) (*model.CIHealth, error) {
+	if limit <= 0 {
+		limit = DefaultRunLimit
+	}
+	branch := repo.DefaultBranch
+	if branch == "" {
+		branch = "main"
+	}
+
+	history := make(map[string][]model.WorkflowRun)
+	var active []model.Workflow
+	for _, wf := range workflows {
+		if wf.ID == 0 || (wf.State != "" && wf.State != "active") {
+			continue
+		}
+		wfRuns, err := runs.ListWorkflowRuns(ctx, repo, wf.ID, branch, limit)
+		if err != nil {
+			return nil, fmt.Errorf("collecting runs for %s: %w", wf.Path, err)
+		}
+		history[wf.Path] = wfRuns
+		active = append(active, wf)
+	}
+
+	h := Analyze(active, history)
+	h.Branch = branch
+	return h, nil
+}
+
+// Analyze summarizes run history keyed by workflow path, as collected now.
+func Analyze(workflows []model.Workflow, history map[string][]model.WorkflowRun) *model.CIHealth {
+	h := &model.CIHealth{CollectedAt: time.Now()}
+	var all []model.WorkflowRun
+	lastRunsPassed := true
+
+	for _, wf := range workflows {
+		runs := history[wf.Path]
+		h.Workflows = append(h.Workflows, model.WorkflowHealth{
+			Workflow: wf.Name,
+			Path:     wf.Path,
+			RunStats: stats(runs),
+		})
+		all = append(all, runs...)
+		if wh := h.Workflows[len(h.Workflows)-1]; wh.Passed+wh.Failed > 0 {
+			lastRunsPassed = lastRunsPassed && wh.LastRunPassed
+		}
+	}
+
+	h.RunStats = stats(all)
+	h.FlakyRuns = 0
+	for _, wh := range h.Workflows {
+		h.FlakyRuns += wh.FlakyRuns
+	}
+	// Across workflows, the last run passed only if every workflow's did.
+	h.LastRunPassed = h.Passed+h.Failed > 0 && lastRunsPassed
+
+	return h
+}
+
+// stats summarizes the completed runs of one workflow. Cancelled and
+// skipped runs count toward Runs but do not change LastRunPassed.
+func stats(runs []model.WorkflowRun) model.RunStats {
+	sorted := slices.Clone(runs)
+	slices.SortStableFunc(sorted, func(a, b model.WorkflowRun) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	var s model.RunStats
+	var durations []time.Duration
+	failedSHAs := make(map[string]bool)
+
+	for _, run := range sorted {
+		if run.Status != "" && run.Status != "completed" {
+			continue
+		}
+		s.Runs++
+		s.LastRunAt = run.CreatedAt
+
+		if d := run.Duration(); d > 0 {
+			durations = append(durations, d)
+		}
+
+		switch {
+		case passed(run):
+			s.Passed++
+			s.LastRunPassed = true
+			s.LastSuccessAt = run.UpdatedAt
+			if run.RunAttempt > 1 || failedSHAs[run.HeadSHA] {
+				s.FlakyRuns++
+			}
+			delete(failedSHAs, run.HeadSHA)
+		case failed(run):
+			s.Failed++
+			s.LastRunPassed = false
+			failedSHAs[run.HeadSHA] = true
+		}
+	}
+
+	if s.Passed+s.Failed > 0 {
+		s.PassRate = float64(s.Passed) * 100 / float64(s.Passed+s.Failed)
+	}
+	if len(durations) > 0 {
+		s.MedianDurationMs = median(durations).Milliseconds()
+	}
+
+	return s
+}
+
+func passed(run model.WorkflowRun) bool {
+	return run.Conclusion == "success"
+}
+
+func failed(run model.WorkflowRun) bool {
+	switch run.Conclusion {
+	case "failure", "timed_out", "startup_failure":
+		return true
+	}
+	return false
+}
+
+func median(durations []time.Duration) time.Duration {
+	slices.Sort(durations)
+	mid := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[mid-1] + durations[mid]) / 2
+	}
+	return durations[mid]
+}
+
+// Summarize populates the run history fields of a policy CI context.
+// DaysSinceLastSuccess is -1 when no run has succeeded, and counts up to
+// now when the collection time is unknown.
+func Summarize(h *model.CIHealth, ci *model.CIContext) {
+	if h == nil || h.Runs == 0 {
+		return
+	}
+	ci.HasRunHistory = true
+	ci.LastRunPassed = h.LastRunPassed
+	ci.RunPassRate = int(h.PassRate)
+	ci.FlakyRunCount = h.FlakyRuns
+	ci.MedianRunSeconds = int(h.MedianDurationMs / 1000)
+	ci.DaysSinceLastSuccess = -1
+	if !h.LastSuccessAt.IsZero() {
+		collected := h.CollectedAt
+		if collected.IsZero() {
+			collected = time.Now()
+		}
+		ci.DaysSinceLastSuccess = int(collected.Sub(h.LastSuccessAt).Hours() / 24)
+	}
+}
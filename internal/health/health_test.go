@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+type fakeRuns map[int64][]model.WorkflowRun
+
+func (f fakeRuns) ListWorkflowRuns(_ context.Context, _ model.Repo, workflowID int64, branch string, limit int) ([]model.WorkflowRun, error) {
+	if branch != "develop" {
+		return nil, errors.New("unexpected branch " + branch)
+	}
+	runs := f[workflowID]
+	return runs[:min(limit, len(runs))], nil
+}
+
+var t0 = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func run(day int, sha, conclusion string, attempt int, minutes int) model.WorkflowRun {
+	created := t0.AddDate(0, 0, day)
+	return model.WorkflowRun{
+		Status:       "completed",
+		Conclusion:   conclusion,
+		HeadSHA:      sha,
+		RunAttempt:   attempt,
+		CreatedAt:    created,
+		RunStartedAt: created,
+		UpdatedAt:    created.Add(time.Duration(minutes) * time.Minute),
+	}
+}
+
+func TestCollect(t *testing.T) {
+	runs := fakeRuns{
+		// Newest first, as returned by the API.
+		1: {
+			run(5, "e", "success", 2, 10), // re-run after a failure
+			run(4, "d", "cancelled", 1, 1),
+			run(3, "c", "success", 1, 6), // same SHA rerun success
+			run(2, "c", "failure", 1, 4),
+			run(1, "b", "success", 1, 8),
+			run(0, "a", "failure", 1, 2),
+		},
+		2: {
+			run(4, "d", "failure", 1, 3),
+			run(1, "b", "success", 1, 3),
+		},
+	}
+	workflows := []model.Workflow{
+		{ID: 1, Name: "CI", Path: ".github/workflows/ci.yml", State: "active"},
+		{ID: 2, Name: "Lint", Path: ".github/workflows/lint.yml", State: "active"},
+		{ID: 3, Name: "Old", Path: ".github/workflows/old.yml", State: "disabled_manually"},
+		{Name: "Local", Path: ".github/workflows/local.yml"},
+	}
+
+	h, err := Collect(context.Background(), runs, model.Repo{DefaultBranch: "develop"}, workflows, 0)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(h.Workflows) != 2 || h.Branch != "develop" {
+		t.Fatalf("Collect() = %+v, want 2 active workflows on develop", h)
+	}
+
+	ci := h.Workflows[0]
+	if ci.Runs != 6 || ci.Passed != 3 || ci.Failed != 2 || int(ci.PassRate) != 60 {
+		t.Errorf("CI stats = %+v", ci.RunStats)
+	}
+	if !ci.LastRunPassed || ci.FlakyRuns != 2 {
+		t.Errorf("CI lastRunPassed=%v flaky=%d, want true 2", ci.LastRunPassed, ci.FlakyRuns)
+	}
+	if !ci.LastSuccessAt.Equal(t0.AddDate(0, 0, 5).Add(10 * time.Minute)) {
+		t.Errorf("CI lastSuccessAt = %v", ci.LastSuccessAt)
+	}
+	// Durations 1, 2, 4, 6, 8, 10 minutes.
+	if ci.MedianDurationMs != (5 * time.Minute).Milliseconds() {
+		t.Errorf("CI median = %dms, want 5m", ci.MedianDurationMs)
+	}
+
+	lint := h.Workflows[1]
+	if lint.LastRunPassed || lint.FlakyRuns != 0 {
+		t.Errorf("Lint lastRunPassed=%v flaky=%d, want false 0", lint.LastRunPassed, lint.FlakyRuns)
+	}
+
+	if h.Runs != 8 || h.Passed != 4 || h.Failed != 3 || h.FlakyRuns != 2 || h.LastRunPassed {
+		t.Errorf("repo stats = %+v", h.RunStats)
+	}
+
+	h.CollectedAt = t0.AddDate(0, 0, 8)
+	var ctx model.CIContext
+	Summarize(h, &ctx)
+	if !ctx.HasRunHistory || ctx.LastRunPassed || ctx.RunPassRate != 57 || ctx.FlakyRunCount != 2 || ctx.DaysSinceLastSuccess != 2 {
+		t.Errorf("Summarize() = %+v", ctx)
+	}
+}
+
+func TestCollectError(t *testing.T) {
+	workflows := []model.Workflow{{ID: 1, Path: ".github/workflows/ci.yml"}}
+	if _, err := Collect(context.Background(), fakeRuns{}, model.Repo{}, workflows, 5); err == nil {
+		t.Error("Collect() expected error for unexpected branch")
+	}
+}
+
+func TestAnalyzeSummarize(t *testing.T) {
+	created := time.Now().AddDate(0, 0, -3).Add(-time.Hour)
+	workflows := []model.Workflow{{Name: "CI", Path: ".github/workflows/ci.yml"}}
+	history := map[string][]model.WorkflowRun{
+		".github/workflows/ci.yml": {{Status: "completed", Conclusion: "success", CreatedAt: created, UpdatedAt: created.Add(10 * time.Minute)}},
+	}
+
+	h := Analyze(workflows, history)
+	if h.CollectedAt.IsZero() {
+		t.Error("Analyze() left CollectedAt unset")
+	}
+	var ctx model.CIContext
+	Summarize(h, &ctx)
+	if ctx.DaysSinceLastSuccess != 3 {
+		t.Errorf("DaysSinceLastSuccess = %d, want 3", ctx.DaysSinceLastSuccess)
+	}
+}
+
+func TestSummarizeNoHistory(t *testing.T) {
+	var ctx model.CIContext
+	Summarize(&model.CIHealth{}, &ctx)
+	if ctx.HasRunHistory || ctx.LastRunPassed {
+		t.Errorf("Summarize() = %+v, want unset", ctx)
+	}
+}
@@ -0,0 +1,165 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// RepoConfigFile is the per-repository configuration file.
+const RepoConfigFile = ".pipelineconductor.yaml"
+
+// ProfileTopicPrefix is the repository topic prefix that selects a profile,
+// e.g. "pc-profile-legacy".
+const ProfileTopicPrefix = "pc-profile-"
+
+// Profile assignment sources, in resolution order.
+const (
+	ProfileSourceRepoConfig = "repo-config"
+	ProfileSourceTopic      = "topic"
+	ProfileSourceMapping    = "mapping"
+	ProfileSourceDefault    = "default"
+)
+
+// FileGetter reads files from a repository. collector.Collector satisfies it.
+// Errors for missing files must match fs.ErrNotExist.
+type FileGetter interface {
+	GetFileContent(ctx context.Context, repo model.Repo, path string) (string, error)
+}
+
+// RepoConfig is the content of a repository's .pipelineconductor.yaml.
+type RepoConfig struct {
+	Profile string `yaml:"profile"`
+}
+
+// ProfileMapping assigns profiles to repositories by glob pattern.
+type ProfileMapping struct {
+	Default string        `yaml:"default"`
+	Rules   []ProfileRule `yaml:"rules"`
+}
+
+// ProfileRule maps repositories whose full name matches Pattern
+// (path.Match syntax, e.g. "myorg/legacy-*") to a profile.
+type ProfileRule struct {
+	Pattern string `yaml:"pattern"`
+	Profile string `yaml:"profile"`
+}
+
+// LoadProfileMapping loads a profile mapping file.
+func LoadProfileMapping(filePath string) (*ProfileMapping, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
+	}
+
+	var mapping ProfileMapping
+	if err := yaml.Unmarshal(content, &mapping); err != nil {
+		return nil, fmt.Errorf("parsing YAML %s: %w", filePath, err)
+	}
+
+	for _, rule := range mapping.Rules {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in %s: %w", rule.Pattern, filePath, err)
+		}
+	}
+
+	return &mapping, nil
+}
+
+// ProfileAssignment records which profile applies to a repository and why.
+type ProfileAssignment struct {
+	Profile *model.Profile
+	Source  string
+	// Detail describes the matching topic, rule pattern, or config file.
+	Detail string
+}
+
+// ProfileResolver selects a profile per repository. Sources are consulted
+// in order: the repository's .pipelineconductor.yaml, a pc-profile-<name>
+// topic, the mapping file rules, and finally the default profile.
+type ProfileResolver struct {
+	Manager *ProfileManager
+	Files   FileGetter
+	Mapping *ProfileMapping
+	// Default is the fallback profile name. If empty, the mapping default
+	// or "default" is used.
+	Default string
+}
+
+// NewProfileResolver creates a resolver. files and mapping may be nil.
+func NewProfileResolver(manager *ProfileManager, files FileGetter, mapping *ProfileMapping) *ProfileResolver {
+	return &ProfileResolver{
+		Manager: manager,
+		Files:   files,
+		Mapping: mapping,
+	}
+}
+
+// Resolve returns the profile assignment for a repository.
+func (r *ProfileResolver) Resolve(ctx context.Context, repo model.Repo) (*ProfileAssignment, error) {
+	if r.Files != nil {
+		content, err := r.Files.GetFileContent(ctx, repo, RepoConfigFile)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("reading %s in %s: %w", RepoConfigFile, repo.FullName, err)
+		default:
+			var cfg RepoConfig
+			if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+				return nil, fmt.Errorf("parsing %s in %s: %w", RepoConfigFile, repo.FullName, err)
+			}
+			if cfg.Profile != "" {
+				return r.assign(cfg.Profile, ProfileSourceRepoConfig, RepoConfigFile)
+			}
+		}
+	}
+
+	for _, topic := range repo.Topics {
+		if name, ok := strings.CutPrefix(topic, ProfileTopicPrefix); ok && name != "" {
+			return r.assign(name, ProfileSourceTopic, topic)
+		}
+	}
+
+	if r.Mapping != nil {
+		for _, rule := range r.Mapping.Rules {
+			if matched, _ := path.Match(rule.Pattern, repo.FullName); matched {
+				return r.assign(rule.Profile, ProfileSourceMapping, rule.Pattern)
+			}
+		}
+	}
+
+	name := r.Default
+	if name == "" && r.Mapping != nil {
+		name = r.Mapping.Default
+	}
+	if name == "" {
+		name = "default"
+	}
+	return r.assign(name, ProfileSourceDefault, name)
+}
+
+func (r *ProfileResolver) assign(name, source, detail string) (*ProfileAssignment, error) {
+	profile, err := r.Manager.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", source, detail, err)
+	}
+	return &ProfileAssignment{Profile: profile, Source: source, Detail: detail}, nil
+}
+
+// BuildContext resolves the repository's profile and builds its PolicyContext
+// against that profile.
+func (r *ProfileResolver) BuildContext(ctx context.Context, repo model.Repo, workflows []model.Workflow, bp *model.BranchProtection) (*model.PolicyContext, *ProfileAssignment, error) {
+	assignment, err := r.Resolve(ctx, repo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving profile for %s: %w", repo.FullName, err)
+	}
+	return NewContextBuilder(assignment.Profile).Build(repo, workflows, bp), assignment, nil
+}
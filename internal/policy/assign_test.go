@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// fakeFiles serves repository files keyed by "owner/name:path".
+type fakeFiles map[string]string
+
+func (f fakeFiles) GetFileContent(_ context.Context, repo model.Repo, path string) (string, error) {
+	if content, ok := f[repo.FullName+":"+path]; ok {
+		return content, nil
+	}
+	if content, ok := f[repo.FullName+":error"]; ok {
+		return "", errors.New(content)
+	}
+	return "", fs.ErrNotExist
+}
+
+func TestProfileResolverResolve(t *testing.T) {
+	pm := NewProfileManager()
+	pm.LoadBuiltinProfiles()
+
+	files := fakeFiles{
+		"myorg/configured:" + RepoConfigFile:   "profile: modern\n",
+		"myorg/empty-config:" + RepoConfigFile: "# no profile\n",
+	}
+	mapping := &ProfileMapping{
+		Default: "default",
+		Rules: []ProfileRule{
+			{Pattern: "myorg/legacy-*", Profile: "legacy"},
+			{Pattern: "otherorg/*", Profile: "modern"},
+		},
+	}
+	r := NewProfileResolver(pm, files, mapping)
+
+	tests := []struct {
+		name        string
+		repo        model.Repo
+		wantProfile string
+		wantSource  string
+		wantDetail  string
+	}{
+		{
+			name:        "repo config wins over topic",
+			repo:        model.Repo{FullName: "myorg/configured", Topics: []string{"pc-profile-legacy"}},
+			wantProfile: "modern",
+			wantSource:  ProfileSourceRepoConfig,
+			wantDetail:  RepoConfigFile,
+		},
+		{
+			name:        "topic wins over mapping",
+			repo:        model.Repo{FullName: "myorg/legacy-api", Topics: []string{"go", "pc-profile-modern"}},
+			wantProfile: "modern",
+			wantSource:  ProfileSourceTopic,
+			wantDetail:  "pc-profile-modern",
+		},
+		{
+			name:        "empty repo config falls through",
+			repo:        model.Repo{FullName: "myorg/empty-config", Topics: []string{"pc-profile-legacy"}},
+			wantProfile: "legacy",
+			wantSource:  ProfileSourceTopic,
+			wantDetail:  "pc-profile-legacy",
+		},
+		{
+			name:        "mapping glob",
+			repo:        model.Repo{FullName: "myorg/legacy-api"},
+			wantProfile: "legacy",
+			wantSource:  ProfileSourceMapping,
+			wantDetail:  "myorg/legacy-*",
+		},
+		{
+			name:        "default",
+			repo:        model.Repo{FullName: "myorg/service"},
+			wantProfile: "default",
+			wantSource:  ProfileSourceDefault,
+			wantDetail:  "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), tt.repo)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got.Profile.Name != tt.wantProfile {
+				t.Errorf("Profile = %s, want %s", got.Profile.Name, tt.wantProfile)
+			}
+			if got.Source != tt.wantSource {
+				t.Errorf("Source = %s, want %s", got.Source, tt.wantSource)
+			}
+			if got.Detail != tt.wantDetail {
+				t.Errorf("Detail = %s, want %s", got.Detail, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestProfileResolverUnknownProfile(t *testing.T) {
+	pm := NewProfileManager()
+	pm.LoadBuiltinProfiles()
+	r := NewProfileResolver(pm, nil, nil)
+
+	_, err := r.Resolve(context.Background(), model.Repo{
+		FullName: "myorg/api",
+		Topics:   []string{"pc-profile-missing"},
+	})
+	if err == nil {
+		t.Fatal("Resolve() expected error for unknown profile")
+	}
+}
+
+func TestProfileResolverErrors(t *testing.T) {
+	pm := NewProfileManager()
+	pm.LoadBuiltinProfiles()
+
+	// A failed read is not treated as a missing config file.
+	files := fakeFiles{"myorg/api:error": "rate limited"}
+	if _, err := NewProfileResolver(pm, files, nil).Resolve(context.Background(), model.Repo{FullName: "myorg/api"}); err == nil {
+		t.Error("Resolve() expected error for failed config read")
+	}
+
+	// A misspelled default is not replaced with the built-in one.
+	r := NewProfileResolver(pm, nil, nil)
+	r.Default = "modren"
+	if _, err := r.Resolve(context.Background(), model.Repo{FullName: "myorg/api"}); err == nil {
+		t.Error("Resolve() expected error for unknown default profile")
+	}
+}
+
+func TestProfileResolverBuildContext(t *testing.T) {
+	pm := NewProfileManager()
+	pm.LoadBuiltinProfiles()
+	r := NewProfileResolver(pm, nil, nil)
+
+	repo := model.Repo{
+		FullName:  "myorg/api",
+		Languages: []string{"Go"},
+		Topics:    []string{"pc-profile-legacy"},
+	}
+	ctx, assignment, err := r.BuildContext(context.Background(), repo, nil, nil)
+	if err != nil {
+		t.Fatalf("BuildContext() error = %v", err)
+	}
+	if assignment.Profile.Name != "legacy" {
+		t.Errorf("assignment profile = %s, want legacy", assignment.Profile.Name)
+	}
+	if ctx.Go.Profile != "legacy" {
+		t.Errorf("Go.Profile = %s, want legacy", ctx.Go.Profile)
+	}
+}
+
+func TestLoadProfileMapping(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mapping.yaml")
+	content := `
+default: modern
+rules:
+  - pattern: "myorg/legacy-*"
+    profile: legacy
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	mapping, err := LoadProfileMapping(path)
+	if err != nil {
+		t.Fatalf("LoadProfileMapping() error = %v", err)
+	}
+	if mapping.Default != "modern" || len(mapping.Rules) != 1 || mapping.Rules[0].Profile != "legacy" {
+		t.Errorf("LoadProfileMapping() = %+v", mapping)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("rules:\n  - pattern: \"[\"\n    profile: legacy\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfileMapping(bad); err == nil {
+		t.Error("LoadProfileMapping() expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,202 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// coverageUploaders match actions and commands that publish coverage, e.g.
+// codecov/codecov-action, coverallsapp/github-action, or goveralls.
+var coverageUploaders = []string{"codecov", "coveralls"}
+
+// CheckProfileConformance compares a profile's OS, lint, race, and coverage
+// settings with what the repository's workflows actually do, and returns a
+// violation with a remediation hint for each gap.
+//
+// Jobs that call reusable workflows cannot be inspected. A check that
+// finds no evidence is skipped rather than reported only when a called
+// workflow is known to perform it: CI and test workflows, such as
+// go-ci.yaml, cover the test, coverage and OS checks, and lint workflows
+// cover the lint check.
+func CheckProfileConformance(profile *model.Profile, workflows []model.Workflow) []model.Violation {
+	if profile == nil || len(workflows) == 0 {
+		return nil
+	}
+
+	var violations []model.Violation
+	var testSteps []stepRef
+	var lintFound, coverageUploaded, delegatedLint, delegatedTest bool
+	osSet := make(map[string]bool)
+
+	lintTool := profile.Lint.Tool
+	if lintTool == "" {
+		lintTool = "golangci-lint"
+	}
+
+	for _, wf := range workflows {
+		for _, job := range wf.Jobs {
+			if job.UsesReusableWorkflow {
+				lint, test := delegatedChecks(job)
+				delegatedLint = delegatedLint || lint
+				delegatedTest = delegatedTest || test
+			}
+			for _, os := range job.RunsOn {
+				if !strings.Contains(os, "${{") {
+					osSet[os] = true
+				}
+			}
+			if job.Matrix != nil {
+				for _, os := range job.Matrix.OS {
+					osSet[os] = true
+				}
+				for _, inc := range job.Matrix.Include {
+					if os, ok := inc["os"]; ok {
+						osSet[os] = true
+					}
+				}
+			}
+
+			for _, step := range job.Steps {
+				if strings.Contains(step.Run, "go test") {
+					testSteps = append(testSteps, stepRef{workflow: wf.Path, run: step.Run})
+				}
+				if strings.Contains(step.Uses, lintTool) || strings.Contains(step.Run, lintTool) {
+					lintFound = true
+				}
+				if usesAny(step.Uses, coverageUploaders) || usesAny(step.Run, coverageUploaders) {
+					coverageUploaded = true
+				}
+			}
+		}
+	}
+
+	if profile.Test.Race {
+		violations = append(violations, checkRace(profile, testSteps, delegatedTest)...)
+	}
+
+	if profile.Test.Coverage && !coverageUploaded && !delegatedTest {
+		v := model.Violation{
+			Policy:      "profile/test",
+			Rule:        "coverage-upload",
+			Message:     fmt.Sprintf("Profile %s requires coverage but no workflow uploads it", profile.Name),
+			Severity:    model.SeverityLow,
+			Remediation: "Run go test with -coverprofile=coverage.out and add a codecov/codecov-action step",
+		}
+		if len(testSteps) > 0 {
+			v.File = testSteps[0].workflow
+		}
+		violations = append(violations, v)
+	}
+
+	if profile.Lint.Enabled && !lintFound && !delegatedLint {
+		violations = append(violations, model.Violation{
+			Policy:      "profile/lint",
+			Rule:        "lint-tool",
+			Message:     fmt.Sprintf("Profile %s requires %s but no workflow runs it", profile.Name, lintTool),
+			Severity:    model.SeverityMedium,
+			Remediation: lintRemediation(lintTool),
+		})
+	}
+
+	for _, os := range profile.OS {
+		// Inline jobs, such as lint, say nothing about the OSes a called
+		// test workflow runs on.
+		if osSet[os] || delegatedTest {
+			continue
+		}
+		violations = append(violations, model.Violation{
+			Policy:      "profile/os-matrix",
+			Rule:        "os-coverage",
+			Message:     fmt.Sprintf("Profile %s requires OS %s but no job runs on it", profile.Name, os),
+			Severity:    model.SeverityLow,
+			Remediation: fmt.Sprintf("Add %s to the job's runs-on or matrix.os", os),
+		})
+	}
+
+	return violations
+}
+
+type stepRef struct {
+	workflow string
+	run      string
+}
+
+// checkRace reports go test invocations that do not enable the race detector.
+func checkRace(profile *model.Profile, testSteps []stepRef, delegated bool) []model.Violation {
+	if len(testSteps) == 0 {
+		if delegated {
+			return nil
+		}
+		return []model.Violation{{
+			Policy:      "profile/test",
+			Rule:        "race-detector",
+			Message:     fmt.Sprintf("Profile %s requires race detection but no workflow runs go test", profile.Name),
+			Severity:    model.SeverityMedium,
+			Remediation: "Add a step that runs go test -race ./...",
+		}}
+	}
+
+	var violations []model.Violation
+	for _, step := range testSteps {
+		if hasRaceFlag(step.run) {
+			continue
+		}
+		violations = append(violations, model.Violation{
+			Policy:      "profile/test",
+			Rule:        "race-detector",
+			Message:     fmt.Sprintf("Profile %s requires race detection but go test runs without -race", profile.Name),
+			Severity:    model.SeverityMedium,
+			Remediation: "Add -race to the go test command",
+			File:        step.workflow,
+		})
+	}
+	return violations
+}
+
+// delegatedChecks reports whether the reusable workflow a job calls is
+// known to lint or to run the tests, going by its action name: the part of
+// the file name after the language prefix, as in go-lint.yaml or go-ci.yaml.
+func delegatedChecks(job model.WorkflowJob) (lint, test bool) {
+	if job.ReusableWorkflowRef == nil {
+		return false, false
+	}
+	name := path.Base(job.ReusableWorkflowRef.Path)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	action := name[strings.LastIndex(name, "-")+1:]
+	return action == "lint", action == "ci" || action == "test"
+}
+
+// hasRaceFlag reports whether every go test command in a run script uses
+// -race. Lines continued with a trailing backslash are joined first.
+func hasRaceFlag(run string) bool {
+	run = strings.ReplaceAll(run, "\\\n", " ")
+	for line := range strings.SplitSeq(run, "\n") {
+		if !strings.Contains(line, "go test") {
+			continue
+		}
+		if !slices.Contains(strings.Fields(line), "-race") {
+			return false
+		}
+	}
+	return true
+}
+
+func usesAny(s string, needles []string) bool {
+	for _, n := range needles {
+		if strings.Contains(s, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func lintRemediation(tool string) string {
+	if strings.HasPrefix(tool, "golangci-lint") {
+		return "Add a golangci/golangci-lint-action step"
+	}
+	return fmt.Sprintf("Add a step that runs %s", tool)
+}
@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+func TestCheckProfileConformance(t *testing.T) {
+	profile := DefaultProfile()
+
+	conforming := model.Workflow{
+		Path: ".github/workflows/ci.yaml",
+		Jobs: []model.WorkflowJob{
+			{
+				ID:     "test",
+				RunsOn: []string{"${{ matrix.os }}"},
+				Matrix: &model.MatrixConfig{OS: []string{"ubuntu-latest", "macos-latest", "windows-latest"}},
+				Steps: []model.WorkflowStep{
+					{Run: "go test -race -coverprofile=coverage.out ./..."},
+					{Uses: "codecov/codecov-action@v5"},
+				},
+			},
+			{
+				ID:     "lint",
+				RunsOn: []string{"ubuntu-latest"},
+				Steps:  []model.WorkflowStep{{Uses: "golangci/golangci-lint-action@v8"}},
+			},
+		},
+	}
+
+	gaps := model.Workflow{
+		Path: ".github/workflows/ci.yaml",
+		Jobs: []model.WorkflowJob{
+			{
+				ID:     "test",
+				RunsOn: []string{"ubuntu-latest"},
+				Steps:  []model.WorkflowStep{{Run: "go build ./...\ngo test ./..."}},
+			},
+		},
+	}
+
+	delegated := model.Workflow{
+		Path: ".github/workflows/ci.yaml",
+		Jobs: []model.WorkflowJob{
+			{ID: "ci", UsesReusableWorkflow: true, ReusableWorkflowRef: &model.ReusableWorkflowRef{Path: ".github/workflows/go-ci.yaml"}},
+			{ID: "lint", UsesReusableWorkflow: true, ReusableWorkflowRef: &model.ReusableWorkflowRef{Path: ".github/workflows/go-lint.yaml"}},
+		},
+	}
+
+	ciOnly := model.Workflow{
+		Path: ".github/workflows/ci.yaml",
+		Jobs: []model.WorkflowJob{delegated.Jobs[0]},
+	}
+
+	ciInlineLint := model.Workflow{
+		Path: ".github/workflows/ci.yaml",
+		Jobs: []model.WorkflowJob{delegated.Jobs[0], conforming.Jobs[1]},
+	}
+
+	release := model.Workflow{
+		Path: ".github/workflows/release.yaml",
+		Jobs: []model.WorkflowJob{
+			{ID: "release", UsesReusableWorkflow: true, ReusableWorkflowRef: &model.ReusableWorkflowRef{Path: ".github/workflows/go-release.yaml"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		workflows []model.Workflow
+		wantRules []string
+	}{
+		{
+			name:      "conforming",
+			workflows: []model.Workflow{conforming},
+		},
+		{
+			name:      "gaps",
+			workflows: []model.Workflow{gaps},
+			wantRules: []string{"race-detector", "coverage-upload", "lint-tool", "os-coverage", "os-coverage"},
+		},
+		{
+			name:      "reusable workflow delegates step checks",
+			workflows: []model.Workflow{delegated},
+		},
+		{
+			name:      "called CI workflow does not cover lint",
+			workflows: []model.Workflow{ciOnly},
+			wantRules: []string{"lint-tool"},
+		},
+		{
+			name:      "called CI workflow covers OS matrix beside inline lint job",
+			workflows: []model.Workflow{ciInlineLint},
+		},
+		{
+			name:      "called release workflow covers nothing",
+			workflows: []model.Workflow{release},
+			wantRules: []string{"race-detector", "coverage-upload", "lint-tool", "os-coverage", "os-coverage", "os-coverage"},
+		},
+		{
+			name:      "no workflows",
+			workflows: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckProfileConformance(profile, tt.workflows)
+			if len(got) != len(tt.wantRules) {
+				t.Fatalf("CheckProfileConformance() = %d violations, want %d: %+v", len(got), len(tt.wantRules), got)
+			}
+			for i, v := range got {
+				if v.Rule != tt.wantRules[i] {
+					t.Errorf("violation[%d].Rule = %s, want %s", i, v.Rule, tt.wantRules[i])
+				}
+				if v.Remediation == "" {
+					t.Errorf("violation[%d] has no remediation", i)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckProfileConformanceRaceFile(t *testing.T) {
+	profile := ModernProfile()
+	workflows := []model.Workflow{{
+		Path: ".github/workflows/test.yaml",
+		Jobs: []model.WorkflowJob{{
+			ID:     "test",
+			RunsOn: []string{"ubuntu-latest", "macos-latest"},
+			Steps: []model.WorkflowStep{
+				{Run: "go test -race ./...\ngo test -tags integration ./..."},
+				{Uses: "codecov/codecov-action@v5"},
+				{Run: "golangci-lint run"},
+			},
+		}},
+	}}
+
+	got := CheckProfileConformance(profile, workflows)
+	if len(got) != 1 {
+		t.Fatalf("CheckProfileConformance() = %+v, want one race violation", got)
+	}
+	if got[0].Rule != "race-detector" || got[0].File != ".github/workflows/test.yaml" {
+		t.Errorf("violation = %+v", got[0])
+	}
+}
+
+func TestHasRaceFlag(t *testing.T) {
+	tests := []struct {
+		run  string
+		want bool
+	}{
+		{"go test -race ./...", true},
+		{"go build ./...\ngo test ./...", false},
+		{"go test ./... \\\n  -race -count=1", true},
+		{"go test -race ./...\ngo test \\\n  ./integration", false},
+	}
+	for _, tt := range tests {
+		if got := hasRaceFlag(tt.run); got != tt.want {
+			t.Errorf("hasRaceFlag(%q) = %v, want %v", tt.run, got, tt.want)
+		}
+	}
+}
@@ -37,13 +37,25 @@ func (b *ContextBuilder) Build(repo model.Repo, workflows []model.Workflow, bp *
 	// Go context (if applicable)
 	ctx.Go = b.buildGoContext(repo, workflows)
 
+	// Status check reconciliation
+	b.reconcileChecks(&ctx.CI, workflows, bp)
+
 	// Branch protection context
 	if bp != nil {
 		ctx.BranchProtection = model.BranchProtectionContext{
-			Enabled:             bp.Enabled,
-			RequireReviews:      bp.RequireReviews,
-			RequireStatusChecks: bp.RequireStatusChecks,
-			EnforceAdmins:       bp.EnforceAdmins,
+			Enabled:                       bp.Enabled,
+			RequireReviews:                bp.RequireReviews,
+			RequiredReviewers:             bp.RequiredReviewers,
+			RequireStatusChecks:           bp.RequireStatusChecks,
+			RequiredStatusChecks:          bp.RequiredStatusChecks,
+			EnforceAdmins:                 bp.EnforceAdmins,
+			RequireSignedCommits:          bp.RequireSignedCommits,
+			RequireLinearHistory:          bp.RequireLinearHistory,
+			AllowForcePushes:              bp.AllowForcePushes,
+			AllowDeletions:                bp.AllowDeletions,
+			RequireCodeOwnerReviews:       bp.RequireCodeOwnerReviews,
+			DismissStaleReviews:           bp.DismissStaleReviews,
+			RequireConversationResolution: bp.RequireConversationResolution,
 		}
 	}
 
@@ -92,6 +104,23 @@ func (b *ContextBuilder) buildCIContext(workflows []model.Workflow) model.CICont
 	return ctx
 }
 
+// reconcileChecks fills the CI context's required, produced, and
+// mismatched status checks.
+func (b *ContextBuilder) reconcileChecks(ci *model.CIContext, workflows []model.Workflow, bp *model.BranchProtection) {
+	important := DefaultProfile().Checks.Required
+	if b.profile != nil && len(b.profile.Checks.Required) > 0 {
+		important = b.profile.Checks.Required
+	}
+
+	r := ReconcileStatusChecks(workflows, bp, important)
+	ci.RequiredChecks = r.Required
+	ci.ProducedChecks = r.ProducedNames()
+	ci.MissingRequiredChecks = r.Missing
+	for _, job := range r.Unrequired {
+		ci.UnrequiredJobs = append(ci.UnrequiredJobs, job.Job)
+	}
+}
+
 func (b *ContextBuilder) buildGoContext(repo model.Repo, workflows []model.Workflow) model.GoContext {
 	ctx := model.GoContext{}
 
@@ -185,6 +214,8 @@ func (b *ContextBuilder) buildComplianceContext(result model.RepoCheckResult, re
 			}
 		case model.MatchTypeEquivalent:
 			compliance.EquivalentMatchCount++
+		case model.MatchTypeInactive:
+			compliance.InactiveWorkflows = append(compliance.InactiveWorkflows, wf.WorkflowType)
 		}
 		if wf.FilenameMismatch {
 			compliance.HasFilenameMismatch = true
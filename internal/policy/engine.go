@@ -3,6 +3,7 @@ package policy
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/cedar-policy/cedar-go"
 	"github.com/plexusone/pipelineconductor/pkg/model"
@@ -18,20 +19,84 @@ const (
 	ActionRelease = "release"
 )
 
+// DefaultActions returns the actions evaluated when none are configured.
+func DefaultActions() []string {
+	return []string{ActionBuild, ActionTest, ActionLint, ActionMerge}
+}
+
+// DefaultPrincipal returns the principal used when none are configured.
+func DefaultPrincipal() model.Principal {
+	return model.Principal{Type: model.PrincipalTypeCISystem, ID: "pipelineconductor"}
+}
+
 // Engine evaluates Cedar policies against repository contexts.
 type Engine struct {
-	policySet *cedar.PolicySet
-	entities  cedar.EntityMap
+	policySet  *cedar.PolicySet
+	entities   cedar.EntityMap
+	actions    []string
+	principals []model.Principal
 }
 
 // NewEngine creates a new policy evaluation engine.
 func NewEngine() *Engine {
 	return &Engine{
-		policySet: cedar.NewPolicySet(),
-		entities:  make(cedar.EntityMap),
+		policySet:  cedar.NewPolicySet(),
+		entities:   make(cedar.EntityMap),
+		actions:    DefaultActions(),
+		principals: []model.Principal{DefaultPrincipal()},
+	}
+}
+
+// SetActions sets the actions evaluated by EvaluateAll.
+// An empty list restores the default actions.
+func (e *Engine) SetActions(actions []string) {
+	if len(actions) == 0 {
+		actions = DefaultActions()
+	}
+	e.actions = slices.Clone(actions)
+}
+
+// SetPrincipals sets the principals evaluated by EvaluateAll and registers
+// their team memberships as Cedar entities, replacing those of previous
+// calls. An empty list restores the default CI system principal.
+func (e *Engine) SetPrincipals(principals []model.Principal) {
+	if len(principals) == 0 {
+		principals = []model.Principal{DefaultPrincipal()}
+	}
+	e.principals = slices.Clone(principals)
+	e.entities = make(cedar.EntityMap)
+
+	for _, p := range principals {
+		uid := principalUID(p)
+		var parents []cedar.EntityUID
+		for _, team := range p.Teams {
+			teamUID := cedar.NewEntityUID(cedar.EntityType(model.PrincipalTypeTeam), cedar.String(team))
+			parents = append(parents, teamUID)
+			if _, ok := e.entities[teamUID]; !ok {
+				e.entities[teamUID] = cedar.Entity{UID: teamUID}
+			}
+		}
+		e.entities[uid] = cedar.Entity{UID: uid, Parents: cedar.NewEntityUIDSet(parents...)}
 	}
 }
 
+// Configure applies the evaluation settings from a profile.
+func (e *Engine) Configure(cfg model.ProfileEvaluation) *Engine {
+	e.SetActions(cfg.Actions)
+	e.SetPrincipals(cfg.Principals)
+	return e
+}
+
+// Actions returns the actions evaluated by EvaluateAll.
+func (e *Engine) Actions() []string {
+	return slices.Clone(e.actions)
+}
+
+// Principals returns the principals evaluated by EvaluateAll.
+func (e *Engine) Principals() []model.Principal {
+	return slices.Clone(e.principals)
+}
+
 // AddPolicy adds a policy to the engine.
 func (e *Engine) AddPolicy(id string, policyText []byte) error {
 	var policy cedar.Policy
@@ -47,10 +112,17 @@ func (e *Engine) AddPolicyFromFile(id string, content []byte) error {
 	return e.AddPolicy(id, content)
 }
 
-// Evaluate evaluates policies for a repository and action.
+// Evaluate evaluates policies for a repository and action using the
+// first configured principal.
 func (e *Engine) Evaluate(ctx *model.PolicyContext, action string) *EvaluationResult {
+	return e.EvaluateAs(ctx, e.principals[0], action)
+}
+
+// EvaluateAs evaluates policies for a repository and action on behalf of
+// a specific principal.
+func (e *Engine) EvaluateAs(ctx *model.PolicyContext, principal model.Principal, action string) *EvaluationResult {
 	// Build Cedar request
-	req := e.buildRequest(ctx, action)
+	req := e.buildRequest(ctx, principal, action)
 
 	// Evaluate against policy set
 	decision, diagnostic := cedar.Authorize(e.policySet, e.entities, req)
@@ -58,6 +130,7 @@ func (e *Engine) Evaluate(ctx *model.PolicyContext, action string) *EvaluationRe
 	result := &EvaluationResult{
 		Allowed:    decision == cedar.Allow,
 		Action:     action,
+		Principal:  principal.String(),
 		RepoName:   ctx.Repo.FullName,
 		Diagnostic: diagnostic,
 	}
@@ -74,20 +147,22 @@ func (e *Engine) Evaluate(ctx *model.PolicyContext, action string) *EvaluationRe
 	return result
 }
 
-// EvaluateAll evaluates all standard CI/CD actions for a repository.
+// EvaluateAll evaluates every configured action for every configured
+// principal. Results are ordered by principal, then action.
 func (e *Engine) EvaluateAll(ctx *model.PolicyContext) []*EvaluationResult {
-	actions := []string{ActionBuild, ActionTest, ActionLint, ActionMerge}
 	var results []*EvaluationResult
-	for _, action := range actions {
-		results = append(results, e.Evaluate(ctx, action))
+	for _, principal := range e.principals {
+		for _, action := range e.actions {
+			results = append(results, e.EvaluateAs(ctx, principal, action))
+		}
 	}
 	return results
 }
 
 // buildRequest constructs a Cedar request from a policy context.
-func (e *Engine) buildRequest(ctx *model.PolicyContext, action string) cedar.Request {
+func (e *Engine) buildRequest(ctx *model.PolicyContext, p model.Principal, action string) cedar.Request {
 	// Principal: the CI system or actor
-	principal := cedar.NewEntityUID(cedar.EntityType("CISystem"), cedar.String("pipelineconductor"))
+	principal := principalUID(p)
 
 	// Action: the CI/CD action being evaluated
 	actionUID := cedar.NewEntityUID(cedar.EntityType("Action"), cedar.String(action))
@@ -95,8 +170,17 @@ func (e *Engine) buildRequest(ctx *model.PolicyContext, action string) cedar.Req
 	// Resource: the repository
 	resource := cedar.NewEntityUID(cedar.EntityType("Repository"), cedar.String(ctx.Repo.FullName))
 
-	// Context: all the policy-relevant data
-	context := cedar.NewRecord(cedar.RecordMap{
+	return cedar.Request{
+		Principal: principal,
+		Action:    actionUID,
+		Resource:  resource,
+		Context:   buildContextRecord(ctx),
+	}
+}
+
+// buildContextRecord converts a policy context into the Cedar context record.
+func buildContextRecord(ctx *model.PolicyContext) cedar.Record {
+	return cedar.NewRecord(cedar.RecordMap{
 		// Repository info
 		"repoName":     cedar.String(ctx.Repo.Name),
 		"repoOrg":      cedar.String(ctx.Repo.Org),
@@ -107,12 +191,20 @@ func (e *Engine) buildRequest(ctx *model.PolicyContext, action string) cedar.Req
 		"topics":       stringSliceToSet(ctx.Repo.Topics),
 
 		// CI configuration
-		"hasWorkflow":          cedar.Boolean(ctx.CI.HasWorkflow),
-		"usesReusableWorkflow": cedar.Boolean(ctx.CI.UsesReusableWorkflow),
-		"reusableWorkflowRef":  cedar.String(ctx.CI.ReusableWorkflowRef),
-		"lastRunPassed":        cedar.Boolean(ctx.CI.LastRunPassed),
-		"requiredChecks":       stringSliceToSet(ctx.CI.RequiredChecks),
-		"osMatrix":             stringSliceToSet(ctx.CI.OSMatrix),
+		"hasWorkflow":           cedar.Boolean(ctx.CI.HasWorkflow),
+		"usesReusableWorkflow":  cedar.Boolean(ctx.CI.UsesReusableWorkflow),
+		"reusableWorkflowRef":   cedar.String(ctx.CI.ReusableWorkflowRef),
+		"lastRunPassed":         cedar.Boolean(ctx.CI.LastRunPassed),
+		"requiredChecks":        stringSliceToSet(ctx.CI.RequiredChecks),
+		"osMatrix":              stringSliceToSet(ctx.CI.OSMatrix),
+		"producedChecks":        stringSliceToSet(ctx.CI.ProducedChecks),
+		"missingRequiredChecks": stringSliceToSet(ctx.CI.MissingRequiredChecks),
+		"unrequiredJobs":        stringSliceToSet(ctx.CI.UnrequiredJobs),
+		"hasRunHistory":         cedar.Boolean(ctx.CI.HasRunHistory),
+		"runPassRate":           cedar.Long(int64(ctx.CI.RunPassRate)),
+		"flakyRunCount":         cedar.Long(int64(ctx.CI.FlakyRunCount)),
+		"medianRunSeconds":      cedar.Long(int64(ctx.CI.MedianRunSeconds)),
+		"daysSinceLastSuccess":  cedar.Long(int64(ctx.CI.DaysSinceLastSuccess)),
 
 		// Go-specific
 		"goVersions": stringSliceToSet(ctx.Go.Versions),
@@ -120,17 +212,33 @@ func (e *Engine) buildRequest(ctx *model.PolicyContext, action string) cedar.Req
 		"hasGoMod":   cedar.Boolean(ctx.Go.HasGoMod),
 
 		// Dependencies
-		"hasRenovate":          cedar.Boolean(ctx.Dependencies.HasRenovate),
-		"hasDependabot":        cedar.Boolean(ctx.Dependencies.HasDependabot),
-		"oldestDependencyDays": cedar.Long(int64(ctx.Dependencies.OldestDependencyDays)),
-		"hasVulnerabilities":   cedar.Boolean(ctx.Dependencies.HasVulnerabilities),
-		"vulnerabilityCount":   cedar.Long(int64(ctx.Dependencies.VulnerabilityCount)),
+		"hasRenovate":             cedar.Boolean(ctx.Dependencies.HasRenovate),
+		"hasDependabot":           cedar.Boolean(ctx.Dependencies.HasDependabot),
+		"oldestDependencyDays":    cedar.Long(int64(ctx.Dependencies.OldestDependencyDays)),
+		"dependencyCount":         cedar.Long(int64(ctx.Dependencies.DependencyCount)),
+		"outdatedDependencyCount": cedar.Long(int64(ctx.Dependencies.OutdatedCount)),
+		"hasVulnerabilities":      cedar.Boolean(ctx.Dependencies.HasVulnerabilities),
+		"vulnerabilityCount":      cedar.Long(int64(ctx.Dependencies.VulnerabilityCount)),
+		"updateEcosystems":        stringSliceToSet(ctx.Dependencies.UpdateEcosystems),
+		"uncoveredEcosystems":     stringSliceToSet(ctx.Dependencies.UncoveredEcosystems),
+		"updateAutomerge":         cedar.Boolean(ctx.Dependencies.UpdateAutomerge),
+		"updateSchedule":          cedar.Boolean(ctx.Dependencies.UpdateSchedule),
+		"extendsOrgPreset":        cedar.Boolean(ctx.Dependencies.ExtendsOrgPreset),
 
 		// Branch protection
 		"branchProtectionEnabled":       cedar.Boolean(ctx.BranchProtection.Enabled),
 		"requireReviews":                cedar.Boolean(ctx.BranchProtection.RequireReviews),
 		"requireStatusChecks":           cedar.Boolean(ctx.BranchProtection.RequireStatusChecks),
 		"branchProtectionEnforceAdmins": cedar.Boolean(ctx.BranchProtection.EnforceAdmins),
+		"requiredReviewers":             cedar.Long(int64(ctx.BranchProtection.RequiredReviewers)),
+		"requiredStatusChecks":          stringSliceToSet(ctx.BranchProtection.RequiredStatusChecks),
+		"requireSignedCommits":          cedar.Boolean(ctx.BranchProtection.RequireSignedCommits),
+		"requireLinearHistory":          cedar.Boolean(ctx.BranchProtection.RequireLinearHistory),
+		"allowForcePushes":              cedar.Boolean(ctx.BranchProtection.AllowForcePushes),
+		"allowDeletions":                cedar.Boolean(ctx.BranchProtection.AllowDeletions),
+		"requireCodeOwnerReviews":       cedar.Boolean(ctx.BranchProtection.RequireCodeOwnerReviews),
+		"dismissStaleReviews":           cedar.Boolean(ctx.BranchProtection.DismissStaleReviews),
+		"requireConversationResolution": cedar.Boolean(ctx.BranchProtection.RequireConversationResolution),
 
 		// Compliance
 		"complianceLevel":       cedar.String(ctx.Compliance.Level),
@@ -142,15 +250,26 @@ func (e *Engine) buildRequest(ctx *model.PolicyContext, action string) cedar.Req
 		"usesReusableWorkflows": cedar.Boolean(ctx.Compliance.UsesReusableWorkflows),
 		"exactMatchCount":       cedar.Long(int64(ctx.Compliance.ExactMatchCount)),
 		"equivalentMatchCount":  cedar.Long(int64(ctx.Compliance.EquivalentMatchCount)),
+		"inactiveWorkflowCount": cedar.Long(int64(len(ctx.Compliance.InactiveWorkflows))),
+		"inactiveWorkflows":     stringSliceToSet(ctx.Compliance.InactiveWorkflows),
 		"complianceRefRepo":     cedar.String(ctx.Compliance.RefRepo),
+
+		// Repository files
+		"hasCodeowners":       cedar.Boolean(ctx.Files.HasCodeowners),
+		"codeownersValid":     cedar.Boolean(ctx.Files.CodeownersValid),
+		"hasLicense":          cedar.Boolean(ctx.Files.HasLicense),
+		"licenseSpdx":         cedar.String(ctx.Files.LicenseSPDX),
+		"hasSecurityPolicy":   cedar.Boolean(ctx.Files.HasSecurityPolicy),
+		"hasGolangciConfig":   cedar.Boolean(ctx.Files.HasGolangciConfig),
+		"golangciExtendsOrg":  cedar.Boolean(ctx.Files.GolangciExtendsOrg),
+		"missingReadmeBadges": stringSliceToSet(ctx.Files.MissingReadmeBadges),
+		"hasEditorconfig":     cedar.Boolean(ctx.Files.HasEditorconfig),
 	})
+}
 
-	return cedar.Request{
-		Principal: principal,
-		Action:    actionUID,
-		Resource:  resource,
-		Context:   context,
-	}
+// principalUID converts a principal into a Cedar entity UID.
+func principalUID(p model.Principal) cedar.EntityUID {
+	return cedar.NewEntityUID(cedar.EntityType(p.Type), cedar.String(p.ID))
 }
 
 // stringSliceToSet converts a string slice to a Cedar Set.
@@ -166,6 +285,7 @@ func stringSliceToSet(slice []string) cedar.Set {
 type EvaluationResult struct {
 	Allowed    bool
 	Action     string
+	Principal  string
 	RepoName   string
 	Reasons    []string
 	Errors     []string
@@ -184,8 +304,11 @@ func (r *EvaluationResult) ToViolation() *model.Violation {
 	}
 
 	message := fmt.Sprintf("Policy denied %s action", r.Action)
+	if r.Principal != "" && r.Principal != DefaultPrincipal().String() {
+		message = fmt.Sprintf("Policy denied %s action for %s", r.Action, r.Principal)
+	}
 	if len(r.Reasons) > 0 {
-		message = fmt.Sprintf("Policy denied %s action (policies: %v)", r.Action, r.Reasons)
+		message = fmt.Sprintf("%s (policies: %v)", message, r.Reasons)
 	}
 
 	return &model.Violation{
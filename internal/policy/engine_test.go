@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/plexusone/pipelineconductor/pkg/model"
@@ -143,3 +144,148 @@ func TestEngineAddPolicy(t *testing.T) {
 		t.Error("AddPolicy() with invalid policy should return error")
 	}
 }
+
+func TestEngineConfigureActions(t *testing.T) {
+	engine := NewEngine().Configure(model.ProfileEvaluation{
+		Actions: []string{ActionDeploy, ActionRelease},
+	})
+
+	results := engine.EvaluateAll(&model.PolicyContext{Repo: model.RepoContext{FullName: "org/repo"}})
+	if len(results) != 2 {
+		t.Fatalf("EvaluateAll() returned %d results, want 2", len(results))
+	}
+	if results[0].Action != ActionDeploy || results[1].Action != ActionRelease {
+		t.Errorf("EvaluateAll() actions = %s, %s, want deploy, release", results[0].Action, results[1].Action)
+	}
+
+	// Empty configuration restores defaults.
+	engine.SetActions(nil)
+	if got := len(engine.Actions()); got != 4 {
+		t.Errorf("Actions() after reset = %d, want 4", got)
+	}
+}
+
+func TestEngineMultiPrincipal(t *testing.T) {
+	engine := NewEngine()
+	policies := map[string]string{
+		"bot-automerge": `
+permit(principal == Bot::"renovate", action == Action::"merge", resource)
+when { context.lastRunPassed == true };
+`,
+		"maintainers-merge": `
+permit(principal in Team::"maintainers", action == Action::"merge", resource)
+when { context.hasWorkflow == true };
+`,
+	}
+	for id, text := range policies {
+		if err := engine.AddPolicy(id, []byte(text)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	renovate := model.Principal{Type: model.PrincipalTypeBot, ID: "renovate"}
+	alice := model.Principal{Type: model.PrincipalTypeUser, ID: "alice", Teams: []string{"maintainers"}}
+	bob := model.Principal{Type: model.PrincipalTypeUser, ID: "bob"}
+	engine.Configure(model.ProfileEvaluation{
+		Actions:    []string{ActionMerge},
+		Principals: []model.Principal{renovate, alice, bob},
+	})
+
+	ctx := &model.PolicyContext{
+		Repo: model.RepoContext{FullName: "org/repo"},
+		CI:   model.CIContext{HasWorkflow: true, LastRunPassed: false},
+	}
+
+	results := engine.EvaluateAll(ctx)
+	if len(results) != 3 {
+		t.Fatalf("EvaluateAll() returned %d results, want 3", len(results))
+	}
+
+	want := map[string]bool{
+		renovate.String(): false, // tests have not passed
+		alice.String():    true,  // member of maintainers
+		bob.String():      false, // not a maintainer
+	}
+	for _, r := range results {
+		if r.Allowed != want[r.Principal] {
+			t.Errorf("EvaluateAll() %s merge allowed = %v, want %v", r.Principal, r.Allowed, want[r.Principal])
+		}
+	}
+
+	v := results[0].ToViolation()
+	if v == nil || !strings.Contains(v.Message, `Bot::"renovate"`) {
+		t.Errorf("ToViolation() message = %v, want principal mentioned", v)
+	}
+
+	// Reconfiguring drops the team memberships of earlier principals.
+	engine.Configure(model.ProfileEvaluation{Principals: []model.Principal{bob}})
+	if r := engine.EvaluateAs(ctx, model.Principal{Type: model.PrincipalTypeUser, ID: "alice"}, ActionMerge); r.Allowed {
+		t.Error("EvaluateAs() alice merge allowed after reconfiguring without their team")
+	}
+}
+
+func TestPrincipalString(t *testing.T) {
+	p := DefaultPrincipal()
+	if got := p.String(); got != `CISystem::"pipelineconductor"` {
+		t.Errorf("DefaultPrincipal().String() = %s", got)
+	}
+}
+
+func TestEngineBranchProtectionDetail(t *testing.T) {
+	engine := NewEngine()
+	policy := []byte(`permit(principal, action == Action::"merge", resource)
+when {
+    context.requiredReviewers >= 2 &&
+    context.allowForcePushes == false &&
+    context.requireCodeOwnerReviews == true &&
+    context.requiredStatusChecks.contains("test")
+};`)
+	if err := engine.AddPolicy("reviews", policy); err != nil {
+		t.Fatalf("AddPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		bp   model.BranchProtection
+		want bool
+	}{
+		{
+			name: "meets requirements",
+			bp: model.BranchProtection{
+				Enabled:                 true,
+				RequiredReviewers:       2,
+				RequireCodeOwnerReviews: true,
+				RequiredStatusChecks:    []string{"test"},
+			},
+			want: true,
+		},
+		{
+			name: "one reviewer",
+			bp: model.BranchProtection{
+				Enabled:                 true,
+				RequiredReviewers:       1,
+				RequireCodeOwnerReviews: true,
+				RequiredStatusChecks:    []string{"test"},
+			},
+		},
+		{
+			name: "force pushes allowed",
+			bp: model.BranchProtection{
+				Enabled:                 true,
+				RequiredReviewers:       2,
+				RequireCodeOwnerReviews: true,
+				RequiredStatusChecks:    []string{"test"},
+				AllowForcePushes:        true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewContextBuilder(nil).Build(model.Repo{FullName: "org/repo"}, nil, &tt.bp)
+			if got := engine.Evaluate(ctx, ActionMerge).Allowed; got != tt.want {
+				t.Errorf("Evaluate().Allowed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cedar-policy/cedar-go"
+	cedarast "github.com/cedar-policy/cedar-go/ast"
+	"github.com/cedar-policy/cedar-go/x/exp/ast"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// Explain evaluates policies for a repository and action and returns a
+// per-policy trace showing scope matches, condition results, and which
+// policies determined the decision. The first configured principal is used.
+func (e *Engine) Explain(ctx *model.PolicyContext, action string) (*model.PolicyExplanation, error) {
+	return e.ExplainAs(ctx, e.principals[0], action)
+}
+
+// ExplainAs is like Explain but evaluates on behalf of a specific principal.
+func (e *Engine) ExplainAs(ctx *model.PolicyContext, principal model.Principal, action string) (*model.PolicyExplanation, error) {
+	req := e.buildRequest(ctx, principal, action)
+
+	decision, diagnostic := cedar.Authorize(e.policySet, e.entities, req)
+
+	contextMap, err := recordToMap(req.Context)
+	if err != nil {
+		return nil, fmt.Errorf("encoding context for %s: %w", ctx.Repo.FullName, err)
+	}
+
+	exp := &model.PolicyExplanation{
+		Repo:      ctx.Repo.FullName,
+		Action:    action,
+		Principal: req.Principal.String(),
+		Decision:  model.DecisionDeny,
+		Context:   contextMap,
+	}
+	if decision == cedar.Allow {
+		exp.Decision = model.DecisionAllow
+	}
+
+	determining := make(map[cedar.PolicyID]bool)
+	for _, reason := range diagnostic.Reasons {
+		determining[reason.PolicyID] = true
+	}
+	errored := make(map[cedar.PolicyID]string)
+	for _, derr := range diagnostic.Errors {
+		errored[derr.PolicyID] = derr.Message
+	}
+
+	var ids []cedar.PolicyID
+	for id := range e.policySet.All() {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		trace := e.tracePolicy(e.policySet.Get(id), req)
+		trace.ID = string(id)
+		trace.Determining = determining[id]
+		if msg, ok := errored[id]; ok {
+			trace.Error = msg
+			trace.Satisfied = false
+		}
+		exp.Policies = append(exp.Policies, trace)
+	}
+
+	return exp, nil
+}
+
+// ExplainAll explains every configured action for every configured principal.
+func (e *Engine) ExplainAll(ctx *model.PolicyContext) ([]*model.PolicyExplanation, error) {
+	var results []*model.PolicyExplanation
+	for _, principal := range e.principals {
+		for _, action := range e.actions {
+			exp, err := e.ExplainAs(ctx, principal, action)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, exp)
+		}
+	}
+	return results, nil
+}
+
+// tracePolicy evaluates the scope and each condition of a policy in isolation.
+func (e *Engine) tracePolicy(p *cedar.Policy, req cedar.Request) model.PolicyTrace {
+	src := (*ast.Policy)(p.AST())
+
+	trace := model.PolicyTrace{
+		Effect: "permit",
+		Scope:  scopeText(src),
+	}
+	if src.Effect == ast.EffectForbid {
+		trace.Effect = "forbid"
+	}
+
+	// Scope only: same principal/action/resource constraints, no conditions.
+	scopeOnly := &ast.Policy{
+		Effect:    ast.EffectPermit,
+		Principal: src.Principal,
+		Action:    src.Action,
+		Resource:  src.Resource,
+	}
+	trace.ScopeMatched, _ = e.isolatedDecision(scopeOnly, req)
+
+	trace.Satisfied = trace.ScopeMatched
+	for _, cond := range src.Conditions {
+		ct := model.ConditionTrace{
+			Kind: model.ConditionKindWhen,
+			Expr: conditionText(cond.Body),
+		}
+		if cond.Condition == ast.ConditionUnless {
+			ct.Kind = model.ConditionKindUnless
+		}
+
+		// Evaluate the clause body as a "when" clause on an unscoped permit,
+		// so an Allow decision means the expression evaluated to true.
+		bodyOnly := ast.Permit()
+		bodyOnly.Conditions = []ast.ConditionType{{Condition: ast.ConditionWhen, Body: cond.Body}}
+		value, errMsg := e.isolatedDecision(bodyOnly, req)
+		if errMsg != "" {
+			ct.Error = errMsg
+		} else {
+			ct.Value = &value
+			ct.Passed = value == (ct.Kind == model.ConditionKindWhen)
+		}
+
+		if !ct.Passed {
+			trace.Satisfied = false
+		}
+		trace.Conditions = append(trace.Conditions, ct)
+	}
+
+	return trace
+}
+
+// isolatedDecision authorizes a request against a single synthetic policy.
+// It returns true on Allow and any evaluation error message.
+func (e *Engine) isolatedDecision(p *ast.Policy, req cedar.Request) (bool, string) {
+	ps := cedar.NewPolicySet()
+	ps.Add("explain", cedar.NewPolicyFromAST((*cedarast.Policy)(p)))
+	decision, diagnostic := cedar.Authorize(ps, e.entities, req)
+	if len(diagnostic.Errors) > 0 {
+		return false, diagnostic.Errors[0].Message
+	}
+	return decision == cedar.Allow, ""
+}
+
+// scopeText renders the principal/action/resource scope of a policy.
+func scopeText(p *ast.Policy) string {
+	scopeOnly := &ast.Policy{
+		Effect:    p.Effect,
+		Principal: p.Principal,
+		Action:    p.Action,
+		Resource:  p.Resource,
+	}
+	text := string(cedar.NewPolicyFromAST((*cedarast.Policy)(scopeOnly)).MarshalCedar())
+	if start := strings.Index(text, "("); start >= 0 {
+		text = text[start+1:]
+	}
+	if end := strings.LastIndex(text, ")"); end >= 0 {
+		text = text[:end]
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// conditionText renders the body of a when/unless clause as Cedar text.
+func conditionText(body ast.IsNode) string {
+	p := ast.Permit()
+	p.Conditions = []ast.ConditionType{{Condition: ast.ConditionWhen, Body: body}}
+	text := string(cedar.NewPolicyFromAST((*cedarast.Policy)(p)).MarshalCedar())
+	if start := strings.Index(text, "when {"); start >= 0 {
+		text = text[start+len("when {"):]
+	}
+	text = strings.TrimSuffix(strings.TrimSpace(text), ";")
+	text = strings.TrimSuffix(strings.TrimSpace(text), "}")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// recordToMap converts a Cedar record into a JSON-friendly map.
+func recordToMap(r cedar.Record) (map[string]any, error) {
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
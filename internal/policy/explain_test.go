@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+func TestEngineExplain(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.AddPolicy("allow-merge", []byte(`
+permit(principal, action == Action::"merge", resource)
+when { context.hasWorkflow == true }
+unless { context.archived };
+`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AddPolicy("build-only", []byte(`
+permit(principal, action == Action::"build", resource);
+`)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &model.PolicyContext{
+		Repo: model.RepoContext{FullName: "org/repo", Archived: true},
+		CI:   model.CIContext{HasWorkflow: true},
+	}
+
+	exp, err := engine.Explain(ctx, ActionMerge)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+
+	if exp.Decision != model.DecisionDeny {
+		t.Errorf("Decision = %s, want deny", exp.Decision)
+	}
+	if exp.Principal != `CISystem::"pipelineconductor"` {
+		t.Errorf("Principal = %s", exp.Principal)
+	}
+	if exp.Context["hasWorkflow"] != true {
+		t.Errorf("Context[hasWorkflow] = %v, want true", exp.Context["hasWorkflow"])
+	}
+	if len(exp.Policies) != 2 {
+		t.Fatalf("len(Policies) = %d, want 2", len(exp.Policies))
+	}
+
+	merge := exp.Policies[0]
+	if merge.ID != "allow-merge" {
+		t.Fatalf("Policies[0].ID = %s, want allow-merge", merge.ID)
+	}
+	if !merge.ScopeMatched {
+		t.Error("allow-merge ScopeMatched = false, want true")
+	}
+	if merge.Satisfied {
+		t.Error("allow-merge Satisfied = true, want false")
+	}
+	if len(merge.Conditions) != 2 {
+		t.Fatalf("len(Conditions) = %d, want 2", len(merge.Conditions))
+	}
+	if c := merge.Conditions[0]; c.Kind != model.ConditionKindWhen || !c.Passed || c.Value == nil || !*c.Value {
+		t.Errorf("when condition = %+v, want passed with value true", c)
+	}
+	if c := merge.Conditions[1]; c.Kind != model.ConditionKindUnless || c.Passed || c.Expr != "context.archived" {
+		t.Errorf("unless condition = %+v, want failed on context.archived", c)
+	}
+
+	build := exp.Policies[1]
+	if build.ScopeMatched {
+		t.Error("build-only ScopeMatched = true, want false for merge")
+	}
+
+	// Allowed decisions mark the satisfied permit as determining.
+	ctx.Repo.Archived = false
+	exp, err = engine.Explain(ctx, ActionMerge)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if exp.Decision != model.DecisionAllow {
+		t.Errorf("Decision = %s, want allow", exp.Decision)
+	}
+	if ids := exp.DeterminingPolicies(); len(ids) != 1 || ids[0] != "allow-merge" {
+		t.Errorf("DeterminingPolicies() = %v, want [allow-merge]", ids)
+	}
+
+	if _, err := json.Marshal(exp); err != nil {
+		t.Errorf("json.Marshal() error = %v", err)
+	}
+}
+
+func TestEngineExplainAll(t *testing.T) {
+	engine := NewEngine()
+	if err := NewLoader(engine).LoadBuiltinPolicies(); err != nil {
+		t.Fatal(err)
+	}
+
+	exps, err := engine.ExplainAll(&model.PolicyContext{Repo: model.RepoContext{FullName: "org/repo"}})
+	if err != nil {
+		t.Fatalf("ExplainAll() error = %v", err)
+	}
+	if len(exps) != 4 {
+		t.Errorf("ExplainAll() returned %d explanations, want 4", len(exps))
+	}
+}
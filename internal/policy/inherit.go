@@ -0,0 +1,323 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// Removal syntax for list fields in profiles that extend other profiles.
+// A "!value" entry removes an inherited value; "!*" clears all inherited values.
+const (
+	removePrefix = "!"
+	removeAll    = "!*"
+)
+
+// profileSpec is the on-disk form of a profile. Unlike model.Profile it
+// distinguishes unset fields from zero values so inherited settings are
+// only overridden when explicitly set.
+type profileSpec struct {
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description"`
+	Extends     stringList `yaml:"extends"`
+	Go          struct {
+		Versions []string `yaml:"versions"`
+	} `yaml:"go"`
+	OS     []string `yaml:"os"`
+	Checks struct {
+		Required []string `yaml:"required"`
+	} `yaml:"checks"`
+	Lint struct {
+		Enabled *bool  `yaml:"enabled"`
+		Tool    string `yaml:"tool"`
+	} `yaml:"lint"`
+	Test struct {
+		Coverage *bool `yaml:"coverage"`
+		Race     *bool `yaml:"race"`
+	} `yaml:"test"`
+	Evaluation struct {
+		Actions    []string          `yaml:"actions"`
+		Principals []model.Principal `yaml:"principals"`
+	} `yaml:"evaluation"`
+}
+
+// stringList accepts either a single string or a list of strings.
+type stringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value != "" {
+			*s = []string{node.Value}
+		}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// ResolvedProfile is a fully resolved profile with the origin of each field.
+type ResolvedProfile struct {
+	Profile *model.Profile
+	// Origins maps a field path (e.g. "lint.tool", "os[ubuntu-latest]")
+	// to the name of the profile that defined it.
+	Origins map[string]string
+}
+
+// Origin returns the profile that defined a field path, or "" if unset.
+func (r *ResolvedProfile) Origin(field string) string {
+	return r.Origins[field]
+}
+
+// Resolve returns the fully resolved profile with field origins,
+// following extends chains and detecting cycles.
+func (m *ProfileManager) Resolve(name string) (*ResolvedProfile, error) {
+	return m.resolve(name, nil)
+}
+
+func (m *ProfileManager) resolve(name string, stack []string) (*ResolvedProfile, error) {
+	if i := slices.Index(stack, name); i >= 0 {
+		cycle := append(slices.Clone(stack[i:]), name)
+		return nil, fmt.Errorf("profile inheritance cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	spec, ok := m.specs[name]
+	if !ok {
+		profile, ok := m.profiles[name]
+		if !ok {
+			if len(stack) > 0 {
+				return nil, fmt.Errorf("profile %s extends unknown profile %s", stack[len(stack)-1], name)
+			}
+			return nil, fmt.Errorf("profile not found: %s", name)
+		}
+		return &ResolvedProfile{Profile: profile, Origins: originsFor(profile, name)}, nil
+	}
+
+	stack = append(stack, name)
+	result := &ResolvedProfile{
+		Profile: &model.Profile{},
+		Origins: make(map[string]string),
+	}
+	for _, parent := range spec.Extends {
+		base, err := m.resolve(parent, stack)
+		if err != nil {
+			return nil, err
+		}
+		mergeResolved(result, base)
+	}
+	applySpec(result, spec, name)
+
+	result.Profile.Name = name
+	result.Profile.Extends = slices.Clone(spec.Extends)
+	return result, nil
+}
+
+// mergeResolved layers a resolved parent profile onto dst.
+func mergeResolved(dst, src *ResolvedProfile) {
+	p, s := dst.Profile, src.Profile
+	if s.Description != "" {
+		p.Description = s.Description
+		dst.Origins["description"] = src.Origins["description"]
+	}
+	p.Go.Versions = mergeList(p.Go.Versions, s.Go.Versions, "go.versions", dst.Origins, src.Origins)
+	p.OS = mergeList(p.OS, s.OS, "os", dst.Origins, src.Origins)
+	p.Checks.Required = mergeList(p.Checks.Required, s.Checks.Required, "checks.required", dst.Origins, src.Origins)
+	p.Evaluation.Actions = mergeList(p.Evaluation.Actions, s.Evaluation.Actions, "evaluation.actions", dst.Origins, src.Origins)
+	p.Evaluation.Principals = mergePrincipals(p.Evaluation.Principals, s.Evaluation.Principals, dst.Origins,
+		func(key string) string { return src.Origins[key] })
+
+	for _, field := range []string{"lint.enabled", "lint.tool", "test.coverage", "test.race"} {
+		if origin, ok := src.Origins[field]; ok {
+			dst.Origins[field] = origin
+		}
+	}
+	if _, ok := src.Origins["lint.enabled"]; ok {
+		p.Lint.Enabled = s.Lint.Enabled
+	}
+	if s.Lint.Tool != "" {
+		p.Lint.Tool = s.Lint.Tool
+	}
+	if _, ok := src.Origins["test.coverage"]; ok {
+		p.Test.Coverage = s.Test.Coverage
+	}
+	if _, ok := src.Origins["test.race"]; ok {
+		p.Test.Race = s.Test.Race
+	}
+}
+
+// applySpec layers a profile's own settings onto dst.
+func applySpec(dst *ResolvedProfile, spec *profileSpec, name string) {
+	p := dst.Profile
+	if spec.Description != "" {
+		p.Description = spec.Description
+		dst.Origins["description"] = name
+	}
+	p.Go.Versions = applyList(p.Go.Versions, spec.Go.Versions, "go.versions", dst.Origins, name)
+	p.OS = applyList(p.OS, spec.OS, "os", dst.Origins, name)
+	p.Checks.Required = applyList(p.Checks.Required, spec.Checks.Required, "checks.required", dst.Origins, name)
+	p.Evaluation.Actions = applyList(p.Evaluation.Actions, spec.Evaluation.Actions, "evaluation.actions", dst.Origins, name)
+	p.Evaluation.Principals = mergePrincipals(p.Evaluation.Principals, spec.Evaluation.Principals, dst.Origins,
+		func(string) string { return name })
+
+	if spec.Lint.Enabled != nil {
+		p.Lint.Enabled = *spec.Lint.Enabled
+		dst.Origins["lint.enabled"] = name
+	}
+	if spec.Lint.Tool != "" {
+		p.Lint.Tool = spec.Lint.Tool
+		dst.Origins["lint.tool"] = name
+	}
+	if spec.Test.Coverage != nil {
+		p.Test.Coverage = *spec.Test.Coverage
+		dst.Origins["test.coverage"] = name
+	}
+	if spec.Test.Race != nil {
+		p.Test.Race = *spec.Test.Race
+		dst.Origins["test.race"] = name
+	}
+}
+
+// mergeList appends inherited items not already present, keeping their origins.
+func mergeList(dst, src []string, field string, dstOrigins, srcOrigins map[string]string) []string {
+	for _, item := range src {
+		key := listKey(field, item)
+		if !slices.Contains(dst, item) {
+			dst = append(dst, item)
+		}
+		dstOrigins[key] = srcOrigins[key]
+	}
+	return dst
+}
+
+// applyList applies a profile's own list entries, honoring removal syntax.
+func applyList(dst, items []string, field string, origins map[string]string, name string) []string {
+	for _, item := range items {
+		switch {
+		case item == removeAll:
+			for _, existing := range dst {
+				delete(origins, listKey(field, existing))
+			}
+			dst = nil
+		case strings.HasPrefix(item, removePrefix):
+			value := strings.TrimPrefix(item, removePrefix)
+			dst = slices.DeleteFunc(dst, func(s string) bool { return s == value })
+			delete(origins, listKey(field, value))
+		default:
+			if !slices.Contains(dst, item) {
+				dst = append(dst, item)
+			}
+			origins[listKey(field, item)] = name
+		}
+	}
+	return dst
+}
+
+// mergePrincipals appends principals not already present, recording the
+// origin reported by originOf for each.
+func mergePrincipals(dst, src []model.Principal, origins map[string]string, originOf func(key string) string) []model.Principal {
+	for _, p := range src {
+		key := listKey("evaluation.principals", p.String())
+		if !slices.ContainsFunc(dst, func(e model.Principal) bool { return e.String() == p.String() }) {
+			dst = append(dst, p)
+		}
+		origins[key] = originOf(key)
+	}
+	return dst
+}
+
+func listKey(field, item string) string {
+	return field + "[" + item + "]"
+}
+
+// originsFor attributes every set field of a concrete profile to name.
+func originsFor(p *model.Profile, name string) map[string]string {
+	origins := make(map[string]string)
+	if p.Description != "" {
+		origins["description"] = name
+	}
+	for field, items := range map[string][]string{
+		"go.versions":        p.Go.Versions,
+		"os":                 p.OS,
+		"checks.required":    p.Checks.Required,
+		"evaluation.actions": p.Evaluation.Actions,
+	} {
+		for _, item := range items {
+			origins[listKey(field, item)] = name
+		}
+	}
+	for _, pr := range p.Evaluation.Principals {
+		origins[listKey("evaluation.principals", pr.String())] = name
+	}
+	origins["lint.enabled"] = name
+	if p.Lint.Tool != "" {
+		origins["lint.tool"] = name
+	}
+	origins["test.coverage"] = name
+	origins["test.race"] = name
+	return origins
+}
+
+// WriteResolvedProfile writes a resolved profile as YAML annotated with
+// the origin of each field.
+func WriteResolvedProfile(w io.Writer, rp *ResolvedProfile) error {
+	p := rp.Profile
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("name: %s\n", p.Name))
+	if len(p.Extends) > 0 {
+		sb.WriteString(fmt.Sprintf("extends: [%s]\n", strings.Join(p.Extends, ", ")))
+	}
+	if p.Description != "" {
+		sb.WriteString(fmt.Sprintf("description: %s  # from %s\n", p.Description, rp.Origin("description")))
+	}
+
+	writeList := func(indent, key, field string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("%s%s:\n", indent, key))
+		for _, item := range items {
+			sb.WriteString(fmt.Sprintf("%s  - %q  # from %s\n", indent, item, rp.Origin(listKey(field, item))))
+		}
+	}
+	writeScalar := func(key, field string, value any) {
+		if origin := rp.Origin(field); origin != "" {
+			sb.WriteString(fmt.Sprintf("  %s: %v  # from %s\n", key, value, origin))
+		}
+	}
+
+	sb.WriteString("go:\n")
+	writeList("  ", "versions", "go.versions", p.Go.Versions)
+	writeList("", "os", "os", p.OS)
+	sb.WriteString("checks:\n")
+	writeList("  ", "required", "checks.required", p.Checks.Required)
+	sb.WriteString("lint:\n")
+	writeScalar("enabled", "lint.enabled", p.Lint.Enabled)
+	writeScalar("tool", "lint.tool", p.Lint.Tool)
+	sb.WriteString("test:\n")
+	writeScalar("coverage", "test.coverage", p.Test.Coverage)
+	writeScalar("race", "test.race", p.Test.Race)
+
+	if len(p.Evaluation.Actions) > 0 || len(p.Evaluation.Principals) > 0 {
+		sb.WriteString("evaluation:\n")
+		writeList("  ", "actions", "evaluation.actions", p.Evaluation.Actions)
+		if len(p.Evaluation.Principals) > 0 {
+			sb.WriteString("  principals:\n")
+			for _, pr := range p.Evaluation.Principals {
+				sb.WriteString(fmt.Sprintf("    - %s  # from %s\n", pr.String(), rp.Origin(listKey("evaluation.principals", pr.String()))))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
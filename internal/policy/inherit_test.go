@@ -0,0 +1,211 @@
+package policy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeProfile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProfileManagerExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeProfile(t, dir, "strict", `
+name: strict
+extends: default
+description: Stricter version of default
+go:
+  versions:
+    - "!1.24"
+    - "1.26"
+os:
+  - "!windows-latest"
+checks:
+  required:
+    - codeql
+lint:
+  tool: golangci-lint-v2
+`)
+
+	pm := NewProfileManager()
+	pm.LoadBuiltinProfiles()
+	if err := pm.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory() error = %v", err)
+	}
+
+	resolved, err := pm.Resolve("strict")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	p := resolved.Profile
+
+	if want := []string{"1.25", "1.26"}; !slices.Equal(p.Go.Versions, want) {
+		t.Errorf("Go.Versions = %v, want %v", p.Go.Versions, want)
+	}
+	if want := []string{"ubuntu-latest", "macos-latest"}; !slices.Equal(p.OS, want) {
+		t.Errorf("OS = %v, want %v", p.OS, want)
+	}
+	if want := []string{"test", "lint", "build", "codeql"}; !slices.Equal(p.Checks.Required, want) {
+		t.Errorf("Checks.Required = %v, want %v", p.Checks.Required, want)
+	}
+	if !p.Lint.Enabled || !p.Test.Race || !p.Test.Coverage {
+		t.Errorf("inherited booleans not preserved: %+v %+v", p.Lint, p.Test)
+	}
+	if p.Lint.Tool != "golangci-lint-v2" {
+		t.Errorf("Lint.Tool = %s, want golangci-lint-v2", p.Lint.Tool)
+	}
+	if !slices.Equal(p.Extends, []string{"default"}) {
+		t.Errorf("Extends = %v, want [default]", p.Extends)
+	}
+
+	origins := map[string]string{
+		"description":           "strict",
+		"go.versions[1.25]":     "default",
+		"go.versions[1.26]":     "strict",
+		"os[ubuntu-latest]":     "default",
+		"checks.required[test]": "default",
+		"lint.tool":             "strict",
+		"lint.enabled":          "default",
+		"test.race":             "default",
+	}
+	for field, want := range origins {
+		if got := resolved.Origin(field); got != want {
+			t.Errorf("Origin(%q) = %q, want %q", field, got, want)
+		}
+	}
+	if got := resolved.Origin("os[windows-latest]"); got != "" {
+		t.Errorf("Origin(os[windows-latest]) = %q, want removed", got)
+	}
+
+	// Get returns the same resolved profile.
+	got, err := pm.Get("strict")
+	if err != nil || got.Name != "strict" {
+		t.Errorf("Get(strict) = %v, %v", got, err)
+	}
+}
+
+func TestProfileManagerExtendsChainAndComposition(t *testing.T) {
+	dir := t.TempDir()
+	writeProfile(t, dir, "base", `
+go:
+  versions: ["1.24"]
+test:
+  race: false
+`)
+	writeProfile(t, dir, "lint", `
+lint:
+  enabled: true
+  tool: golangci-lint
+`)
+	writeProfile(t, dir, "middle", `
+extends: base
+go:
+  versions: ["1.25"]
+`)
+	writeProfile(t, dir, "leaf", `
+extends: [middle, lint]
+go:
+  versions: ["!*", "1.26"]
+test:
+  race: true
+`)
+
+	pm := NewProfileManager()
+	if err := pm.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory() error = %v", err)
+	}
+
+	resolved, err := pm.Resolve("leaf")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	p := resolved.Profile
+	if !slices.Equal(p.Go.Versions, []string{"1.26"}) {
+		t.Errorf("Go.Versions = %v, want [1.26]", p.Go.Versions)
+	}
+	if !p.Lint.Enabled || resolved.Origin("lint.enabled") != "lint" {
+		t.Errorf("Lint.Enabled = %v from %q, want true from lint", p.Lint.Enabled, resolved.Origin("lint.enabled"))
+	}
+	if !p.Test.Race || resolved.Origin("test.race") != "leaf" {
+		t.Errorf("Test.Race = %v from %q, want true from leaf", p.Test.Race, resolved.Origin("test.race"))
+	}
+
+	middle, err := pm.Resolve("middle")
+	if err != nil {
+		t.Fatalf("Resolve(middle) error = %v", err)
+	}
+	if middle.Origin("go.versions[1.24]") != "base" || middle.Origin("go.versions[1.25]") != "middle" {
+		t.Errorf("middle origins = %v", middle.Origins)
+	}
+}
+
+func TestProfileManagerExtendsErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeProfile(t, dir, "a", "extends: b\n")
+	writeProfile(t, dir, "b", "extends: c\n")
+	writeProfile(t, dir, "c", "extends: a\n")
+	writeProfile(t, dir, "orphan", "extends: missing\n")
+
+	pm := NewProfileManager()
+	pm.LoadBuiltinProfiles()
+	if err := pm.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory() error = %v", err)
+	}
+
+	_, err := pm.Get("a")
+	if err == nil || !strings.Contains(err.Error(), "cycle: a -> b -> c -> a") {
+		t.Errorf("Get(a) error = %v, want cycle error", err)
+	}
+
+	_, err = pm.Get("orphan")
+	if err == nil || !strings.Contains(err.Error(), "unknown profile missing") {
+		t.Errorf("Get(orphan) error = %v, want unknown parent error", err)
+	}
+
+	if p := pm.GetOrDefault("a"); p.Name != "default" {
+		t.Errorf("GetOrDefault(a).Name = %s, want default", p.Name)
+	}
+}
+
+func TestWriteResolvedProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeProfile(t, dir, "strict", `
+extends: default
+go:
+  versions: ["1.26"]
+`)
+
+	pm := NewProfileManager()
+	pm.LoadBuiltinProfiles()
+	if err := pm.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := pm.Resolve("strict")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteResolvedProfile(&buf, resolved); err != nil {
+		t.Fatalf("WriteResolvedProfile() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"extends: [default]",
+		`- "1.24"  # from default`,
+		`- "1.26"  # from strict`,
+		"race: true  # from default",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+}
@@ -12,12 +12,16 @@ import (
 // ProfileManager manages CI/CD profiles.
 type ProfileManager struct {
 	profiles map[string]*model.Profile
+	// specs holds profiles loaded from files that extend other profiles;
+	// they are resolved on access.
+	specs map[string]*profileSpec
 }
 
 // NewProfileManager creates a new profile manager.
 func NewProfileManager() *ProfileManager {
 	return &ProfileManager{
 		profiles: make(map[string]*model.Profile),
+		specs:    make(map[string]*profileSpec),
 	}
 }
 
@@ -47,43 +51,53 @@ func (m *ProfileManager) LoadFromDirectory(dir string) error {
 	return nil
 }
 
-// LoadFromFile loads a profile from a YAML file.
+// LoadFromFile loads a profile from a YAML file. Profiles that use
+// `extends` are resolved against their parents when first accessed.
 func (m *ProfileManager) LoadFromFile(path string) error {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("reading file %s: %w", path, err)
 	}
 
-	var profile model.Profile
-	if err := yaml.Unmarshal(content, &profile); err != nil {
+	var spec profileSpec
+	if err := yaml.Unmarshal(content, &spec); err != nil {
 		return fmt.Errorf("parsing YAML %s: %w", path, err)
 	}
 
-	if profile.Name == "" {
+	if spec.Name == "" {
 		// Use filename as profile name
 		base := filepath.Base(path)
-		profile.Name = base[:len(base)-len(filepath.Ext(base))]
+		spec.Name = base[:len(base)-len(filepath.Ext(base))]
 	}
 
-	m.profiles[profile.Name] = &profile
+	delete(m.profiles, spec.Name)
+	m.specs[spec.Name] = &spec
+	if len(spec.Extends) == 0 {
+		resolved, err := m.Resolve(spec.Name)
+		if err != nil {
+			return fmt.Errorf("loading profile %s: %w", path, err)
+		}
+		delete(m.specs, spec.Name)
+		m.profiles[spec.Name] = resolved.Profile
+	}
 	return nil
 }
 
-// Get returns a profile by name.
+// Get returns a profile by name, resolving any inheritance.
 func (m *ProfileManager) Get(name string) (*model.Profile, error) {
-	profile, ok := m.profiles[name]
-	if !ok {
-		return nil, fmt.Errorf("profile not found: %s", name)
+	resolved, err := m.Resolve(name)
+	if err != nil {
+		return nil, err
 	}
-	return profile, nil
+	return resolved.Profile, nil
 }
 
 // GetOrDefault returns a profile by name, or the default profile if not found.
 func (m *ProfileManager) GetOrDefault(name string) *model.Profile {
-	if profile, ok := m.profiles[name]; ok {
+	if profile, err := m.Get(name); err == nil {
 		return profile
 	}
-	if profile, ok := m.profiles["default"]; ok {
+	if profile, err := m.Get("default"); err == nil {
 		return profile
 	}
 	return DefaultProfile()
@@ -91,15 +105,19 @@ func (m *ProfileManager) GetOrDefault(name string) *model.Profile {
 
 // List returns all profile names.
 func (m *ProfileManager) List() []string {
-	names := make([]string, 0, len(m.profiles))
+	names := make([]string, 0, len(m.profiles)+len(m.specs))
 	for name := range m.profiles {
 		names = append(names, name)
 	}
+	for name := range m.specs {
+		names = append(names, name)
+	}
 	return names
 }
 
 // Add adds a profile to the manager.
 func (m *ProfileManager) Add(profile *model.Profile) {
+	delete(m.specs, profile.Name)
 	m.profiles[profile.Name] = profile
 }
 
@@ -0,0 +1,332 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// maxReusableDepth bounds how deeply local reusable workflows are followed.
+const maxReusableDepth = 4
+
+// StatusCheck is a check run name a workflow job reports to GitHub.
+type StatusCheck struct {
+	Name     string `json:"name"`
+	Workflow string `json:"workflow"`
+	Job      string `json:"job"`
+	// Wildcard is set for jobs calling a remote reusable workflow, whose
+	// nested job names are unknown; Name then ends in " / *".
+	Wildcard bool `json:"wildcard,omitempty"`
+}
+
+// Matches reports whether the check produces the given check name.
+func (c StatusCheck) Matches(name string) bool {
+	if c.Wildcard {
+		return strings.HasPrefix(name, strings.TrimSuffix(c.Name, "*"))
+	}
+	return c.Name == name
+}
+
+// ProducedChecks computes the check names produced by workflow jobs,
+// expanding matrices and prefixing jobs of local reusable workflows with
+// the calling job's name ("caller / nested").
+func ProducedChecks(workflows []model.Workflow) []StatusCheck {
+	byPath := make(map[string]model.Workflow, len(workflows))
+	for _, wf := range workflows {
+		byPath[wf.Path] = wf
+	}
+
+	var checks []StatusCheck
+	for _, wf := range workflows {
+		// Workflows only triggered by workflow_call report checks under
+		// their callers' names.
+		if len(wf.Triggers) == 1 && wf.Triggers[0] == "workflow_call" {
+			continue
+		}
+		checks = append(checks, workflowChecks(wf, byPath, 0)...)
+	}
+	return checks
+}
+
+func workflowChecks(wf model.Workflow, byPath map[string]model.Workflow, depth int) []StatusCheck {
+	var checks []StatusCheck
+	for _, job := range wf.Jobs {
+		for _, name := range jobCheckNames(job) {
+			if !job.UsesReusableWorkflow || job.ReusableWorkflowRef == nil {
+				checks = append(checks, StatusCheck{Name: name, Workflow: wf.Path, Job: job.ID})
+				continue
+			}
+
+			called, ok := byPath[strings.TrimPrefix(job.ReusableWorkflowRef.FullRef, "./")]
+			if !ok || !strings.HasPrefix(job.ReusableWorkflowRef.FullRef, "./") || depth >= maxReusableDepth {
+				checks = append(checks, StatusCheck{Name: name + " / *", Workflow: wf.Path, Job: job.ID, Wildcard: true})
+				continue
+			}
+			for _, nested := range workflowChecks(called, byPath, depth+1) {
+				nested.Name = name + " / " + nested.Name
+				nested.Workflow = wf.Path
+				nested.Job = job.ID
+				checks = append(checks, nested)
+			}
+		}
+	}
+	return checks
+}
+
+// jobCheckNames returns the check names for a job: its name (or ID), with
+// the matrix values appended for each combination unless the name already
+// interpolates them.
+func jobCheckNames(job model.WorkflowJob) []string {
+	base := job.Name
+	if base == "" {
+		base = job.ID
+	}
+
+	combos := expandMatrix(job.Matrix)
+	if len(combos) == 0 {
+		return []string{base}
+	}
+
+	names := make([]string, 0, len(combos))
+	for _, combo := range combos {
+		if strings.Contains(base, "${{") {
+			name := base
+			for _, kv := range combo {
+				name = replaceMatrixExpr(name, kv[0], kv[1])
+			}
+			names = append(names, name)
+			continue
+		}
+		values := make([]string, len(combo))
+		for i, kv := range combo {
+			values[i] = kv[1]
+		}
+		names = append(names, fmt.Sprintf("%s (%s)", base, strings.Join(values, ", ")))
+	}
+	return names
+}
+
+// replaceMatrixExpr substitutes ${{ matrix.key }} (with optional spacing).
+func replaceMatrixExpr(s, key, value string) string {
+	for _, expr := range []string{"${{ matrix." + key + " }}", "${{matrix." + key + "}}"} {
+		s = strings.ReplaceAll(s, expr, value)
+	}
+	return s
+}
+
+// expandMatrix returns the matrix combinations as ordered key/value pairs,
+// applying exclude and include entries. Keys added by include entries are
+// appended in sorted order, since their declaration order is not retained.
+func expandMatrix(m *model.MatrixConfig) [][][2]string {
+	if m == nil {
+		return nil
+	}
+
+	var combos [][][2]string
+	for _, dim := range m.Dimensions {
+		if len(dim.Values) == 0 {
+			continue
+		}
+		if len(combos) == 0 {
+			combos = [][][2]string{nil}
+		}
+		var next [][][2]string
+		for _, combo := range combos {
+			for _, v := range dim.Values {
+				next = append(next, append(slices.Clone(combo), [2]string{dim.Key, v}))
+			}
+		}
+		combos = next
+	}
+
+	combos = slices.DeleteFunc(combos, func(combo [][2]string) bool {
+		for _, ex := range m.Exclude {
+			if excludes(combo, ex) {
+				return true
+			}
+		}
+		return false
+	})
+
+	isDim := func(key string) bool {
+		return slices.ContainsFunc(m.Dimensions, func(d model.MatrixDimension) bool { return d.Key == key })
+	}
+
+	// An include entry extends every combination whose original values it
+	// does not overwrite; if there is none, it becomes a new combination.
+	for _, inc := range m.Include {
+		extended := false
+		for i, combo := range combos {
+			if conflicts(combo, inc, isDim) {
+				continue
+			}
+			combos[i] = mergePairs(combo, inc)
+			extended = true
+		}
+		if !extended {
+			var combo [][2]string
+			for _, dim := range m.Dimensions {
+				if v, ok := inc[dim.Key]; ok {
+					combo = append(combo, [2]string{dim.Key, v})
+				}
+			}
+			combos = append(combos, mergePairs(combo, inc))
+		}
+	}
+
+	return combos
+}
+
+// excludes reports whether every key of an exclude entry matches the combination.
+func excludes(combo [][2]string, entry map[string]string) bool {
+	for k, v := range entry {
+		if !slices.Contains(combo, [2]string{k, v}) {
+			return false
+		}
+	}
+	return len(entry) > 0
+}
+
+// conflicts reports whether an include entry would overwrite an original
+// matrix value of the combination.
+func conflicts(combo [][2]string, entry map[string]string, isDim func(string) bool) bool {
+	for _, kv := range combo {
+		if v, ok := entry[kv[0]]; ok && isDim(kv[0]) && v != kv[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// mergePairs sets entry values on a copy of the combination. Keys not
+// already present are appended in sorted order.
+func mergePairs(combo [][2]string, entry map[string]string) [][2]string {
+	result := slices.Clone(combo)
+	keys := make([]string, 0, len(entry))
+	for k := range entry {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if i := slices.IndexFunc(result, func(kv [2]string) bool { return kv[0] == k }); i >= 0 {
+			result[i][1] = entry[k]
+			continue
+		}
+		result = append(result, [2]string{k, entry[k]})
+	}
+	return result
+}
+
+// StatusCheckReconciliation compares branch protection required checks
+// with the checks workflows produce.
+type StatusCheckReconciliation struct {
+	Branch   string        `json:"branch"`
+	Required []string      `json:"required"`
+	Produced []StatusCheck `json:"produced"`
+	// Missing lists required checks no workflow produces; they block merges.
+	Missing []string `json:"missing,omitempty"`
+	// Unrequired lists important jobs whose checks are not required by
+	// branch protection.
+	Unrequired []UnrequiredJob `json:"unrequired,omitempty"`
+}
+
+// UnrequiredJob is a job whose checks are not required by branch protection.
+type UnrequiredJob struct {
+	Workflow string   `json:"workflow"`
+	Job      string   `json:"job"`
+	Checks   []string `json:"checks"`
+}
+
+// ReconcileStatusChecks compares required status checks with the checks
+// produced by workflows. Jobs whose ID contains one of the important names
+// (e.g. "test", "lint") are reported if none of their checks are required.
+func ReconcileStatusChecks(workflows []model.Workflow, bp *model.BranchProtection, important []string) *StatusCheckReconciliation {
+	r := &StatusCheckReconciliation{
+		Produced: ProducedChecks(workflows),
+	}
+	if bp == nil {
+		return r
+	}
+	r.Branch = bp.Branch
+	r.Required = bp.RequiredStatusChecks
+
+	for _, req := range r.Required {
+		if !slices.ContainsFunc(r.Produced, func(c StatusCheck) bool { return c.Matches(req) }) {
+			r.Missing = append(r.Missing, req)
+		}
+	}
+
+	if !bp.Enabled || !bp.RequireStatusChecks {
+		return r
+	}
+
+	// Group produced checks by job and report important jobs with no
+	// required check.
+	var jobs []UnrequiredJob
+	required := make(map[int]bool)
+	for _, c := range r.Produced {
+		i := slices.IndexFunc(jobs, func(j UnrequiredJob) bool { return j.Workflow == c.Workflow && j.Job == c.Job })
+		if i < 0 {
+			jobs = append(jobs, UnrequiredJob{Workflow: c.Workflow, Job: c.Job})
+			i = len(jobs) - 1
+		}
+		jobs[i].Checks = append(jobs[i].Checks, c.Name)
+		if slices.ContainsFunc(r.Required, c.Matches) {
+			required[i] = true
+		}
+	}
+	for i, job := range jobs {
+		if !required[i] && isImportantJob(job.Job, important) {
+			r.Unrequired = append(r.Unrequired, job)
+		}
+	}
+
+	return r
+}
+
+func isImportantJob(jobID string, important []string) bool {
+	id := strings.ToLower(jobID)
+	for _, name := range important {
+		if name != "" && strings.Contains(id, strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProducedNames returns the names of all produced checks.
+func (r *StatusCheckReconciliation) ProducedNames() []string {
+	names := make([]string, 0, len(r.Produced))
+	for _, c := range r.Produced {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Violations converts reconciliation gaps into policy violations.
+func (r *StatusCheckReconciliation) Violations() []model.Violation {
+	var violations []model.Violation
+	for _, name := range r.Missing {
+		violations = append(violations, model.Violation{
+			Policy:      "branch-protection/status-checks",
+			Rule:        "required-check-not-produced",
+			Message:     fmt.Sprintf("Required status check %q on %s is not produced by any workflow, so merges are blocked", name, r.Branch),
+			Severity:    model.SeverityHigh,
+			Remediation: fmt.Sprintf("Rename a job to produce %q or remove it from the required status checks (ignore if an external app reports it)", name),
+		})
+	}
+	for _, job := range r.Unrequired {
+		violations = append(violations, model.Violation{
+			Policy:      "branch-protection/status-checks",
+			Rule:        "check-not-required",
+			Message:     fmt.Sprintf("Job %s runs but none of its checks are required on %s", job.Job, r.Branch),
+			Severity:    model.SeverityLow,
+			Remediation: fmt.Sprintf("Add %s to the required status checks", strings.Join(job.Checks, ", ")),
+			File:        job.Workflow,
+		})
+	}
+	return violations
+}
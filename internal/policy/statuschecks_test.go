@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+func TestJobCheckNames(t *testing.T) {
+	tests := []struct {
+		name string
+		job  model.WorkflowJob
+		want []string
+	}{
+		{
+			name: "job id",
+			job:  model.WorkflowJob{ID: "lint"},
+			want: []string{"lint"},
+		},
+		{
+			name: "job name",
+			job:  model.WorkflowJob{ID: "lint", Name: "Lint"},
+			want: []string{"Lint"},
+		},
+		{
+			name: "matrix",
+			job: model.WorkflowJob{
+				ID: "test",
+				Matrix: &model.MatrixConfig{Dimensions: []model.MatrixDimension{
+					{Key: "os", Values: []string{"ubuntu-latest", "macos-latest"}},
+					{Key: "go", Values: []string{"1.25"}},
+				}},
+			},
+			want: []string{"test (ubuntu-latest, 1.25)", "test (macos-latest, 1.25)"},
+		},
+		{
+			name: "matrix include and exclude",
+			job: model.WorkflowJob{
+				ID: "test",
+				Matrix: &model.MatrixConfig{
+					Dimensions: []model.MatrixDimension{
+						{Key: "os", Values: []string{"ubuntu-latest", "windows-latest"}},
+						{Key: "go", Values: []string{"1.24", "1.25"}},
+					},
+					Exclude: []map[string]string{{"os": "windows-latest", "go": "1.24"}},
+					Include: []map[string]string{
+						{"os": "windows-latest", "experimental": "true"},
+						{"os": "macos-latest", "go": "1.25"},
+					},
+				},
+			},
+			want: []string{
+				"test (ubuntu-latest, 1.24)",
+				"test (ubuntu-latest, 1.25)",
+				"test (windows-latest, 1.25, true)",
+				"test (macos-latest, 1.25)",
+			},
+		},
+		{
+			name: "name interpolates matrix",
+			job: model.WorkflowJob{
+				ID:   "test",
+				Name: "Test on ${{ matrix.os }}",
+				Matrix: &model.MatrixConfig{Dimensions: []model.MatrixDimension{
+					{Key: "os", Values: []string{"ubuntu-latest"}},
+				}},
+			},
+			want: []string{"Test on ubuntu-latest"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := jobCheckNames(tt.job)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("jobCheckNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProducedChecksReusable(t *testing.T) {
+	workflows := []model.Workflow{
+		{
+			Path:     ".github/workflows/ci.yaml",
+			Triggers: []string{"push"},
+			Jobs: []model.WorkflowJob{
+				{
+					ID:                   "local",
+					Name:                 "CI",
+					UsesReusableWorkflow: true,
+					ReusableWorkflowRef:  model.ParseReusableWorkflowRef("./.github/workflows/reusable.yaml"),
+				},
+				{
+					ID:                   "remote",
+					UsesReusableWorkflow: true,
+					ReusableWorkflowRef:  model.ParseReusableWorkflowRef("myorg/.github/.github/workflows/go-ci.yaml@v1"),
+				},
+			},
+		},
+		{
+			Path:     ".github/workflows/reusable.yaml",
+			Triggers: []string{"workflow_call"},
+			Jobs:     []model.WorkflowJob{{ID: "build"}},
+		},
+	}
+
+	got := ProducedChecks(workflows)
+	var names []string
+	for _, c := range got {
+		names = append(names, c.Name)
+	}
+	if want := []string{"CI / build", "remote / *"}; !slices.Equal(names, want) {
+		t.Fatalf("ProducedChecks() = %q, want %q", names, want)
+	}
+	if !got[1].Matches("remote / test (ubuntu-latest)") {
+		t.Error("wildcard check should match nested job names")
+	}
+}
+
+func TestReconcileStatusChecks(t *testing.T) {
+	workflows := []model.Workflow{{
+		Path:     ".github/workflows/ci.yaml",
+		Triggers: []string{"push"},
+		Jobs: []model.WorkflowJob{
+			{ID: "build"},
+			{ID: "lint"},
+			{ID: "test", Matrix: &model.MatrixConfig{Dimensions: []model.MatrixDimension{
+				{Key: "os", Values: []string{"ubuntu-latest", "macos-latest"}},
+			}}},
+		},
+	}}
+	bp := &model.BranchProtection{
+		Branch:               "main",
+		Enabled:              true,
+		RequireStatusChecks:  true,
+		RequiredStatusChecks: []string{"build", "test (ubuntu-latest)", "test (windows-latest)"},
+	}
+
+	r := ReconcileStatusChecks(workflows, bp, []string{"test", "lint", "build"})
+
+	if want := []string{"test (windows-latest)"}; !slices.Equal(r.Missing, want) {
+		t.Errorf("Missing = %q, want %q", r.Missing, want)
+	}
+	if len(r.Unrequired) != 1 || r.Unrequired[0].Job != "lint" {
+		t.Errorf("Unrequired = %+v, want lint only", r.Unrequired)
+	}
+
+	violations := r.Violations()
+	if len(violations) != 2 {
+		t.Fatalf("Violations() = %+v, want 2", violations)
+	}
+	if violations[0].Rule != "required-check-not-produced" || violations[0].Severity != model.SeverityHigh {
+		t.Errorf("violations[0] = %+v", violations[0])
+	}
+	if violations[1].Rule != "check-not-required" || violations[1].File != ".github/workflows/ci.yaml" {
+		t.Errorf("violations[1] = %+v", violations[1])
+	}
+}
+
+func TestContextBuilderStatusChecks(t *testing.T) {
+	workflows := []model.Workflow{{
+		Path:     ".github/workflows/ci.yaml",
+		Triggers: []string{"push"},
+		Jobs:     []model.WorkflowJob{{ID: "test"}, {ID: "lint"}},
+	}}
+	bp := &model.BranchProtection{
+		Branch:               "main",
+		Enabled:              true,
+		RequireStatusChecks:  true,
+		RequiredStatusChecks: []string{"test", "e2e"},
+	}
+
+	ctx := NewContextBuilder(nil).Build(model.Repo{FullName: "myorg/api"}, workflows, bp)
+
+	if !slices.Equal(ctx.CI.RequiredChecks, []string{"test", "e2e"}) {
+		t.Errorf("RequiredChecks = %q", ctx.CI.RequiredChecks)
+	}
+	if !slices.Equal(ctx.CI.ProducedChecks, []string{"test", "lint"}) {
+		t.Errorf("ProducedChecks = %q", ctx.CI.ProducedChecks)
+	}
+	if !slices.Equal(ctx.CI.MissingRequiredChecks, []string{"e2e"}) {
+		t.Errorf("MissingRequiredChecks = %q", ctx.CI.MissingRequiredChecks)
+	}
+	if !slices.Equal(ctx.CI.UnrequiredJobs, []string{"lint"}) {
+		t.Errorf("UnrequiredJobs = %q", ctx.CI.UnrequiredJobs)
+	}
+}
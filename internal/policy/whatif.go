@@ -0,0 +1,261 @@
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/cedar-policy/cedar-go"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// Change operators for what-if analysis.
+const (
+	ChangeOpSet    = "="
+	ChangeOpAdd    = "+="
+	ChangeOpRemove = "-="
+)
+
+// DefaultMaxChanges limits the size of change sets explored by WhatIf.
+const DefaultMaxChanges = 4
+
+// Change is a candidate modification to a Cedar context attribute,
+// e.g. "usesReusableWorkflow=true" or "goVersions+=1.25".
+type Change struct {
+	Attribute string `json:"attribute"`
+	Op        string `json:"op"`
+	Value     string `json:"value"`
+}
+
+// ParseChange parses a change expression of the form attr=value,
+// attr+=value (add to set), or attr-=value (remove from set).
+func ParseChange(s string) (Change, error) {
+	for _, op := range []string{ChangeOpAdd, ChangeOpRemove, ChangeOpSet} {
+		if attr, value, found := strings.Cut(s, op); found {
+			attr = strings.TrimSpace(attr)
+			if attr == "" {
+				return Change{}, fmt.Errorf("invalid change %q: missing attribute", s)
+			}
+			return Change{Attribute: attr, Op: op, Value: strings.TrimSpace(value)}, nil
+		}
+	}
+	return Change{}, fmt.Errorf("invalid change %q: expected attr=value, attr+=value, or attr-=value", s)
+}
+
+// ParseChanges parses a list of change expressions.
+func ParseChanges(exprs []string) ([]Change, error) {
+	changes := make([]Change, 0, len(exprs))
+	for _, expr := range exprs {
+		c, err := ParseChange(expr)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// String returns the change in expression form.
+func (c Change) String() string {
+	return c.Attribute + c.Op + c.Value
+}
+
+// apply applies the change to a Cedar context record map. The existing
+// attribute type determines how the value is parsed.
+func (c Change) apply(rm cedar.RecordMap) error {
+	key := cedar.String(c.Attribute)
+	current, ok := rm[key]
+	if !ok {
+		return fmt.Errorf("unknown context attribute %q", c.Attribute)
+	}
+
+	switch v := current.(type) {
+	case cedar.Boolean:
+		if c.Op != ChangeOpSet {
+			return fmt.Errorf("attribute %q is a boolean; only %s is supported", c.Attribute, ChangeOpSet)
+		}
+		b, err := strconv.ParseBool(c.Value)
+		if err != nil {
+			return fmt.Errorf("attribute %q: %w", c.Attribute, err)
+		}
+		rm[key] = cedar.Boolean(b)
+	case cedar.Long:
+		n, err := strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("attribute %q: %w", c.Attribute, err)
+		}
+		switch c.Op {
+		case ChangeOpAdd:
+			rm[key] = v + cedar.Long(n)
+		case ChangeOpRemove:
+			rm[key] = v - cedar.Long(n)
+		default:
+			rm[key] = cedar.Long(n)
+		}
+	case cedar.String:
+		if c.Op != ChangeOpSet {
+			return fmt.Errorf("attribute %q is a string; only %s is supported", c.Attribute, ChangeOpSet)
+		}
+		rm[key] = cedar.String(c.Value)
+	case cedar.Set:
+		rm[key] = applySetChange(v, c)
+	default:
+		return fmt.Errorf("attribute %q has unsupported type %T", c.Attribute, current)
+	}
+
+	return nil
+}
+
+// applySetChange adds, removes, or replaces string members of a set.
+func applySetChange(set cedar.Set, c Change) cedar.Set {
+	var values []cedar.Value
+	switch c.Op {
+	case ChangeOpAdd:
+		values = append(set.Slice(), cedar.String(c.Value))
+	case ChangeOpRemove:
+		for v := range set.All() {
+			if !v.Equal(cedar.String(c.Value)) {
+				values = append(values, v)
+			}
+		}
+	default:
+		for _, item := range strings.Split(c.Value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, cedar.String(item))
+			}
+		}
+	}
+	return cedar.NewSet(values...)
+}
+
+// WhatIfResult describes the smallest change set that turns a denied
+// action into an allowed one.
+type WhatIfResult struct {
+	Action    string `json:"action"`
+	Principal string `json:"principal"`
+	// Allowed reports whether the action is already allowed without changes.
+	Allowed bool `json:"allowed"`
+	// Feasible reports whether some combination of candidates flips the decision.
+	Feasible bool `json:"feasible"`
+	// Truncated reports that no change set was found but larger ones, over
+	// DefaultMaxChanges, were not explored, so a fix may still exist.
+	Truncated bool     `json:"truncated,omitempty"`
+	Changes   []Change `json:"changes,omitempty"`
+	// Policies lists the policies that permit the action after the changes.
+	Policies []string `json:"policies,omitempty"`
+}
+
+// Remediation returns a human-readable description of the required changes.
+func (r *WhatIfResult) Remediation() string {
+	if r.Allowed || !r.Feasible {
+		return ""
+	}
+	parts := make([]string, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		switch c.Op {
+		case ChangeOpAdd:
+			parts = append(parts, fmt.Sprintf("add %s to %s", c.Value, c.Attribute))
+		case ChangeOpRemove:
+			parts = append(parts, fmt.Sprintf("remove %s from %s", c.Value, c.Attribute))
+		default:
+			parts = append(parts, fmt.Sprintf("set %s to %s", c.Attribute, c.Value))
+		}
+	}
+	return fmt.Sprintf("To allow %s: %s", r.Action, strings.Join(parts, "; "))
+}
+
+// WhatIf finds, for every configured principal and action, the smallest
+// subset of candidate changes that turns a deny into an allow. Change sets
+// larger than DefaultMaxChanges are not explored; results that found no
+// change set within that limit are marked Truncated.
+func (e *Engine) WhatIf(ctx *model.PolicyContext, candidates []Change) ([]*WhatIfResult, error) {
+	base := buildContextRecord(ctx).Map()
+
+	// Validate candidates up front so bad input is reported once.
+	for _, c := range candidates {
+		if err := c.apply(maps.Clone(base)); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []*WhatIfResult
+	for _, principal := range e.principals {
+		for _, action := range e.actions {
+			results = append(results, e.whatIf(ctx, principal, action, base, candidates))
+		}
+	}
+	return results, nil
+}
+
+func (e *Engine) whatIf(ctx *model.PolicyContext, principal model.Principal, action string, base cedar.RecordMap, candidates []Change) *WhatIfResult {
+	result := &WhatIfResult{
+		Action:    action,
+		Principal: principal.String(),
+	}
+
+	req := e.buildRequest(ctx, principal, action)
+	if decision, _ := cedar.Authorize(e.policySet, e.entities, req); decision == cedar.Allow {
+		result.Allowed = true
+		result.Feasible = true
+		return result
+	}
+
+	maxSize := min(len(candidates), DefaultMaxChanges)
+	for size := 1; size <= maxSize; size++ {
+		found := false
+		forEachCombination(len(candidates), size, func(idx []int) bool {
+			rm := maps.Clone(base)
+			for _, i := range idx {
+				// Candidates were validated in WhatIf.
+				_ = candidates[i].apply(rm)
+			}
+			req.Context = cedar.NewRecord(rm)
+			decision, diagnostic := cedar.Authorize(e.policySet, e.entities, req)
+			if decision != cedar.Allow {
+				return true
+			}
+			for _, i := range idx {
+				result.Changes = append(result.Changes, candidates[i])
+			}
+			for _, reason := range diagnostic.Reasons {
+				result.Policies = append(result.Policies, string(reason.PolicyID))
+			}
+			found = true
+			return false
+		})
+		if found {
+			result.Feasible = true
+			break
+		}
+	}
+	result.Truncated = !result.Feasible && len(candidates) > maxSize
+
+	return result
+}
+
+// forEachCombination calls fn with every k-sized combination of indexes in
+// [0, n), in lexicographic order, until fn returns false.
+func forEachCombination(n, k int, fn func([]int) bool) {
+	idx := make([]int, k)
+	for i := range idx {
+		idx[i] = i
+	}
+	for {
+		if !fn(idx) {
+			return
+		}
+		i := k - 1
+		for i >= 0 && idx[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
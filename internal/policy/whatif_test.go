@@ -0,0 +1,165 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+func TestParseChange(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Change
+		wantErr bool
+	}{
+		{"usesReusableWorkflow=true", Change{"usesReusableWorkflow", ChangeOpSet, "true"}, false},
+		{"goVersions+=1.25", Change{"goVersions", ChangeOpAdd, "1.25"}, false},
+		{"osMatrix-=windows-latest", Change{"osMatrix", ChangeOpRemove, "windows-latest"}, false},
+		{"noop", Change{}, true},
+		{"=true", Change{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseChange(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChange(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseChange(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.input {
+				t.Errorf("Change.String() = %q, want %q", got.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestEngineWhatIf(t *testing.T) {
+	engine := NewEngine()
+	engine.SetActions([]string{ActionBuild, ActionMerge})
+	if err := engine.AddPolicy("merge", []byte(`
+permit(principal, action == Action::"merge", resource)
+when { context.usesReusableWorkflow && context.branchProtectionEnabled };
+`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AddPolicy("build", []byte(`
+permit(principal, action == Action::"build", resource)
+when { context.goVersions.contains("1.25") };
+`)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &model.PolicyContext{
+		Repo: model.RepoContext{FullName: "org/repo"},
+		Go:   model.GoContext{Versions: []string{"1.21"}},
+	}
+	candidates, err := ParseChanges([]string{
+		"hasRenovate=true",
+		"usesReusableWorkflow=true",
+		"goVersions+=1.25",
+		"branchProtectionEnabled=true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := engine.WhatIf(ctx, candidates)
+	if err != nil {
+		t.Fatalf("WhatIf() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("WhatIf() returned %d results, want 2", len(results))
+	}
+
+	build := results[0]
+	if !build.Feasible || len(build.Changes) != 1 || build.Changes[0].String() != "goVersions+=1.25" {
+		t.Errorf("build what-if = %+v, want single goVersions+=1.25 change", build)
+	}
+
+	merge := results[1]
+	if !merge.Feasible || len(merge.Changes) != 2 {
+		t.Fatalf("merge what-if = %+v, want two changes", merge)
+	}
+	if merge.Changes[0].Attribute != "usesReusableWorkflow" || merge.Changes[1].Attribute != "branchProtectionEnabled" {
+		t.Errorf("merge changes = %v", merge.Changes)
+	}
+	if len(merge.Policies) != 1 || merge.Policies[0] != "merge" {
+		t.Errorf("merge policies = %v, want [merge]", merge.Policies)
+	}
+	if !strings.Contains(merge.Remediation(), "set usesReusableWorkflow to true") {
+		t.Errorf("Remediation() = %q", merge.Remediation())
+	}
+}
+
+func TestEngineWhatIfInfeasible(t *testing.T) {
+	engine := NewEngine()
+	engine.SetActions([]string{ActionMerge})
+	if err := engine.AddPolicy("merge", []byte(`
+permit(principal, action == Action::"merge", resource)
+when { context.lastRunPassed };
+`)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &model.PolicyContext{Repo: model.RepoContext{FullName: "org/repo"}}
+	results, err := engine.WhatIf(ctx, []Change{{Attribute: "hasWorkflow", Op: ChangeOpSet, Value: "true"}})
+	if err != nil {
+		t.Fatalf("WhatIf() error = %v", err)
+	}
+	if results[0].Feasible || results[0].Allowed || results[0].Truncated {
+		t.Errorf("WhatIf() = %+v, want infeasible", results[0])
+	}
+	if results[0].Remediation() != "" {
+		t.Errorf("Remediation() = %q, want empty", results[0].Remediation())
+	}
+}
+
+func TestEngineWhatIfTruncated(t *testing.T) {
+	engine := NewEngine()
+	engine.SetActions([]string{ActionMerge})
+	if err := engine.AddPolicy("merge", []byte(`
+permit(principal, action == Action::"merge", resource)
+when { context.hasWorkflow && context.usesReusableWorkflow && context.hasRenovate &&
+	context.branchProtectionEnabled && context.lastRunPassed };
+`)); err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := ParseChanges([]string{
+		"hasWorkflow=true",
+		"usesReusableWorkflow=true",
+		"hasRenovate=true",
+		"branchProtectionEnabled=true",
+		"lastRunPassed=true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := &model.PolicyContext{Repo: model.RepoContext{FullName: "org/repo"}}
+	results, err := engine.WhatIf(ctx, candidates)
+	if err != nil {
+		t.Fatalf("WhatIf() error = %v", err)
+	}
+	if results[0].Feasible || !results[0].Truncated {
+		t.Errorf("WhatIf() = %+v, want truncated search", results[0])
+	}
+}
+
+func TestEngineWhatIfInvalidChange(t *testing.T) {
+	engine := NewEngine()
+	ctx := &model.PolicyContext{Repo: model.RepoContext{FullName: "org/repo"}}
+
+	invalid := [][]Change{
+		{{Attribute: "doesNotExist", Op: ChangeOpSet, Value: "true"}},
+		{{Attribute: "hasWorkflow", Op: ChangeOpAdd, Value: "true"}},
+		{{Attribute: "vulnerabilityCount", Op: ChangeOpSet, Value: "many"}},
+	}
+	for _, changes := range invalid {
+		if _, err := engine.WhatIf(ctx, changes); err == nil {
+			t.Errorf("WhatIf(%v) error = nil, want error", changes)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package remediator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// Warning codes reported when converting an inline workflow.
+const (
+	// WarnStepLost marks a custom step the reusable workflow does not run.
+	WarnStepLost = "step-lost"
+	// WarnEnvLost marks an environment variable that is not passed on,
+	// since a caller's env does not reach the reusable workflow.
+	WarnEnvLost = "env-lost"
+	// WarnInputLost marks a setting the reusable workflow has no input for.
+	WarnInputLost = "input-lost"
+	// WarnTypeSkipped marks another required workflow type the file
+	// matches, since each file is converted into a single caller.
+	WarnTypeSkipped = "type-skipped"
+)
+
+// callerInputs are the inputs each reference reusable workflow accepts, in
+// the order they are written to the caller's with: block.
+var callerInputs = map[string][]string{
+	"go-ci":   {"go-versions", "platforms", "working-directory", "test-flags"},
+	"ts-ci":   {"working-directory"},
+	"ts-lint": {"working-directory"},
+}
+
+// standardSteps are the steps a reference reusable workflow runs itself:
+// the actions it uses and the run: lines matching commands.
+type standardSteps struct {
+	actions  []string
+	commands *regexp.Regexp
+}
+
+// callerSteps are the standard steps of each reference reusable workflow.
+// Steps of other types are not run by its caller.
+var callerSteps = map[string]standardSteps{
+	"go-ci": {
+		actions:  []string{"actions/checkout", "actions/setup-go", "actions/cache"},
+		commands: regexp.MustCompile(`^go (build|test|vet|mod (download|verify))(\s|$)`),
+	},
+	"go-lint": {
+		actions:  []string{"actions/checkout", "actions/setup-go", "actions/cache", "golangci/golangci-lint-action"},
+		commands: regexp.MustCompile(`^(go mod (download|verify)|gofmt -l|golangci-lint run)(\s|$)`),
+	},
+	"ts-ci": {
+		actions:  []string{"actions/checkout", "actions/setup-node", "actions/cache"},
+		commands: regexp.MustCompile(`^npm (ci|install|test|run (build|test))(\s|$)`),
+	},
+	"ts-lint": {
+		actions:  []string{"actions/checkout", "actions/setup-node", "actions/cache"},
+		commands: regexp.MustCompile(`^npm (ci|install|run lint)(\s|$)`),
+	},
+}
+
+// InlineInputs are the settings of an inline workflow that map onto the
+// reference reusable workflow's inputs.
+type InlineInputs struct {
+	GoVersions       []string // From the go-version matrix or setup-go
+	Platforms        []string // From runs-on or its os matrix
+	WorkingDirectory string   // From defaults.run or the steps
+	TestFlags        string   // Flags passed to go test
+}
+
+// with returns the caller inputs for the settings that are set. Platforms
+// are only passed when they differ from the ubuntu-latest default.
+func (in InlineInputs) with() map[string]string {
+	with := make(map[string]string)
+	if len(in.GoVersions) > 0 {
+		b, _ := json.Marshal(in.GoVersions)
+		with["go-versions"] = string(b)
+	}
+	if len(in.Platforms) > 0 && !slices.Equal(in.Platforms, []string{"ubuntu-latest"}) {
+		b, _ := json.Marshal(in.Platforms)
+		with["platforms"] = string(b)
+	}
+	if in.WorkingDirectory != "" && in.WorkingDirectory != "." {
+		with["working-directory"] = in.WorkingDirectory
+	}
+	if in.TestFlags != "" {
+		with["test-flags"] = in.TestFlags
+	}
+	return with
+}
+
+// Conversion is an inline workflow converted into a caller of a reference
+// reusable workflow.
+type Conversion struct {
+	Path         string
+	WorkflowType string
+	Uses         string
+	Inputs       InlineInputs
+	// With holds the inputs passed to the reusable workflow.
+	With map[string]string
+	// Warnings list what the caller no longer does: custom steps, env
+	// and settings the reusable workflow has no input for.
+	Warnings []model.Warning
+	Patch    *model.Patch
+}
+
+// Lossy reports whether the conversion drops custom steps.
+func (c *Conversion) Lossy() bool {
+	return c.lostSteps() > 0
+}
+
+// lostSteps returns the number of steps and jobs the caller no longer runs.
+func (c *Conversion) lostSteps() int {
+	n := 0
+	for _, w := range c.Warnings {
+		if w.Code == WarnStepLost {
+			n++
+		}
+	}
+	return n
+}
+
+// ConvertToCaller converts an inline workflow into a single job calling
+// the reusable workflow uses. The inline jobs are analyzed for the Go
+// versions, platforms, working directory and go test flags they use, and
+// those the reference workflow accepts are passed as with: inputs. The
+// first job's id and name are kept for the caller job; the triggers,
+// permissions and comments outside the jobs are kept as well. Workflow
+// env and defaults are removed since they do not reach the reusable
+// workflow.
+func ConvertToCaller(path, content, workflowType, uses string) (*Conversion, error) {
+	e, err := NewWorkflowEditor(path, content)
+	if err != nil {
+		return nil, err
+	}
+	jobsKey, jobs := mappingValue(e.root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode || len(jobs.Content) == 0 {
+		return nil, fmt.Errorf("%s has no jobs", path)
+	}
+
+	c := &Conversion{Path: path, WorkflowType: workflowType, Uses: uses}
+	c.analyze(e.root)
+	c.With = c.Inputs.with()
+	accepted := callerInputs[workflowType]
+	for _, name := range slices.Sorted(maps.Keys(c.With)) {
+		if !slices.Contains(accepted, name) {
+			c.warn(WarnInputLost, fmt.Sprintf("%s %s is not an input of %s", name, c.With[name], workflowType))
+			delete(c.With, name)
+		}
+	}
+
+	unit := strings.Repeat(" ", e.indent)
+	lines := []string{"jobs:", unit + jobs.Content[0].Value + ":"}
+	if _, name := mappingValue(jobs.Content[1], "name"); name != nil && name.Kind == yaml.ScalarNode {
+		lines = append(lines, unit+unit+"name: "+formatScalar(name.Value, name.Style))
+	}
+	lines = append(lines, unit+unit+"uses: "+formatScalar(uses, 0))
+	if len(c.With) > 0 {
+		lines = append(lines, unit+unit+"with:")
+		for _, name := range accepted {
+			if v, ok := c.With[name]; ok {
+				style := yaml.Style(0)
+				if strings.HasPrefix(v, "[") || strings.HasPrefix(v, "-") {
+					style = yaml.SingleQuotedStyle
+				}
+				lines = append(lines, unit+unit+unit+name+": "+formatScalar(v, style))
+			}
+		}
+	}
+
+	edits := []textEdit{{
+		start: e.lineStart(jobsKey.Line),
+		end:   e.lineEnd(lastLine(jobs)),
+		text:  strings.Join(lines, "\n") + "\n",
+	}}
+	for _, key := range []string{"env", "defaults"} {
+		if k, v := mappingValue(e.root, key); k != nil {
+			edits = append(edits, textEdit{start: e.lineStart(k.Line), end: e.lineEnd(lastLine(v))})
+		}
+	}
+	if err := e.apply(edits); err != nil {
+		return nil, err
+	}
+	c.Patch = e.Patch()
+	return c, nil
+}
+
+// analyze collects the inputs of the workflow's jobs and warns about what
+// a caller would lose.
+func (c *Conversion) analyze(root *yaml.Node) {
+	c.Inputs.WorkingDirectory = defaultWorkingDirectory(root)
+	c.warnEnv(root, "workflow")
+
+	_, jobs := mappingValue(root, "jobs")
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		id, job := jobs.Content[i].Value, jobs.Content[i+1]
+		if _, v := mappingValue(job, "uses"); v != nil {
+			c.warn(WarnStepLost, fmt.Sprintf("job %s calls %s", id, v.Value))
+			continue
+		}
+		if dir := defaultWorkingDirectory(job); dir != "" && c.Inputs.WorkingDirectory == "" {
+			c.Inputs.WorkingDirectory = dir
+		}
+		c.warnEnv(job, "job "+id)
+
+		_, strategy := mappingValue(job, "strategy")
+		_, matrix := mappingValue(strategy, "matrix")
+		for _, key := range []string{"go-version", "go-versions", "go"} {
+			if _, v := mappingValue(matrix, key); v != nil && v.Kind == yaml.SequenceNode && len(c.Inputs.GoVersions) == 0 {
+				c.Inputs.GoVersions = scalarValues(v)
+			}
+		}
+		if _, runsOn := mappingValue(job, "runs-on"); runsOn != nil && len(c.Inputs.Platforms) == 0 {
+			c.Inputs.Platforms = platforms(runsOn, matrix)
+		}
+
+		_, steps := mappingValue(job, "steps")
+		if steps == nil {
+			continue
+		}
+		for _, step := range steps.Content {
+			c.analyzeStep(id, step)
+		}
+	}
+}
+
+// analyzeStep collects the inputs of a step, or warns that it is custom.
+func (c *Conversion) analyzeStep(job string, step *yaml.Node) {
+	_, name := mappingValue(step, "name")
+	_, uses := mappingValue(step, "uses")
+	_, run := mappingValue(step, "run")
+	_, with := mappingValue(step, "with")
+
+	if _, dir := mappingValue(step, "working-directory"); dir != nil && c.Inputs.WorkingDirectory == "" {
+		c.Inputs.WorkingDirectory = dir.Value
+	}
+	if _, v := mappingValue(with, "go-version"); v != nil && v.Kind == yaml.ScalarNode &&
+		!strings.Contains(v.Value, "${{") && len(c.Inputs.GoVersions) == 0 {
+		c.Inputs.GoVersions = []string{v.Value}
+	}
+
+	label := ""
+	switch {
+	case name != nil:
+		label = name.Value
+	case uses != nil:
+		label = uses.Value
+	case run != nil:
+		label, _, _ = strings.Cut(strings.TrimSpace(run.Value), "\n")
+	}
+
+	steps := callerSteps[c.WorkflowType]
+	standard := true
+	switch {
+	case uses != nil:
+		action, _, _ := strings.Cut(uses.Value, "@")
+		standard = slices.Contains(steps.actions, action)
+	case run != nil:
+		for line := range strings.Lines(run.Value) {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if steps.commands == nil || !steps.commands.MatchString(line) {
+				standard = false
+				break
+			}
+			if flags, ok := testFlags(line); ok && c.Inputs.TestFlags == "" {
+				c.Inputs.TestFlags = flags
+			}
+		}
+	}
+	if !standard {
+		c.warn(WarnStepLost, fmt.Sprintf("job %s step %q is not run by the %s reusable workflow", job, label, c.WorkflowType))
+		return
+	}
+	c.warnEnv(step, fmt.Sprintf("job %s step %q", job, label))
+}
+
+// warnEnv warns about each env variable set on n.
+func (c *Conversion) warnEnv(n *yaml.Node, where string) {
+	_, env := mappingValue(n, "env")
+	if env == nil || env.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(env.Content); i += 2 {
+		c.warn(WarnEnvLost, fmt.Sprintf("%s env %s is not passed to the reusable workflow", where, env.Content[i].Value))
+	}
+}
+
+func (c *Conversion) warn(code, message string) {
+	c.Warnings = append(c.Warnings, model.Warning{Code: code, Message: message, File: c.Path})
+}
+
+// defaultWorkingDirectory returns defaults.run.working-directory of a
+// workflow or job.
+func defaultWorkingDirectory(n *yaml.Node) string {
+	_, defaults := mappingValue(n, "defaults")
+	_, run := mappingValue(defaults, "run")
+	if _, dir := mappingValue(run, "working-directory"); dir != nil {
+		return dir.Value
+	}
+	return ""
+}
+
+// platforms returns the runners of a runs-on value, expanding a matrix
+// expression such as ${{ matrix.os }}.
+func platforms(runsOn, matrix *yaml.Node) []string {
+	if runsOn.Kind != yaml.ScalarNode {
+		return nil
+	}
+	if key, ok := strings.CutPrefix(strings.TrimSpace(strings.Trim(runsOn.Value, "${}")), "matrix."); ok {
+		if _, v := mappingValue(matrix, strings.TrimSpace(key)); v != nil && v.Kind == yaml.SequenceNode {
+			return scalarValues(v)
+		}
+		return nil
+	}
+	return []string{runsOn.Value}
+}
+
+// testFlags returns the flags of a go test command line, leaving out the
+// package patterns.
+func testFlags(line string) (string, bool) {
+	args, ok := strings.CutPrefix(line, "go test")
+	if !ok {
+		return "", false
+	}
+	var flags []string
+	for _, arg := range strings.Fields(args) {
+		if strings.HasPrefix(arg, ".") || strings.Contains(arg, "/...") {
+			continue
+		}
+		flags = append(flags, arg)
+	}
+	return strings.Join(flags, " "), true
+}
+
+// ConvertEquivalent returns a plan that converts the workflows matching a
+// required workflow only equivalently into callers of the reference
+// reusable workflow given by the check's expected ref. A file matching
+// several required workflows is converted once, into the caller that
+// drops the fewest steps, with a warning for each other type. Conversions
+// that would drop custom steps are left out unless allowLoss is set. The
+// warnings of every conversion, including those left out, are returned;
+// when lossy conversions are included, their warnings are listed in the
+// pull request body for review.
+func ConvertEquivalent(ctx context.Context, files FileGetter, repo model.Repo, checks []model.WorkflowCheck, allowLoss bool) (model.RemediationPlan, []model.Warning, error) {
+	plan := model.RemediationPlan{Repo: repo}
+	var warnings, review []model.Warning
+
+	var paths []string
+	byPath := make(map[string][]model.WorkflowCheck)
+	for _, wc := range checks {
+		if !wc.Present || wc.MatchType != model.MatchTypeEquivalent || wc.ActualWorkflow == "" || wc.ExpectedRef == "" {
+			continue
+		}
+		if _, ok := byPath[wc.ActualWorkflow]; !ok {
+			paths = append(paths, wc.ActualWorkflow)
+		}
+		byPath[wc.ActualWorkflow] = append(byPath[wc.ActualWorkflow], wc)
+	}
+
+	for _, path := range paths {
+		content, err := files.GetFileContent(ctx, repo, path)
+		if err != nil {
+			return plan, warnings, fmt.Errorf("reading %s: %w", path, err)
+		}
+		var c *Conversion
+		for _, wc := range byPath[path] {
+			candidate, err := ConvertToCaller(path, content, wc.WorkflowType, wc.ExpectedRef)
+			if err != nil {
+				return plan, warnings, fmt.Errorf("converting %s: %w", path, err)
+			}
+			if c == nil || candidate.lostSteps() < c.lostSteps() {
+				c = candidate
+			}
+		}
+		for _, wc := range byPath[path] {
+			if wc.WorkflowType != c.WorkflowType {
+				c.warn(WarnTypeSkipped, fmt.Sprintf("also matches %s, but is converted to a %s caller only", wc.WorkflowType, c.WorkflowType))
+			}
+		}
+		warnings = append(warnings, c.Warnings...)
+		if c.Patch == nil || c.Lossy() && !allowLoss {
+			continue
+		}
+		plan.Patches = append(plan.Patches, *c.Patch)
+		review = append(review, c.Warnings...)
+	}
+
+	if len(plan.Patches) > 0 {
+		plan.PRTitle = "ci: call reference reusable workflows"
+		plan.PRBody = PRBody(plan)
+		if len(review) > 0 {
+			var sb strings.Builder
+			sb.WriteString(plan.PRBody)
+			sb.WriteString("\n**Review:** the reusable workflows do not cover the following.\n\n")
+			for _, w := range review {
+				sb.WriteString(fmt.Sprintf("- `%s`: %s\n", w.File, w.Message))
+			}
+			plan.PRBody = sb.String()
+		}
+	}
+	return plan, warnings, nil
+}
@@ -0,0 +1,214 @@
+package remediator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+const inlineGoCI = `name: CI # inline
+on:
+  push:
+    branches: [main]
+  pull_request:
+permissions:
+  contents: read
+env:
+  GOFLAGS: -mod=mod
+defaults:
+  run:
+    working-directory: svc
+jobs:
+  test:
+    name: Test
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest, macos-latest]
+        go-version: ['1.24.x', '1.25.x']
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version: ${{ matrix.go-version }}
+      - run: |
+          go build ./...
+          go test -race -covermode=atomic ./...
+`
+
+func TestConvertToCaller(t *testing.T) {
+	c, err := ConvertToCaller(".github/workflows/ci.yml", inlineGoCI, "go-ci", "myorg/.github/.github/workflows/go-ci.yaml@main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `name: CI # inline
+on:
+  push:
+    branches: [main]
+  pull_request:
+permissions:
+  contents: read
+jobs:
+  test:
+    name: Test
+    uses: myorg/.github/.github/workflows/go-ci.yaml@main
+    with:
+      go-versions: '["1.24.x","1.25.x"]'
+      platforms: '["ubuntu-latest","macos-latest"]'
+      working-directory: svc
+      test-flags: '-race -covermode=atomic'
+`
+	if c.Patch == nil || c.Patch.Content != want {
+		t.Fatalf("converted workflow:\n%v\nwant:\n%s", c.Patch, want)
+	}
+	if c.Patch.Diff == "" {
+		t.Error("patch has no diff")
+	}
+	if c.Lossy() {
+		t.Errorf("standard steps reported as lost: %+v", c.Warnings)
+	}
+	if len(c.Warnings) != 1 || c.Warnings[0].Code != WarnEnvLost || !strings.Contains(c.Warnings[0].Message, "GOFLAGS") {
+		t.Errorf("warnings = %+v, want GOFLAGS env lost", c.Warnings)
+	}
+}
+
+func TestConvertToCallerWarnings(t *testing.T) {
+	content := `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version: "1.25"
+      - name: Generate
+        run: go generate ./...
+      - uses: golangci/golangci-lint-action@v8
+        env:
+          GOGC: "50"
+`
+	c, err := ConvertToCaller("lint.yml", content, "go-lint", "myorg/.github/.github/workflows/go-lint.yaml@main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, w := range c.Warnings {
+		codes = append(codes, w.Code)
+	}
+	if got, want := strings.Join(codes, ","), "step-lost,env-lost,input-lost"; got != want {
+		t.Errorf("warning codes = %s, want %s: %+v", got, want, c.Warnings)
+	}
+	if !c.Lossy() || len(c.With) != 0 {
+		t.Errorf("Lossy() = %v, With = %v", c.Lossy(), c.With)
+	}
+	if !strings.HasSuffix(c.Patch.Content, "jobs:\n  lint:\n    uses: myorg/.github/.github/workflows/go-lint.yaml@main\n") {
+		t.Errorf("converted workflow:\n%s", c.Patch.Content)
+	}
+
+	if _, err := ConvertToCaller("x.yml", "on: push\n", "go-ci", "x"); err == nil {
+		t.Error("ConvertToCaller() without jobs expected error")
+	}
+}
+
+const testAndLintCI = `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: go test ./...
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: golangci/golangci-lint-action@v8
+`
+
+func TestConvertToCallerScopedSteps(t *testing.T) {
+	tests := []struct {
+		workflowType string
+		lost         string
+	}{
+		{workflowType: "go-ci", lost: "golangci/golangci-lint-action@v8"},
+		{workflowType: "go-lint", lost: "go test ./..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.workflowType, func(t *testing.T) {
+			c, err := ConvertToCaller("ci.yml", testAndLintCI, tt.workflowType, "myorg/.github/.github/workflows/"+tt.workflowType+".yaml@main")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !c.Lossy() || len(c.Warnings) != 1 || !strings.Contains(c.Warnings[0].Message, tt.lost) {
+				t.Errorf("warnings = %+v, want %q lost", c.Warnings, tt.lost)
+			}
+		})
+	}
+}
+
+func TestConvertEquivalentDuplicatePath(t *testing.T) {
+	files := fakeFiles{".github/workflows/ci.yml": testAndLintCI}
+	checks := []model.WorkflowCheck{
+		{WorkflowType: "go-ci", Present: true, MatchType: model.MatchTypeEquivalent, ActualWorkflow: ".github/workflows/ci.yml", ExpectedRef: "myorg/.github/.github/workflows/go-ci.yaml@main"},
+		{WorkflowType: "go-lint", Present: true, MatchType: model.MatchTypeEquivalent, ActualWorkflow: ".github/workflows/ci.yml", ExpectedRef: "myorg/.github/.github/workflows/go-lint.yaml@main"},
+	}
+	plan, warnings, err := ConvertEquivalent(context.Background(), files, model.Repo{FullName: "myorg/repo1"}, checks, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Patches) != 1 || !strings.Contains(plan.Patches[0].Content, "go-ci.yaml@main") {
+		t.Errorf("patches = %+v, want one go-ci caller", plan.Patches)
+	}
+	var codes []string
+	for _, w := range warnings {
+		codes = append(codes, w.Code)
+	}
+	if got, want := strings.Join(codes, ","), "step-lost,type-skipped"; got != want {
+		t.Errorf("warning codes = %s, want %s: %+v", got, want, warnings)
+	}
+}
+
+func TestConvertEquivalent(t *testing.T) {
+	files := fakeFiles{
+		".github/workflows/ci.yml":   inlineGoCI,
+		".github/workflows/lint.yml": "on: push\njobs:\n  lint:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make lint\n",
+	}
+	checks := []model.WorkflowCheck{
+		{WorkflowType: "go-ci", Present: true, MatchType: model.MatchTypeEquivalent, ActualWorkflow: ".github/workflows/ci.yml", ExpectedRef: "myorg/.github/.github/workflows/go-ci.yaml@main"},
+		{WorkflowType: "go-lint", Present: true, MatchType: model.MatchTypeEquivalent, ActualWorkflow: ".github/workflows/lint.yml", ExpectedRef: "myorg/.github/.github/workflows/go-lint.yaml@main"},
+		{WorkflowType: "go-sast-codeql", Present: true, MatchType: model.MatchTypeExact, ActualWorkflow: ".github/workflows/codeql.yml"},
+	}
+
+	tests := []struct {
+		name      string
+		allowLoss bool
+		want      []string
+	}{
+		{name: "lossy conversions skipped", want: []string{".github/workflows/ci.yml"}},
+		{name: "lossy conversions allowed", allowLoss: true, want: []string{".github/workflows/ci.yml", ".github/workflows/lint.yml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, warnings, err := ConvertEquivalent(context.Background(), files, model.Repo{FullName: "myorg/repo1"}, checks, tt.allowLoss)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var paths []string
+			for _, p := range plan.Patches {
+				paths = append(paths, p.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("patched %v, want %v", paths, tt.want)
+			}
+			if len(warnings) != 2 {
+				t.Errorf("warnings = %+v, want 2", warnings)
+			}
+			if got := strings.Contains(plan.PRBody, `"make lint"`); got != tt.allowLoss {
+				t.Errorf("PR body lists lost step = %v:\n%s", got, plan.PRBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package remediator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type diffOp struct {
+	kind  byte // ' ', '-', or '+'
+	text  string
+	aLine int // line in the old file at this op
+	bLine int // line in the new file at this op
+}
+
+// UnifiedDiff returns a unified diff between two versions of a file, or ""
+// when they are equal.
+func UnifiedDiff(path, before, after string) string {
+	if before == after {
+		return ""
+	}
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", path, path))
+
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == ' ' {
+			continue
+		}
+
+		// A hunk runs until more than two contexts' worth of unchanged
+		// lines separate it from the next change.
+		last := i
+		for k := i; k < len(ops) && k-last <= 2*diffContext; k++ {
+			if ops[k].kind != ' ' {
+				last = k
+			}
+		}
+		start := max(i-diffContext, 0)
+		end := min(last+diffContext+1, len(ops))
+
+		var aCount, bCount int
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(ops[start].aLine, aCount), hunkRange(ops[start].bLine, bCount)))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			sb.WriteByte('\n')
+		}
+		i = end - 1
+	}
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes a line diff from the longest common subsequence.
+// Workflow files are small enough that the quadratic table is cheap.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	emit := func(kind byte, text string) {
+		ops = append(ops, diffOp{kind: kind, text: text, aLine: i + 1, bLine: j + 1})
+	}
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			emit(' ', a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			emit('-', a[i])
+			i++
+		default:
+			emit('+', b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		emit('-', a[i])
+	}
+	for ; j < len(b); j++ {
+		emit('+', b[j])
+	}
+	return ops
+}
@@ -0,0 +1,588 @@
+package remediator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// callerJobKeys are the job keys GitHub allows on a job that calls a
+// reusable workflow.
+var callerJobKeys = map[string]bool{
+	"name": true, "uses": true, "with": true, "secrets": true, "needs": true,
+	"if": true, "permissions": true, "strategy": true, "concurrency": true,
+}
+
+// WorkflowEditor edits an existing workflow file while preserving its
+// comments, key order and indentation. Nodes are located with yaml.v3 and
+// replacement text is spliced into the source at their positions, so
+// lines an edit does not touch are left byte-for-byte unchanged.
+type WorkflowEditor struct {
+	path     string
+	original string
+	content  string
+	noEOL    bool       // original has no trailing newline
+	root     *yaml.Node // top-level mapping
+	indent   int        // indentation unit in spaces
+}
+
+// NewWorkflowEditor creates an editor for the workflow at path.
+func NewWorkflowEditor(path, content string) (*WorkflowEditor, error) {
+	e := &WorkflowEditor{path: path, original: content, content: content}
+	if !strings.HasSuffix(content, "\n") {
+		e.noEOL = true
+		e.content += "\n"
+	}
+	if err := e.parse(); err != nil {
+		return nil, err
+	}
+	e.indent = detectIndent(e.root)
+	return e, nil
+}
+
+// Content returns the edited workflow.
+func (e *WorkflowEditor) Content() string {
+	if e.noEOL {
+		return strings.TrimSuffix(e.content, "\n")
+	}
+	return e.content
+}
+
+// Patch returns the edits as an update patch with a unified diff, or nil
+// when nothing changed.
+func (e *WorkflowEditor) Patch() *model.Patch {
+	content := e.Content()
+	if content == e.original {
+		return nil
+	}
+	return &model.Patch{
+		Path:      e.path,
+		Operation: "update",
+		Content:   content,
+		Diff:      UnifiedDiff(e.path, e.original, content),
+	}
+}
+
+// SetGoVersions replaces every go-version list with versions, and sets
+// single go-version values to the last of them. Expressions such as
+// ${{ matrix.go-version }} are left alone. It returns the number of
+// values changed.
+func (e *WorkflowEditor) SetGoVersions(versions ...string) (int, error) {
+	if len(versions) == 0 {
+		return 0, errors.New("no Go versions given")
+	}
+
+	var edits []textEdit
+	walkPairs(e.root, func(k, v *yaml.Node) {
+		if k.Value != "go-version" {
+			return
+		}
+		switch v.Kind {
+		case yaml.SequenceNode:
+			if !slices.Equal(scalarValues(v), versions) {
+				edits = append(edits, e.replaceSequence(v, versions))
+			}
+		case yaml.ScalarNode:
+			newest := versions[len(versions)-1]
+			if v.Tag != "!!null" && v.Value != newest && !strings.Contains(v.Value, "${{") {
+				edits = append(edits, e.replaceScalar(v, newest))
+			}
+		}
+	})
+	return len(edits), e.apply(edits)
+}
+
+// UpdateRefPins changes the @ref of every uses: reference to a workflow or
+// action in refRepo. It returns the number of references changed.
+func (e *WorkflowEditor) UpdateRefPins(refRepo, ref string) (int, error) {
+	var edits []textEdit
+	walkPairs(e.root, func(k, v *yaml.Node) {
+		if k.Value != "uses" || v.Kind != yaml.ScalarNode || !strings.HasPrefix(v.Value, refRepo+"/") {
+			return
+		}
+		at := strings.LastIndex(v.Value, "@")
+		if at < 0 || v.Value[at+1:] == ref {
+			return
+		}
+		edits = append(edits, e.replaceScalar(v, v.Value[:at+1]+ref))
+	})
+	return len(edits), e.apply(edits)
+}
+
+// SetRefRepo points every uses: call to a reusable workflow in a
+// repository with the same name as refRepo, such as another owner's
+// .github repository, at refRepo and pins it to ref. It returns the number
+// of references changed.
+func (e *WorkflowEditor) SetRefRepo(refRepo, ref string) (int, error) {
+	_, name, _ := strings.Cut(refRepo, "/")
+	var edits []textEdit
+	walkPairs(e.root, func(k, v *yaml.Node) {
+		if k.Value != "uses" || v.Kind != yaml.ScalarNode {
+			return
+		}
+		parts := strings.SplitN(v.Value, "/", 3)
+		if len(parts) < 3 || parts[1] != name || !strings.HasPrefix(parts[2], ".github/workflows/") {
+			return
+		}
+		file, _, ok := strings.Cut(parts[2], "@")
+		if uses := refRepo + "/" + file + "@" + ref; ok && uses != v.Value {
+			edits = append(edits, e.replaceScalar(v, uses))
+		}
+	})
+	return len(edits), e.apply(edits)
+}
+
+// UseReusableWorkflow replaces the inline steps of job with a call to the
+// reusable workflow uses. Keys a caller job cannot have, such as runs-on
+// and steps, are removed and returned; name, needs, if, with and the other
+// caller keys are kept.
+func (e *WorkflowEditor) UseReusableWorkflow(job, uses string) ([]string, error) {
+	_, jobs := mappingValue(e.root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s has no jobs", e.path)
+	}
+	_, jv := mappingValue(jobs, job)
+	if jv == nil {
+		return nil, fmt.Errorf("%s has no job %q", e.path, job)
+	}
+	if jv.Kind != yaml.MappingNode || jv.Style&yaml.FlowStyle != 0 || len(jv.Content) == 0 {
+		return nil, fmt.Errorf("job %q in %s is not a block mapping", job, e.path)
+	}
+
+	if _, v := mappingValue(jv, "uses"); v != nil {
+		if v.Value == uses {
+			return nil, nil
+		}
+		return nil, e.apply([]textEdit{e.replaceScalar(v, uses)})
+	}
+
+	usesLine := indentOf(jv) + "uses: " + formatScalar(uses, 0) + "\n"
+	var removed []string
+	var edits []textEdit
+	for i := 0; i+1 < len(jv.Content); i += 2 {
+		k, v := jv.Content[i], jv.Content[i+1]
+		if callerJobKeys[k.Value] {
+			continue
+		}
+		edit := textEdit{start: e.lineStart(k.Line), end: e.lineEnd(lastLine(v))}
+		// The call takes the place of the first removed key.
+		if len(removed) == 0 {
+			edit.text = usesLine
+		}
+		removed = append(removed, k.Value)
+		edits = append(edits, edit)
+	}
+	if len(removed) == 0 {
+		end := e.lineEnd(lastLine(jv))
+		edits = append(edits, textEdit{start: end, end: end, text: usesLine})
+	}
+	return removed, e.apply(edits)
+}
+
+// AddTriggers adds events missing from the workflow's on: triggers.
+func (e *WorkflowEditor) AddTriggers(events ...string) error {
+	_, on := mappingValue(e.root, "on")
+	if on == nil {
+		return fmt.Errorf("%s has no on: triggers", e.path)
+	}
+	existing := triggerEvents(on)
+	var add []string
+	for _, ev := range events {
+		if !slices.Contains(existing, ev) {
+			add = append(add, ev)
+		}
+	}
+	if len(add) == 0 {
+		return nil
+	}
+
+	switch {
+	case on.Kind == yaml.ScalarNode:
+		return e.apply([]textEdit{e.replaceWith(on, "["+strings.Join(append(existing, add...), ", ")+"]")})
+	case on.Kind == yaml.SequenceNode:
+		return e.apply([]textEdit{e.replaceSequence(on, append(existing, add...))})
+	case on.Kind == yaml.MappingNode && on.Style&yaml.FlowStyle == 0:
+		var lines []string
+		for _, ev := range add {
+			lines = append(lines, indentOf(on)+ev+":")
+		}
+		return e.apply([]textEdit{e.insertAfter(lastLine(on), lines)})
+	}
+	return fmt.Errorf("unsupported on: syntax in %s", e.path)
+}
+
+// AddPathFilters adds paths to the path filters of the push and
+// pull_request triggers. Events with paths-ignore are left alone, since
+// the two cannot be combined. A list of events is converted to a mapping
+// first.
+func (e *WorkflowEditor) AddPathFilters(paths ...string) error {
+	onKey, on := mappingValue(e.root, "on")
+	if on == nil {
+		return fmt.Errorf("%s has no on: triggers", e.path)
+	}
+	if on.Kind != yaml.MappingNode {
+		var lines []string
+		for _, ev := range triggerEvents(on) {
+			lines = append(lines, strings.Repeat(" ", onKey.Column-1+e.indent)+ev+":")
+		}
+		start := e.afterKey(onKey)
+		end := e.lineEnd(lastLine(on)) - 1
+		if err := e.apply([]textEdit{{start: start, end: end, text: "\n" + strings.Join(lines, "\n")}}); err != nil {
+			return err
+		}
+		_, on = mappingValue(e.root, "on")
+	}
+	if on.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("unsupported on: syntax in %s", e.path)
+	}
+
+	var edits []textEdit
+	for _, event := range []string{"push", "pull_request"} {
+		ek, ev := mappingValue(on, event)
+		if ev == nil {
+			continue
+		}
+		childIndent := strings.Repeat(" ", ek.Column-1+e.indent)
+		itemIndent := childIndent + strings.Repeat(" ", e.indent)
+
+		switch {
+		case ev.Kind == yaml.ScalarNode && ev.Tag == "!!null":
+			lines := append([]string{childIndent + "paths:"}, pathItems(itemIndent, paths, yaml.DoubleQuotedStyle)...)
+			edits = append(edits, textEdit{
+				start: e.afterKey(ek),
+				end:   e.lineEnd(ek.Line) - 1,
+				text:  "\n" + strings.Join(lines, "\n"),
+			})
+		case ev.Kind == yaml.MappingNode && ev.Style&yaml.FlowStyle == 0:
+			if _, ignore := mappingValue(ev, "paths-ignore"); ignore != nil {
+				continue
+			}
+			_, pv := mappingValue(ev, "paths")
+			if pv == nil {
+				lines := append([]string{indentOf(ev) + "paths:"}, pathItems(indentOf(ev)+strings.Repeat(" ", e.indent), paths, yaml.DoubleQuotedStyle)...)
+				edits = append(edits, e.insertAfter(lastLine(ev), lines))
+				continue
+			}
+			if pv.Kind != yaml.SequenceNode {
+				continue
+			}
+			current := scalarValues(pv)
+			var add []string
+			for _, p := range paths {
+				if !slices.Contains(current, p) {
+					add = append(add, p)
+				}
+			}
+			switch {
+			case len(add) == 0:
+			case pv.Style&yaml.FlowStyle != 0 || len(pv.Content) == 0:
+				edits = append(edits, e.replaceSequence(pv, append(current, add...)))
+			default:
+				first := pv.Content[0]
+				prefix := e.content[e.lineStart(first.Line):e.offset(first.Line, first.Column)]
+				var lines []string
+				for _, p := range add {
+					lines = append(lines, prefix+formatScalar(p, first.Style))
+				}
+				edits = append(edits, e.insertAfter(lastLine(pv), lines))
+			}
+		}
+	}
+	return e.apply(edits)
+}
+
+// SetBranches replaces the branches lists of the push and pull_request
+// triggers with branches. Events without a branches list run on every
+// branch and are left alone. It returns the number of lists changed.
+func (e *WorkflowEditor) SetBranches(branches ...string) (int, error) {
+	if len(branches) == 0 {
+		return 0, errors.New("no branches given")
+	}
+	_, on := mappingValue(e.root, "on")
+	if on == nil || on.Kind != yaml.MappingNode {
+		return 0, nil
+	}
+
+	var edits []textEdit
+	for _, event := range []string{"push", "pull_request"} {
+		_, ev := mappingValue(on, event)
+		if ev == nil || ev.Kind != yaml.MappingNode {
+			continue
+		}
+		_, bv := mappingValue(ev, "branches")
+		if bv != nil && bv.Kind == yaml.SequenceNode && !slices.Equal(scalarValues(bv), branches) {
+			edits = append(edits, e.replaceSequence(bv, branches))
+		}
+	}
+	return len(edits), e.apply(edits)
+}
+
+func pathItems(indent string, paths []string, style yaml.Style) []string {
+	lines := make([]string, len(paths))
+	for i, p := range paths {
+		lines[i] = indent + "- " + formatScalar(p, style)
+	}
+	return lines
+}
+
+// textEdit replaces content[start:end] with text.
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// apply applies non-overlapping edits and re-parses the result.
+func (e *WorkflowEditor) apply(edits []textEdit) error {
+	if len(edits) == 0 {
+		return nil
+	}
+	slices.SortFunc(edits, func(a, b textEdit) int { return b.start - a.start })
+
+	prev := e.content
+	for _, ed := range edits {
+		e.content = e.content[:ed.start] + ed.text + e.content[ed.end:]
+	}
+	if err := e.parse(); err != nil {
+		e.content = prev
+		_ = e.parse()
+		return fmt.Errorf("editing %s: %w", e.path, err)
+	}
+	return nil
+}
+
+func (e *WorkflowEditor) parse() error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(e.content), &doc); err != nil {
+		return fmt.Errorf("parsing %s: %w", e.path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("parsing %s: not a YAML mapping", e.path)
+	}
+	e.root = doc.Content[0]
+	return nil
+}
+
+// lineStart returns the offset of the start of a 1-based line.
+func (e *WorkflowEditor) lineStart(line int) int {
+	off := 0
+	for l := 1; l < line; l++ {
+		i := strings.IndexByte(e.content[off:], '\n')
+		if i < 0 {
+			return len(e.content)
+		}
+		off += i + 1
+	}
+	return off
+}
+
+// lineEnd returns the offset just past the newline ending a 1-based line.
+func (e *WorkflowEditor) lineEnd(line int) int {
+	off := e.lineStart(line)
+	if i := strings.IndexByte(e.content[off:], '\n'); i >= 0 {
+		return off + i + 1
+	}
+	return len(e.content)
+}
+
+// offset returns the offset of a 1-based line and column, counting
+// columns in characters as yaml.v3 does.
+func (e *WorkflowEditor) offset(line, col int) int {
+	off := e.lineStart(line)
+	for i := 1; i < col && off < len(e.content); i++ {
+		_, size := utf8.DecodeRuneInString(e.content[off:])
+		off += size
+	}
+	return off
+}
+
+// afterKey returns the offset just past a mapping key's colon.
+func (e *WorkflowEditor) afterKey(k *yaml.Node) int {
+	off := e.offset(k.Line, k.Column)
+	return off + strings.IndexByte(e.content[off:], ':') + 1
+}
+
+// scalarEnd returns the offset just past a single-line scalar token.
+func (e *WorkflowEditor) scalarEnd(n *yaml.Node) int {
+	start := e.offset(n.Line, n.Column)
+	s := e.content[start:]
+	switch {
+	case n.Style&yaml.DoubleQuotedStyle != 0:
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				return start + i + 1
+			}
+		}
+	case n.Style&yaml.SingleQuotedStyle != 0:
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\'' {
+				if i+1 < len(s) && s[i+1] == '\'' {
+					i++
+					continue
+				}
+				return start + i + 1
+			}
+		}
+	default:
+		return start + len(n.Value)
+	}
+	return len(e.content)
+}
+
+// replaceScalar replaces a scalar, keeping its quoting style.
+func (e *WorkflowEditor) replaceScalar(n *yaml.Node, value string) textEdit {
+	return e.replaceWith(n, formatScalar(value, n.Style))
+}
+
+func (e *WorkflowEditor) replaceWith(n *yaml.Node, text string) textEdit {
+	return textEdit{start: e.offset(n.Line, n.Column), end: e.scalarEnd(n), text: text}
+}
+
+// replaceSequence replaces a sequence's items, keeping its flow or block
+// style, indentation and the quoting style of its first item.
+func (e *WorkflowEditor) replaceSequence(seq *yaml.Node, values []string) textEdit {
+	style := yaml.Style(0)
+	if len(seq.Content) > 0 {
+		style = seq.Content[0].Style
+	}
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = formatScalar(v, style)
+	}
+
+	if seq.Style&yaml.FlowStyle != 0 || len(seq.Content) == 0 {
+		start := e.offset(seq.Line, seq.Column)
+		end := start + strings.IndexByte(e.content[start:], ']') + 1
+		pad := ""
+		if strings.HasPrefix(e.content[start:], "[ ") {
+			pad = " "
+		}
+		return textEdit{start: start, end: end, text: "[" + pad + strings.Join(items, ", ") + pad + "]"}
+	}
+
+	first := seq.Content[0]
+	start := e.lineStart(first.Line)
+	prefix := e.content[start:e.offset(first.Line, first.Column)]
+	var sb strings.Builder
+	for _, item := range items {
+		sb.WriteString(prefix + item + "\n")
+	}
+	return textEdit{start: start, end: e.lineEnd(lastLine(seq)), text: sb.String()}
+}
+
+// insertAfter inserts lines after a 1-based line.
+func (e *WorkflowEditor) insertAfter(line int, lines []string) textEdit {
+	off := e.lineEnd(line)
+	return textEdit{start: off, end: off, text: strings.Join(lines, "\n") + "\n"}
+}
+
+// formatScalar renders a scalar in the given quoting style. Plain values
+// that would not read back as the same string, such as 1.20 or **.go, are
+// double-quoted.
+func formatScalar(value string, style yaml.Style) string {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		return strconv.Quote(value)
+	case style&yaml.SingleQuotedStyle != 0:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	var v any
+	if err := yaml.Unmarshal([]byte(value), &v); err == nil {
+		if s, ok := v.(string); ok && s == value {
+			return value
+		}
+	}
+	return strconv.Quote(value)
+}
+
+// lastLine returns the last source line of a node.
+func lastLine(n *yaml.Node) int {
+	switch n.Kind {
+	case yaml.MappingNode, yaml.SequenceNode, yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return n.Line
+		}
+		return lastLine(n.Content[len(n.Content)-1])
+	case yaml.ScalarNode:
+		if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			// Block scalar content starts on the line after the indicator.
+			return n.Line + strings.Count(strings.TrimRight(n.Value, "\n"), "\n") + 1
+		}
+	}
+	return n.Line
+}
+
+// indentOf returns the indentation of a block collection's entries.
+func indentOf(n *yaml.Node) string {
+	return strings.Repeat(" ", n.Content[0].Column-1)
+}
+
+// detectIndent returns the indentation unit of a document, defaulting to 2.
+func detectIndent(root *yaml.Node) int {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		if v.Kind == yaml.MappingNode && len(v.Content) > 0 && v.Content[0].Column > k.Column {
+			return v.Content[0].Column - k.Column
+		}
+	}
+	return 2
+}
+
+// mappingValue returns the key and value nodes for key in a mapping.
+func mappingValue(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// walkPairs calls fn for every key/value pair in the tree.
+func walkPairs(n *yaml.Node, fn func(k, v *yaml.Node)) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			fn(n.Content[i], n.Content[i+1])
+		}
+	}
+	for _, c := range n.Content {
+		walkPairs(c, fn)
+	}
+}
+
+func scalarValues(seq *yaml.Node) []string {
+	var values []string
+	for _, c := range seq.Content {
+		values = append(values, c.Value)
+	}
+	return values
+}
+
+// triggerEvents returns the event names of an on: value.
+func triggerEvents(on *yaml.Node) []string {
+	switch on.Kind {
+	case yaml.ScalarNode:
+		return []string{on.Value}
+	case yaml.SequenceNode:
+		return scalarValues(on)
+	case yaml.MappingNode:
+		var events []string
+		for i := 0; i < len(on.Content); i += 2 {
+			events = append(events, on.Content[i].Value)
+		}
+		return events
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package remediator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const inlineWorkflow = `# Build and test on every change.
+name: Go CI
+
+on:
+    push:
+        branches: [main]
+    pull_request: # run on PRs too
+
+jobs:
+    test:
+        name: Test
+        runs-on: ${{ matrix.os }}
+        strategy:
+            matrix:
+                os: [ubuntu-latest]
+                go-version:
+                    - "1.21"   # oldest supported
+                    - "1.22"
+        steps:
+            - uses: actions/checkout@v4
+            - uses: actions/setup-go@v5
+              with:
+                  go-version: ${{ matrix.go-version }}
+            - run: |
+                  go build ./...
+                  go test ./...
+    lint:
+        uses: testorg/.github/.github/workflows/go-lint.yaml@v1
+`
+
+func TestWorkflowEditorSetGoVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		versions []string
+		want     string
+		changed  int
+	}{
+		{
+			name:     "block list keeps indentation and quoting",
+			content:  "jobs:\n  t:\n    strategy:\n      matrix:\n        go-version:\n          - '1.21' # old\n          - '1.22'\n    steps: []\n",
+			versions: []string{"1.23", "1.24"},
+			want:     "jobs:\n  t:\n    strategy:\n      matrix:\n        go-version:\n          - '1.23'\n          - '1.24'\n    steps: []\n",
+			changed:  1,
+		},
+		{
+			name:     "flow list",
+			content:  "jobs:\n  t:\n    strategy:\n      matrix:\n        go-version: [ 1.21, 1.22 ] # versions\n",
+			versions: []string{"1.20", "1.24"},
+			want:     "jobs:\n  t:\n    strategy:\n      matrix:\n        go-version: [ \"1.20\", \"1.24\" ] # versions\n",
+			changed:  1,
+		},
+		{
+			name:     "scalar set to newest, expressions untouched",
+			content:  "jobs:\n  t:\n    steps:\n      - with:\n          go-version: \"1.21\"\n      - with:\n          go-version: ${{ matrix.go }}\n",
+			versions: []string{"1.23", "1.24"},
+			want:     "jobs:\n  t:\n    steps:\n      - with:\n          go-version: \"1.24\"\n      - with:\n          go-version: ${{ matrix.go }}\n",
+			changed:  1,
+		},
+		{
+			name:     "already current",
+			content:  "jobs:\n  t:\n    strategy:\n      matrix:\n        go-version: ['1.23', '1.24']",
+			versions: []string{"1.23", "1.24"},
+			want:     "jobs:\n  t:\n    strategy:\n      matrix:\n        go-version: ['1.23', '1.24']",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewWorkflowEditor("ci.yaml", tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			changed, err := e.SetGoVersions(tt.versions...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.changed {
+				t.Errorf("changed = %d, want %d", changed, tt.changed)
+			}
+			if got := e.Content(); got != tt.want {
+				t.Errorf("content =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowEditorUseReusableWorkflow(t *testing.T) {
+	e, err := NewWorkflowEditor(".github/workflows/ci.yaml", inlineWorkflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := e.UseReusableWorkflow("test", "testorg/.github/.github/workflows/go-ci.yaml@main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(removed, []string{"runs-on", "steps"}) {
+		t.Errorf("removed = %v", removed)
+	}
+
+	want := `# Build and test on every change.
+name: Go CI
+
+on:
+    push:
+        branches: [main]
+    pull_request: # run on PRs too
+
+jobs:
+    test:
+        name: Test
+        uses: testorg/.github/.github/workflows/go-ci.yaml@main
+        strategy:
+            matrix:
+                os: [ubuntu-latest]
+                go-version:
+                    - "1.21"   # oldest supported
+                    - "1.22"
+    lint:
+        uses: testorg/.github/.github/workflows/go-lint.yaml@v1
+`
+	if got := e.Content(); got != want {
+		t.Errorf("content =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := e.UseReusableWorkflow("missing", "x"); err == nil {
+		t.Error("expected error for missing job")
+	}
+}
+
+func TestWorkflowEditorUpdateRefPins(t *testing.T) {
+	e, err := NewWorkflowEditor("ci.yaml", inlineWorkflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := e.UpdateRefPins("testorg/.github", "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 1 {
+		t.Errorf("changed = %d, want 1", changed)
+	}
+	if !strings.Contains(e.Content(), "uses: testorg/.github/.github/workflows/go-lint.yaml@v2\n") {
+		t.Errorf("ref not updated:\n%s", e.Content())
+	}
+	if !strings.Contains(e.Content(), "actions/checkout@v4") {
+		t.Error("unrelated action ref changed")
+	}
+}
+
+func TestWorkflowEditorSetRefRepo(t *testing.T) {
+	content := `jobs:
+  ci:
+    uses: grokify/.github/.github/workflows/go-ci.yaml@main
+  local:
+    uses: ./.github/workflows/build.yaml
+  other:
+    uses: grokify/tools/.github/workflows/release.yaml@main
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`
+	e, err := NewWorkflowEditor("ci.yaml", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := e.SetRefRepo("acme/.github", "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(content, "grokify/.github/.github/workflows/go-ci.yaml@main", "acme/.github/.github/workflows/go-ci.yaml@v2", 1)
+	if changed != 1 || e.Content() != want {
+		t.Errorf("changed = %d, content =\n%s\nwant\n%s", changed, e.Content(), want)
+	}
+}
+
+func TestWorkflowEditorSetBranches(t *testing.T) {
+	e, err := NewWorkflowEditor("ci.yaml", "on:\n  push:\n    branches:\n      - main\n  pull_request:\n    branches: [main]\n  schedule:\n    - cron: '0 0 * * *'\njobs: {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := e.SetBranches("master", "release/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "on:\n  push:\n    branches:\n      - master\n      - release/*\n  pull_request:\n    branches: [master, release/*]\n  schedule:\n    - cron: '0 0 * * *'\njobs: {}\n"
+	if changed != 2 || e.Content() != want {
+		t.Errorf("changed = %d, content =\n%s\nwant\n%s", changed, e.Content(), want)
+	}
+}
+
+func TestWorkflowEditorTriggers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		events  []string
+		paths   []string
+		want    string
+	}{
+		{
+			name:    "mapping",
+			content: "on:\n  push:\n    branches: [main]\n    paths:\n      - \"**.go\"\n  pull_request:\njobs: {}\n",
+			events:  []string{"push", "pull_request", "workflow_dispatch"},
+			paths:   []string{"**.go", "go.mod"},
+			want:    "on:\n  push:\n    branches: [main]\n    paths:\n      - \"**.go\"\n      - \"go.mod\"\n  pull_request:\n    paths:\n      - \"**.go\"\n      - \"go.mod\"\n  workflow_dispatch:\njobs: {}\n",
+		},
+		{
+			name:    "list converted to mapping",
+			content: "on: [push] # events\njobs: {}\n",
+			events:  []string{"pull_request"},
+			paths:   []string{"go.mod"},
+			want:    "on:\n  push:\n    paths:\n      - \"go.mod\"\n  pull_request:\n    paths:\n      - \"go.mod\"\njobs: {}\n",
+		},
+		{
+			name:    "scalar",
+			content: "on: push\njobs: {}\n",
+			events:  []string{"pull_request"},
+			want:    "on: [push, pull_request]\njobs: {}\n",
+		},
+		{
+			name:    "paths-ignore left alone",
+			content: "on:\n  push:\n    paths-ignore: [docs/**]\njobs: {}\n",
+			paths:   []string{"go.mod"},
+			want:    "on:\n  push:\n    paths-ignore: [docs/**]\njobs: {}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewWorkflowEditor("ci.yaml", tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := e.AddTriggers(tt.events...); err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.paths) > 0 {
+				if err := e.AddPathFilters(tt.paths...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := e.Content(); got != tt.want {
+				t.Errorf("content =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowEditorPatch(t *testing.T) {
+	e, err := NewWorkflowEditor(".github/workflows/ci.yaml", inlineWorkflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Patch() != nil {
+		t.Fatal("Patch() before edits should be nil")
+	}
+	if _, err := e.UpdateRefPins("testorg/.github", "v2"); err != nil {
+		t.Fatal(err)
+	}
+
+	patch := e.Patch()
+	if patch == nil || patch.Operation != "update" || patch.Path != ".github/workflows/ci.yaml" {
+		t.Fatalf("patch = %+v", patch)
+	}
+	wantDiff := `--- a/.github/workflows/ci.yaml
++++ b/.github/workflows/ci.yaml
+@@ -25,4 +25,4 @@
+                   go build ./...
+                   go test ./...
+     lint:
+-        uses: testorg/.github/.github/workflows/go-lint.yaml@v1
++        uses: testorg/.github/.github/workflows/go-lint.yaml@v2
+`
+	if patch.Diff != wantDiff {
+		t.Errorf("diff =\n%s\nwant\n%s", patch.Diff, wantDiff)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	want := `--- a/f
++++ b/f
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -10,3 +10,4 @@
+ j
+ k
+ l
++m
+`
+	if got := UnifiedDiff("f", before, after); got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("f", before, before); got != "" {
+		t.Errorf("UnifiedDiff(equal) = %q", got)
+	}
+}
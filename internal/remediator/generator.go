@@ -4,7 +4,10 @@ package remediator
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -19,6 +22,19 @@ type WorkflowTemplate struct {
 	Type        string
 	Template    string
 	Description string
+	// Variables lists TemplateData fields that must be non-empty to
+	// render the template.
+	Variables []string
+	// LeftDelim and RightDelim override the "{{" and "}}" delimiters, for
+	// templates that use GitHub expressions.
+	LeftDelim  string
+	RightDelim string
+	// Paths are the source path filters of the template's triggers,
+	// relative to the project directory.
+	Paths []string
+	// Raw templates are used as-is, apart from the $default-branch
+	// placeholder, the trigger branches and PathFilters.
+	Raw bool
 }
 
 // GeneratorConfig configures the workflow generator.
@@ -29,6 +45,12 @@ type GeneratorConfig struct {
 	Verbose     bool
 	OutputDir   string   // Optional: override output directory
 	PathFilters []string // Optional: path filters for workflows
+	// ReleaseBranches are protected release branch patterns, such as
+	// "release/*", that trigger workflows alongside the default branch.
+	ReleaseBranches []string
+	// Overwrite replaces existing workflow files with the rendered
+	// template. Otherwise they are edited in place.
+	Overwrite bool
 }
 
 // Generator generates compliant workflow files.
@@ -50,10 +72,20 @@ func NewGenerator(cfg GeneratorConfig) *Generator {
 
 	// Register built-in templates
 	g.registerGoTemplates()
+	g.registerTSTemplates()
 
 	return g
 }
 
+// Register adds templates, replacing any registered for the same type.
+// Use it with LoadTemplatesDir or LoadRefRepoTemplates to supply
+// templates beyond the built-in ones.
+func (g *Generator) Register(templates ...*WorkflowTemplate) {
+	for _, t := range templates {
+		g.Templates[t.Type] = t
+	}
+}
+
 // registerGoTemplates registers Go workflow templates.
 func (g *Generator) registerGoTemplates() {
 	g.Templates["go-ci"] = &WorkflowTemplate{
@@ -63,6 +95,7 @@ func (g *Generator) registerGoTemplates() {
 		Type:        "go-ci",
 		Description: "Go CI pipeline with build, test, and coverage",
 		Template:    goCI,
+		Paths:       []string{"**.go", "go.mod", "go.sum"},
 	}
 
 	g.Templates["go-lint"] = &WorkflowTemplate{
@@ -72,6 +105,7 @@ func (g *Generator) registerGoTemplates() {
 		Type:        "go-lint",
 		Description: "Go linting with golangci-lint",
 		Template:    goLint,
+		Paths:       []string{"**.go", "go.mod", "go.sum", ".golangci.yml", ".golangci.yaml"},
 	}
 
 	g.Templates["go-sast-codeql"] = &WorkflowTemplate{
@@ -84,13 +118,48 @@ func (g *Generator) registerGoTemplates() {
 	}
 }
 
+// registerTSTemplates registers TypeScript workflow templates.
+func (g *Generator) registerTSTemplates() {
+	g.Templates["ts-ci"] = &WorkflowTemplate{
+		Name:        "TypeScript CI",
+		Filename:    "ts-ci.yaml",
+		Language:    "TypeScript",
+		Type:        "ts-ci",
+		Description: "TypeScript CI pipeline with build and test",
+		Template:    tsCI,
+		Paths:       []string{"**.ts", "**.tsx", "package.json", "package-lock.json"},
+	}
+
+	g.Templates["ts-lint"] = &WorkflowTemplate{
+		Name:        "TypeScript Lint",
+		Filename:    "ts-lint.yaml",
+		Language:    "TypeScript",
+		Type:        "ts-lint",
+		Description: "TypeScript linting with ESLint",
+		Template:    tsLint,
+		Paths:       []string{"**.ts", "**.tsx", "package.json", "package-lock.json", ".eslintrc.*"},
+	}
+}
+
 // TemplateData contains data for template rendering.
 type TemplateData struct {
-	RefRepo     string
-	RefBranch   string
-	RepoName    string
-	RepoOwner   string
-	PathFilters []string
+	RefRepo         string
+	RefBranch       string
+	RepoName        string
+	RepoOwner       string
+	PathFilters     []string
+	DefaultBranch   string   // The repository's default branch; "main" if unknown
+	ReleaseBranches []string // Protected release branch patterns
+	// Branches are the branches that trigger the workflow: the default
+	// branch followed by the release branches.
+	Branches  []string
+	GoVersion string // The go directive of the repository's go.mod
+	// WorkingDirectory is the subfolder holding the template language's
+	// project in a monorepo, or empty when it is at the root.
+	WorkingDirectory string
+	// Paths are the trigger path filters: the template's source paths
+	// under WorkingDirectory, the workflow file itself, and PathFilters.
+	Paths []string
 }
 
 // GenerateForRepo generates missing workflow files for a repository.
@@ -111,14 +180,6 @@ func (g *Generator) GenerateForRepo(repo model.Repo, missing []model.MissingWork
 		}
 	}
 
-	data := TemplateData{
-		RefRepo:     g.Config.RefRepo,
-		RefBranch:   g.Config.RefBranch,
-		RepoName:    repo.Name,
-		RepoOwner:   repo.Owner,
-		PathFilters: g.Config.PathFilters,
-	}
-
 	for _, m := range missing {
 		tmpl, ok := g.Templates[m.WorkflowType]
 		if !ok {
@@ -128,6 +189,14 @@ func (g *Generator) GenerateForRepo(repo model.Repo, missing []model.MissingWork
 			continue
 		}
 
+		data := g.templateData(repo, tmpl)
+		if vars := tmpl.missingVariables(data); len(vars) > 0 {
+			if g.Config.Verbose {
+				fmt.Fprintf(os.Stderr, "Warning: skipping %s for %s: no value for %s\n", tmpl.Name, repo.FullName, strings.Join(vars, ", "))
+			}
+			continue
+		}
+
 		content, err := g.renderTemplate(tmpl, data)
 		if err != nil {
 			return nil, fmt.Errorf("rendering template %s: %w", tmpl.Name, err)
@@ -144,12 +213,24 @@ func (g *Generator) GenerateForRepo(repo model.Repo, missing []model.MissingWork
 		}
 
 		// Check if file already exists
-		if _, err := os.Stat(outputPath); err == nil {
+		if existing, err := os.ReadFile(outputPath); err == nil {
 			gf.IsNew = false
 			gf.WouldOverwrite = true
+			gf.Previous = string(existing)
+			if g.Config.Overwrite {
+				gf.Update = replaceExisting(gf.RelativePath, gf.Previous, content)
+			} else {
+				gf.Update = g.updateExisting(gf.RelativePath, gf.Previous)
+			}
 		}
 
-		if !g.Config.DryRun {
+		// Existing files only receive their in-place update, if any, so
+		// customizations are kept.
+		write := gf.IsNew || gf.Update != nil
+		if !g.Config.DryRun && write {
+			if gf.Update != nil {
+				content = gf.Update.Content
+			}
 			// Workflow files should be world-readable (0644)
 			if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil { //nolint:gosec // G306: workflow files need to be readable
 				return nil, fmt.Errorf("writing %s: %w", outputPath, err)
@@ -170,11 +251,99 @@ type GeneratedFile struct {
 	WorkflowType   string
 	IsNew          bool
 	WouldOverwrite bool
+	// Previous is the existing file's content when IsNew is false.
+	Previous string
+	// Update is the change made to an existing file: its reference
+	// workflow calls re-pinned to RefBranch in place, or the whole
+	// template with Overwrite. It is nil when the file needs no change.
+	Update *model.Patch
+}
+
+// updateExisting returns an in-place edit of an existing workflow that
+// pins its calls into the reference repository to RefBranch.
+func (g *Generator) updateExisting(relPath, content string) *model.Patch {
+	e, err := NewWorkflowEditor(relPath, content)
+	if err != nil {
+		return nil
+	}
+	if _, err := e.UpdateRefPins(g.Config.RefRepo, g.Config.RefBranch); err != nil {
+		return nil
+	}
+	return e.Patch()
+}
+
+// replaceExisting returns a patch replacing an existing file with content,
+// or nil when they are the same.
+func replaceExisting(relPath, previous, content string) *model.Patch {
+	if previous == content {
+		return nil
+	}
+	return &model.Patch{
+		Path:      relPath,
+		Operation: "update",
+		Content:   content,
+		Diff:      UnifiedDiff(relPath, previous, content),
+	}
+}
+
+// templateData returns the template data for rendering a template for a
+// repository.
+func (g *Generator) templateData(repo model.Repo, wt *WorkflowTemplate) TemplateData {
+	data := TemplateData{
+		RefRepo:         g.Config.RefRepo,
+		RefBranch:       g.Config.RefBranch,
+		RepoName:        repo.Name,
+		RepoOwner:       repo.Owner,
+		PathFilters:     g.Config.PathFilters,
+		DefaultBranch:   repo.DefaultBranch,
+		ReleaseBranches: g.Config.ReleaseBranches,
+	}
+	if data.DefaultBranch == "" {
+		data.DefaultBranch = "main"
+	}
+	data.Branches = appendUnique([]string{data.DefaultBranch}, data.ReleaseBranches...)
+	data.WorkingDirectory = projectDir(repo.LocalPath, wt.Language)
+	data.GoVersion = goVersion(filepath.Join(repo.LocalPath, filepath.FromSlash(data.WorkingDirectory), "go.mod"))
+
+	for _, p := range wt.Paths {
+		data.Paths = appendUnique(data.Paths, path.Join(data.WorkingDirectory, p))
+	}
+	if len(data.Paths) > 0 {
+		data.Paths = appendUnique(data.Paths, ".github/workflows/"+wt.Filename)
+	}
+	data.Paths = appendUnique(data.Paths, data.PathFilters...)
+	return data
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// templateFuncs are available to workflow templates.
+var templateFuncs = template.FuncMap{
+	// flow formats a list as a YAML flow sequence, such as [main, develop].
+	"flow": func(values []string) string {
+		items := make([]string, len(values))
+		for i, v := range values {
+			items[i] = formatScalar(v, 0)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	},
+	// quote formats a value as a double-quoted YAML string.
+	"quote": strconv.Quote,
 }
 
 // renderTemplate renders a workflow template with the given data.
 func (g *Generator) renderTemplate(wt *WorkflowTemplate, data TemplateData) (string, error) {
-	tmpl, err := template.New(wt.Name).Parse(wt.Template)
+	if wt.Raw {
+		return renderRaw(wt, data)
+	}
+	tmpl, err := template.New(wt.Name).Delims(wt.LeftDelim, wt.RightDelim).Funcs(templateFuncs).Parse(wt.Template)
 	if err != nil {
 		return "", fmt.Errorf("parsing template: %w", err)
 	}
@@ -187,6 +356,34 @@ func (g *Generator) renderTemplate(wt *WorkflowTemplate, data TemplateData) (str
 	return buf.String(), nil
 }
 
+// renderRaw replaces the $default-branch placeholder of a raw template,
+// points its reusable workflow calls at RefRepo and RefBranch, sets the
+// branches of its push and pull_request triggers to the default and
+// release branches, and adds the configured path filters.
+func renderRaw(wt *WorkflowTemplate, data TemplateData) (string, error) {
+	content := strings.ReplaceAll(wt.Template, defaultBranchPlaceholder, data.DefaultBranch)
+	e, err := NewWorkflowEditor(wt.Filename, content)
+	if err != nil {
+		return "", err
+	}
+	if data.RefRepo != "" && data.RefBranch != "" {
+		if _, err := e.SetRefRepo(data.RefRepo, data.RefBranch); err != nil {
+			return "", err
+		}
+	}
+	if len(data.Branches) > 0 {
+		if _, err := e.SetBranches(data.Branches...); err != nil {
+			return "", err
+		}
+	}
+	if len(data.PathFilters) > 0 {
+		if err := e.AddPathFilters(data.PathFilters...); err != nil {
+			return "", err
+		}
+	}
+	return e.Content(), nil
+}
+
 // GetTemplate returns a template by workflow type.
 func (g *Generator) GetTemplate(workflowType string) (*WorkflowTemplate, bool) {
 	tmpl, ok := g.Templates[workflowType]
@@ -208,59 +405,67 @@ const goCI = `name: Go CI
 
 on:
   push:
-    branches: [main]
+    branches: {{flow .Branches}}
+{{- if .Paths}}
     paths:
-      - "**.go"
-      - "go.mod"
-      - "go.sum"
-      - ".github/workflows/go-ci.yaml"
+{{- range .Paths}}
+      - {{quote .}}
+{{- end}}
+{{- end}}
   pull_request:
-    branches: [main]
+    branches: {{flow .Branches}}
+{{- if .Paths}}
     paths:
-      - "**.go"
-      - "go.mod"
-      - "go.sum"
-      - ".github/workflows/go-ci.yaml"
+{{- range .Paths}}
+      - {{quote .}}
+{{- end}}
+{{- end}}
 
 jobs:
   ci:
     uses: {{.RefRepo}}/.github/workflows/go-ci.yaml@{{.RefBranch}}
+{{- if .WorkingDirectory}}
+    with:
+      working-directory: "{{.WorkingDirectory}}"
+{{- end}}
 `
 
 const goLint = `name: Go Lint
 
 on:
   push:
-    branches: [main]
+    branches: {{flow .Branches}}
+{{- if .Paths}}
     paths:
-      - "**.go"
-      - "go.mod"
-      - "go.sum"
-      - ".golangci.yml"
-      - ".golangci.yaml"
-      - ".github/workflows/go-lint.yaml"
+{{- range .Paths}}
+      - {{quote .}}
+{{- end}}
+{{- end}}
   pull_request:
-    branches: [main]
+    branches: {{flow .Branches}}
+{{- if .Paths}}
     paths:
-      - "**.go"
-      - "go.mod"
-      - "go.sum"
-      - ".golangci.yml"
-      - ".golangci.yaml"
-      - ".github/workflows/go-lint.yaml"
+{{- range .Paths}}
+      - {{quote .}}
+{{- end}}
+{{- end}}
 
 jobs:
   lint:
     uses: {{.RefRepo}}/.github/workflows/go-lint.yaml@{{.RefBranch}}
+{{- if .WorkingDirectory}}
+    with:
+      working-directory: "{{.WorkingDirectory}}"
+{{- end}}
 `
 
 const goSASTCodeQL = `name: CodeQL
 
 on:
   push:
-    branches: [main]
+    branches: {{flow .Branches}}
   pull_request:
-    branches: [main]
+    branches: {{flow .Branches}}
   schedule:
     - cron: "0 6 * * 1"
 
@@ -273,3 +478,63 @@ jobs:
   analyze:
     uses: {{.RefRepo}}/.github/workflows/go-sast-codeql.yaml@{{.RefBranch}}
 `
+
+// TypeScript workflow templates
+
+const tsCI = `name: TypeScript CI
+
+on:
+  push:
+    branches: {{flow .Branches}}
+{{- if .Paths}}
+    paths:
+{{- range .Paths}}
+      - {{quote .}}
+{{- end}}
+{{- end}}
+  pull_request:
+    branches: {{flow .Branches}}
+{{- if .Paths}}
+    paths:
+{{- range .Paths}}
+      - {{quote .}}
+{{- end}}
+{{- end}}
+
+jobs:
+  ci:
+    uses: {{.RefRepo}}/.github/workflows/ts-ci.yaml@{{.RefBranch}}
+{{- if .WorkingDirectory}}
+    with:
+      working-directory: "{{.WorkingDirectory}}"
+{{- end}}
+`
+
+const tsLint = `name: TypeScript Lint
+
+on:
+  push:
+    branches: {{flow .Branches}}
+{{- if .Paths}}
+    paths:
+{{- range .Paths}}
+      - {{quote .}}
+{{- end}}
+{{- end}}
+  pull_request:
+    branches: {{flow .Branches}}
+{{- if .Paths}}
+    paths:
+{{- range .Paths}}
+      - {{quote .}}
+{{- end}}
+{{- end}}
+
+jobs:
+  lint:
+    uses: {{.RefRepo}}/.github/workflows/ts-lint.yaml@{{.RefBranch}}
+{{- if .WorkingDirectory}}
+    with:
+      working-directory: "{{.WorkingDirectory}}"
+{{- end}}
+`
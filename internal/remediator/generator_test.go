@@ -287,6 +287,124 @@ func TestGenerator_GenerateForRepo_ExistingFile(t *testing.T) {
 	if !gf.WouldOverwrite {
 		t.Error("WouldOverwrite = false, want true")
 	}
+	if gf.Update != nil {
+		t.Errorf("Update = %+v, want nil for a non-workflow file", gf.Update)
+	}
+}
+
+func TestGenerator_GenerateForRepo_ExistingFileUpdate(t *testing.T) {
+	repoDir := t.TempDir()
+	workflowsDir := filepath.Join(repoDir, ".github", "workflows")
+	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	existing := "name: Go CI # pinned\non: [push]\njobs:\n  ci:\n    uses: testorg/.github/.github/workflows/go-ci.yaml@v1\n"
+	if err := os.WriteFile(filepath.Join(workflowsDir, "go-ci.yaml"), []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewGenerator(GeneratorConfig{RefRepo: "testorg/.github", RefBranch: "main", DryRun: true})
+	repo := model.Repo{Owner: "testorg", Name: "test-repo", FullName: "testorg/test-repo", LocalPath: repoDir}
+	generated, err := gen.GenerateForRepo(repo, []model.MissingWorkflow{{WorkflowType: "go-ci", Language: "Go"}})
+	if err != nil {
+		t.Fatalf("GenerateForRepo error: %v", err)
+	}
+
+	update := generated[0].Update
+	if update == nil {
+		t.Fatal("Update = nil, want ref pin patch")
+	}
+	want := strings.Replace(existing, "@v1", "@main", 1)
+	if update.Content != want {
+		t.Errorf("Update.Content = %q, want %q", update.Content, want)
+	}
+	if !strings.Contains(update.Diff, "+    uses: testorg/.github/.github/workflows/go-ci.yaml@main") {
+		t.Errorf("Update.Diff = %q", update.Diff)
+	}
+}
+
+func TestGenerator_GenerateForRepo_KeepsCustomizations(t *testing.T) {
+	repoDir := t.TempDir()
+	workflowsDir := filepath.Join(repoDir, ".github", "workflows")
+	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	customized := `name: Go CI
+on: [push]
+jobs:
+  ci:
+    uses: testorg/.github/.github/workflows/go-ci.yaml@v1
+  e2e:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make e2e
+`
+	path := filepath.Join(workflowsDir, "go-ci.yaml")
+	if err := os.WriteFile(path, []byte(customized), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewGenerator(GeneratorConfig{RefRepo: "testorg/.github", RefBranch: "main"})
+	repo := model.Repo{Owner: "testorg", Name: "test-repo", FullName: "testorg/test-repo", LocalPath: repoDir}
+	generated, err := gen.GenerateForRepo(repo, []model.MissingWorkflow{{WorkflowType: "go-ci", Language: "Go"}})
+	if err != nil {
+		t.Fatalf("GenerateForRepo error: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(customized, "@v1", "@main", 1)
+	if string(got) != want {
+		t.Errorf("written file = %q, want %q", got, want)
+	}
+
+	plan := NewPlan(repo, generated)
+	if len(plan.Patches) != 1 || plan.Patches[0].Content != want || plan.Patches[0].Diff == "" {
+		t.Errorf("plan patches = %+v, want the in-place update", plan.Patches)
+	}
+
+	// A file that needs no update is left alone and out of the plan.
+	generated, err = gen.GenerateForRepo(repo, []model.MissingWorkflow{{WorkflowType: "go-ci", Language: "Go"}})
+	if err != nil {
+		t.Fatalf("GenerateForRepo error: %v", err)
+	}
+	if plan := NewPlan(repo, generated); len(plan.Patches) != 0 {
+		t.Errorf("plan patches = %+v, want none", plan.Patches)
+	}
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("unchanged file rewritten to %q", got)
+	}
+}
+
+func TestGenerator_GenerateForRepo_Overwrite(t *testing.T) {
+	repoDir := t.TempDir()
+	workflowsDir := filepath.Join(repoDir, ".github", "workflows")
+	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(workflowsDir, "go-ci.yaml")
+	if err := os.WriteFile(path, []byte("name: old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewGenerator(GeneratorConfig{RefRepo: "testorg/.github", RefBranch: "main", Overwrite: true})
+	repo := model.Repo{Owner: "testorg", Name: "test-repo", FullName: "testorg/test-repo", LocalPath: repoDir}
+	generated, err := gen.GenerateForRepo(repo, []model.MissingWorkflow{{WorkflowType: "go-ci", Language: "Go"}})
+	if err != nil {
+		t.Fatalf("GenerateForRepo error: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != generated[0].Content {
+		t.Errorf("written file = %q, want rendered template", got)
+	}
+	if u := generated[0].Update; u == nil || u.Content != generated[0].Content {
+		t.Errorf("Update = %+v, want template replacement", u)
+	}
 }
 
 func TestGenerator_GenerateForRepo_NoLocalPath(t *testing.T) {
@@ -424,3 +542,136 @@ func TestGoTemplates_ValidYAML(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerator_RenderTriggers(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoDir, "svc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "svc", "go.mod"), []byte("module svc\n"), 0644); err != nil { //nolint:gosec // test fixture
+		t.Fatal(err)
+	}
+
+	gen := NewGenerator(GeneratorConfig{
+		RefRepo:         "testorg/.github",
+		DryRun:          true,
+		ReleaseBranches: []string{"release/*"},
+		PathFilters:     []string{"Makefile"},
+	})
+	repo := model.Repo{Owner: "testorg", Name: "svc", FullName: "testorg/svc", DefaultBranch: "develop", LocalPath: repoDir}
+
+	generated, err := gen.GenerateForRepo(repo, []model.MissingWorkflow{{WorkflowType: "go-lint"}, {WorkflowType: "go-sast-codeql"}})
+	if err != nil {
+		t.Fatalf("GenerateForRepo error: %v", err)
+	}
+
+	lint := generated[0].Content
+	for _, want := range []string{
+		"    branches: [develop, release/*]\n    paths:\n      - \"svc/**.go\"\n",
+		"      - \"svc/.golangci.yml\"\n",
+		"      - \".github/workflows/go-lint.yaml\"\n      - \"Makefile\"\n  pull_request:",
+	} {
+		if !strings.Contains(lint, want) {
+			t.Errorf("go-lint missing %q:\n%s", want, lint)
+		}
+	}
+	if strings.Contains(lint, "[main]") {
+		t.Errorf("go-lint still triggers on main:\n%s", lint)
+	}
+
+	codeql := generated[1].Content
+	if !strings.Contains(codeql, "branches: [develop, release/*]") || strings.Contains(codeql, "paths:") {
+		t.Errorf("go-sast-codeql triggers:\n%s", codeql)
+	}
+}
+
+func TestGenerator_RenderRawPathFilters(t *testing.T) {
+	wt, err := ParseTemplate("go-ci.yaml", []byte("name: Go CI\non:\n  push:\n    branches: [$default-branch]\njobs:\n  ci:\n    runs-on: ubuntu-latest\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := NewGenerator(GeneratorConfig{PathFilters: []string{"**.go"}})
+	content, err := gen.renderTemplate(wt, TemplateData{DefaultBranch: "master", PathFilters: gen.Config.PathFilters})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "    branches: [master]\n    paths:\n      - \"**.go\"\n"; !strings.Contains(content, want) {
+		t.Errorf("content missing %q:\n%s", want, content)
+	}
+}
+
+func TestGenerator_RenderRecipeBranches(t *testing.T) {
+	templates, err := LoadTemplatesDir(filepath.Join("..", "..", "recipes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := NewGenerator(GeneratorConfig{ReleaseBranches: []string{"release/*"}})
+	for _, wt := range templates {
+		if wt.Type != "go-ci" {
+			continue
+		}
+		repo := model.Repo{Owner: "testorg", Name: "legacy", DefaultBranch: "master"}
+		content, err := gen.renderTemplate(wt, gen.templateData(repo, wt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Count(content, "    branches:\n      - master\n      - release/*\n"); got != 2 {
+			t.Errorf("push and pull_request branches not set to master and release/*:\n%s", content)
+		}
+		if strings.Contains(content, "- main\n") {
+			t.Errorf("recipe still triggers on main:\n%s", content)
+		}
+		return
+	}
+	t.Fatal("go-ci recipe not found")
+}
+
+func TestGenerator_RenderRecipeRefRepo(t *testing.T) {
+	templates, err := LoadTemplatesDir(filepath.Join("..", "..", "recipes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := NewGenerator(GeneratorConfig{RefRepo: "acme/.github", RefBranch: "v2"})
+	for _, wt := range templates {
+		if wt.Type != "go-ci" {
+			continue
+		}
+		repo := model.Repo{Owner: "testorg", Name: "app", DefaultBranch: "main"}
+		content, err := gen.renderTemplate(wt, gen.templateData(repo, wt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(content, "uses: acme/.github/.github/workflows/go-ci.yaml@v2\n") || strings.Contains(content, "grokify/") {
+			t.Errorf("recipe not pointed at acme/.github@v2:\n%s", content)
+		}
+		return
+	}
+	t.Fatal("go-ci recipe not found")
+}
+
+func TestGenerator_RenderGoWorkingDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "services", "api", "go.mod"), "module api\n")
+	gen := NewGenerator(GeneratorConfig{RefRepo: "testorg/.github"})
+	repo := model.Repo{Owner: "testorg", Name: "mono", LocalPath: root}
+
+	for _, typ := range []string{"go-ci", "go-lint"} {
+		wt, _ := gen.GetTemplate(typ)
+		content, err := gen.renderTemplate(wt, gen.templateData(repo, wt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "    with:\n      working-directory: \"services/api\"\n"; !strings.Contains(content, want) {
+			t.Errorf("%s missing working-directory:\n%s", typ, content)
+		}
+
+		// A module at the root needs no working directory.
+		content, err = gen.renderTemplate(wt, gen.templateData(model.Repo{Owner: "testorg", Name: "app"}, wt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(content, "with:") {
+			t.Errorf("%s passes a working directory for a root module:\n%s", typ, content)
+		}
+	}
+}
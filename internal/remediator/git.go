@@ -0,0 +1,279 @@
+package remediator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// Git defaults.
+const (
+	DefaultGitBranch = "ci/update-workflows"
+	DefaultGitRemote = "origin"
+)
+
+// ErrDirtyWorktree is returned for repositories with uncommitted changes,
+// or untracked files at patched paths, which are skipped rather than mixed
+// into or overwritten by the remediation commit.
+var ErrDirtyWorktree = errors.New("working tree has uncommitted changes")
+
+// ErrBranchDiverged is returned when the remediation branch already exists
+// and is not an ancestor of HEAD. Resetting it would discard its commits,
+// so the repository is skipped until the branch is merged or deleted.
+var ErrBranchDiverged = errors.New("branch has commits not in HEAD")
+
+// GitConfig configures remediation commits in local checkouts.
+type GitConfig struct {
+	Branch        string // Defaults to DefaultGitBranch
+	CommitMessage string // Defaults to the plan's PR title
+	// Sign signs commits with the user's configured key, or with
+	// SigningKey when set.
+	Sign       bool
+	SigningKey string
+	Push       bool
+	Remote     string // Defaults to DefaultGitRemote
+}
+
+// GitApplier applies remediation plans to local checkouts, such as those
+// found by collector.LocalCollector. It creates a branch from the current
+// HEAD, writes and stages the plan's patches, commits them and optionally
+// pushes the branch.
+type GitApplier struct {
+	Config GitConfig
+}
+
+// NewGitApplier creates a git applier.
+func NewGitApplier(cfg GitConfig) *GitApplier {
+	if cfg.Branch == "" {
+		cfg.Branch = DefaultGitBranch
+	}
+	if cfg.Remote == "" {
+		cfg.Remote = DefaultGitRemote
+	}
+	return &GitApplier{Config: cfg}
+}
+
+// Apply commits the plan's patches on the configured branch, or on the
+// plan's Branch when set, of the repository's local checkout.
+// Repositories with a dirty working tree are skipped with
+// ErrDirtyWorktree. An existing branch is only reused when it is an
+// ancestor of HEAD; otherwise the repository is skipped with
+// ErrBranchDiverged. The result records the base and new commits and the
+// pre-change file contents for rollback; no commit is made when the
+// patches change nothing. If applying fails after the branch is created,
+// the original branch is checked out again and the written files are
+// discarded. Dry-run plans only check the working tree and the branch.
+// The returned result records the failure when err is non-nil.
+func (a *GitApplier) Apply(ctx context.Context, plan model.RemediationPlan) (*model.RemediationResult, error) {
+	if plan.Branch != "" {
+		a = &GitApplier{Config: a.Config}
+		a.Config.Branch = plan.Branch
+	}
+	result := &model.RemediationResult{Repo: plan.Repo, DryRun: plan.DryRun, Branch: a.Config.Branch}
+	if err := a.apply(ctx, plan, result); err != nil {
+		err = fmt.Errorf("applying remediation to %s: %w", plan.Repo.FullName, err)
+		result.Error = err.Error()
+		return result, err
+	}
+	result.Success = true
+	return result, nil
+}
+
+func (a *GitApplier) apply(ctx context.Context, plan model.RemediationPlan, result *model.RemediationResult) (err error) {
+	dir := plan.Repo.LocalPath
+	if dir == "" {
+		return errors.New("repo has no local path")
+	}
+	if len(plan.Patches) == 0 {
+		return errors.New("plan has no patches")
+	}
+
+	status, err := git(ctx, dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		return ErrDirtyWorktree
+	}
+	var paths, written, removed []string
+	for _, p := range plan.Patches {
+		path := filepath.FromSlash(p.Path)
+		paths = append(paths, path)
+		if p.Operation == "delete" {
+			removed = append(removed, path)
+		} else {
+			written = append(written, path)
+		}
+	}
+	untracked, err := git(ctx, dir, append([]string{"ls-files", "--others", "--"}, paths...)...)
+	if err != nil {
+		return err
+	}
+	if untracked != "" {
+		return fmt.Errorf("%w: untracked %s", ErrDirtyWorktree, strings.ReplaceAll(untracked, "\n", ", "))
+	}
+	if err := a.checkBranch(ctx, dir); err != nil {
+		return err
+	}
+	if plan.DryRun {
+		return nil
+	}
+
+	if result.BaseSHA, err = git(ctx, dir, "rev-parse", "HEAD"); err != nil {
+		return err
+	}
+	original, err := git(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		original = result.BaseSHA // detached HEAD
+	}
+	if _, err := git(ctx, dir, "checkout", "-B", a.Config.Branch); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rerr := restoreCheckout(ctx, dir, original, paths); rerr != nil {
+				err = errors.Join(err, fmt.Errorf("restoring %s: %w", original, rerr))
+			}
+		}
+	}()
+	if result.Previous, err = writePatches(dir, plan.Patches); err != nil {
+		return err
+	}
+
+	if len(written) > 0 {
+		if _, err := git(ctx, dir, append([]string{"add", "--"}, written...)...); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		if _, err := git(ctx, dir, append([]string{"rm", "--cached", "--quiet", "--ignore-unmatch", "--"}, removed...)...); err != nil {
+			return err
+		}
+	}
+
+	if staged, err := git(ctx, dir, "diff", "--cached", "--name-only"); err != nil {
+		return err
+	} else if staged != "" {
+		if _, err := git(ctx, dir, a.commitArgs(plan)...); err != nil {
+			return err
+		}
+		if result.CommitSHA, err = git(ctx, dir, "rev-parse", "HEAD"); err != nil {
+			return err
+		}
+	}
+
+	if a.Config.Push {
+		if _, err := git(ctx, dir, "push", "--set-upstream", a.Config.Remote, a.Config.Branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreCheckout checks out the original branch again after a failed
+// apply, discarding the patches written to the index and working tree.
+// The patched paths had no untracked files beforehand, so any left there
+// were created by the patches and are removed.
+func restoreCheckout(ctx context.Context, dir, original string, paths []string) error {
+	if _, err := git(ctx, dir, "checkout", "--force", "--quiet", original); err != nil {
+		return err
+	}
+	_, err := git(ctx, dir, append([]string{"clean", "--force", "-x", "--quiet", "--"}, paths...)...)
+	return err
+}
+
+// checkBranch returns ErrBranchDiverged when the branch exists and is not
+// an ancestor of HEAD, as checking it out with -B would reset it.
+func (a *GitApplier) checkBranch(ctx context.Context, dir string) error {
+	ref := "refs/heads/" + a.Config.Branch
+	var exitErr *exec.ExitError
+	if _, err := git(ctx, dir, "show-ref", "--verify", "--quiet", ref); errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil // no such branch
+	} else if err != nil {
+		return err
+	}
+	if _, err := git(ctx, dir, "merge-base", "--is-ancestor", ref, "HEAD"); errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return fmt.Errorf("%w: %s", ErrBranchDiverged, a.Config.Branch)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// commitArgs returns the git arguments that commit the staged patches.
+func (a *GitApplier) commitArgs(plan model.RemediationPlan) []string {
+	message := a.Config.CommitMessage
+	if message == "" {
+		message = plan.PRTitle
+	}
+	if message == "" {
+		message = PRTitle(plan)
+	}
+	args := []string{"commit", "--message", message}
+	if a.Config.Sign {
+		sign := "--gpg-sign"
+		if a.Config.SigningKey != "" {
+			sign += "=" + a.Config.SigningKey
+		}
+		args = append(args, sign)
+	}
+	return args
+}
+
+// writePatches applies patches to the working tree under dir and returns
+// the files' previous contents.
+func writePatches(dir string, patches []model.Patch) ([]model.FileSnapshot, error) {
+	snapshots := make([]model.FileSnapshot, 0, len(patches))
+	for _, p := range patches {
+		path := filepath.Join(dir, filepath.FromSlash(p.Path))
+		snap := model.FileSnapshot{Path: p.Path}
+		if content, err := os.ReadFile(path); err == nil {
+			snap.Existed = true
+			snap.Content = string(content)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: %w", p.Path, err)
+		}
+		snapshots = append(snapshots, snap)
+
+		switch p.Operation {
+		case "create", "update":
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, fmt.Errorf("creating directory for %s: %w", p.Path, err)
+			}
+			if err := os.WriteFile(path, []byte(p.Content), 0644); err != nil { //nolint:gosec // G306: workflow files need to be readable
+				return nil, fmt.Errorf("writing %s: %w", p.Path, err)
+			}
+		case "delete":
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("removing %s: %w", p.Path, err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown patch operation %q for %s", p.Operation, p.Path)
+		}
+	}
+	return snapshots, nil
+}
+
+// git runs a git command in dir and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,221 @@
+package remediator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// runGit runs a git command for test setup.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := git(context.Background(), dir, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// newGitRepo creates a checkout with one commit on main, cloned from a
+// temporary bare repository that serves as origin.
+func newGitRepo(t *testing.T) (work, remote string) {
+	t.Helper()
+	dir := t.TempDir()
+	remote = filepath.Join(dir, "remote.git")
+	work = filepath.Join(dir, "work")
+	runGit(t, dir, "init", "--quiet", "--bare", "--initial-branch=main", remote)
+	runGit(t, dir, "init", "--quiet", "--initial-branch=main", work)
+	runGit(t, work, "config", "user.name", "Test")
+	runGit(t, work, "config", "user.email", "test@example.com")
+	runGit(t, work, "config", "commit.gpgsign", "false")
+
+	writeFile(t, filepath.Join(work, ".github", "workflows", "ci.yml"), "name: CI\n")
+	writeFile(t, filepath.Join(work, "README.md"), "# repo\n")
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "--quiet", "--message", "initial")
+	runGit(t, work, "remote", "add", "origin", remote)
+	runGit(t, work, "push", "--quiet", "origin", "main")
+	return work, remote
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil { //nolint:gosec // test fixture
+		t.Fatal(err)
+	}
+}
+
+func TestGitApplierApply(t *testing.T) {
+	work, remote := newGitRepo(t)
+	plan := testPlan()
+	plan.Repo.LocalPath = work
+
+	applier := NewGitApplier(GitConfig{CommitMessage: "ci: add compliant workflows", Push: true})
+	result, err := applier.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !result.Success || result.Branch != DefaultGitBranch || result.BaseSHA == "" || result.CommitSHA == "" {
+		t.Errorf("result = %+v", result)
+	}
+	wantPrevious := []model.FileSnapshot{
+		{Path: ".github/workflows/go-ci.yaml"},
+		{Path: ".github/workflows/ci.yml", Existed: true, Content: "name: CI\n"},
+	}
+	if !slices.Equal(result.Previous, wantPrevious) {
+		t.Errorf("Previous = %+v, want %+v", result.Previous, wantPrevious)
+	}
+
+	if branch := runGit(t, work, "branch", "--show-current"); branch != DefaultGitBranch {
+		t.Errorf("current branch = %q, want %q", branch, DefaultGitBranch)
+	}
+	if pushed := runGit(t, remote, "rev-parse", DefaultGitBranch); pushed != result.CommitSHA {
+		t.Errorf("remote branch at %s, want %s", pushed, result.CommitSHA)
+	}
+	if msg := runGit(t, remote, "log", "-1", "--format=%s", DefaultGitBranch); msg != "ci: add compliant workflows" {
+		t.Errorf("commit message = %q", msg)
+	}
+	files := runGit(t, remote, "show", "--name-status", "--format=", DefaultGitBranch)
+	for _, want := range []string{"A\t.github/workflows/go-ci.yaml", "D\t.github/workflows/ci.yml"} {
+		if !strings.Contains(files, want) {
+			t.Errorf("commit files missing %q:\n%s", want, files)
+		}
+	}
+
+	// Re-applying the same plan changes nothing and makes no commit.
+	again, err := applier.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("second Apply() error = %v", err)
+	}
+	if again.CommitSHA != "" || runGit(t, work, "rev-parse", "HEAD") != result.CommitSHA {
+		t.Errorf("second apply committed %q", again.CommitSHA)
+	}
+}
+
+func TestGitApplierSkipsDirtyWorktree(t *testing.T) {
+	work, _ := newGitRepo(t)
+	writeFile(t, filepath.Join(work, "README.md"), "# edited\n")
+	plan := testPlan()
+	plan.Repo.LocalPath = work
+
+	result, err := NewGitApplier(GitConfig{}).Apply(context.Background(), plan)
+	if !errors.Is(err, ErrDirtyWorktree) {
+		t.Fatalf("Apply() error = %v, want ErrDirtyWorktree", err)
+	}
+	if result.Success || result.Error == "" {
+		t.Errorf("result = %+v, want recorded failure", result)
+	}
+	if branch := runGit(t, work, "branch", "--show-current"); branch != "main" {
+		t.Errorf("switched to branch %q", branch)
+	}
+}
+
+func TestGitApplierSkipsUntrackedPatchPath(t *testing.T) {
+	work, _ := newGitRepo(t)
+	path := filepath.Join(work, ".github", "workflows", "go-ci.yaml")
+	writeFile(t, path, "name: local draft\n")
+	plan := testPlan()
+	plan.Repo.LocalPath = work
+
+	_, err := NewGitApplier(GitConfig{}).Apply(context.Background(), plan)
+	if !errors.Is(err, ErrDirtyWorktree) || !strings.Contains(err.Error(), ".github/workflows/go-ci.yaml") {
+		t.Fatalf("Apply() error = %v, want ErrDirtyWorktree for go-ci.yaml", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "name: local draft\n" {
+		t.Errorf("untracked file overwritten with %q", content)
+	}
+}
+
+func TestGitApplierSkipsDivergedBranch(t *testing.T) {
+	work, _ := newGitRepo(t)
+	runGit(t, work, "checkout", "--quiet", "-b", DefaultGitBranch)
+	writeFile(t, filepath.Join(work, "NOTES.md"), "wip\n")
+	runGit(t, work, "add", "NOTES.md")
+	runGit(t, work, "commit", "--quiet", "--message", "wip")
+	tip := runGit(t, work, "rev-parse", "HEAD")
+	runGit(t, work, "checkout", "--quiet", "main")
+	plan := testPlan()
+	plan.Repo.LocalPath = work
+
+	for _, dryRun := range []bool{true, false} {
+		plan.DryRun = dryRun
+		if _, err := NewGitApplier(GitConfig{}).Apply(context.Background(), plan); !errors.Is(err, ErrBranchDiverged) {
+			t.Fatalf("Apply(dryRun=%v) error = %v, want ErrBranchDiverged", dryRun, err)
+		}
+	}
+	if got := runGit(t, work, "rev-parse", DefaultGitBranch); got != tip {
+		t.Errorf("branch reset to %s, want %s", got, tip)
+	}
+	if branch := runGit(t, work, "branch", "--show-current"); branch != "main" {
+		t.Errorf("switched to branch %q", branch)
+	}
+
+	// A branch already merged into HEAD is reused.
+	runGit(t, work, "merge", "--quiet", "--ff-only", DefaultGitBranch)
+	if _, err := NewGitApplier(GitConfig{}).Apply(context.Background(), plan); err != nil {
+		t.Fatalf("Apply() on merged branch error = %v", err)
+	}
+}
+
+func TestGitApplierRestoresOnFailure(t *testing.T) {
+	work, _ := newGitRepo(t)
+	writeFile(t, filepath.Join(work, "build.log"), "unrelated\n")
+	plan := testPlan()
+	plan.Repo.LocalPath = work
+
+	// The push fails after the commit is made.
+	_, err := NewGitApplier(GitConfig{Push: true, Remote: "missing"}).Apply(context.Background(), plan)
+	if err == nil {
+		t.Fatal("Apply() expected push error")
+	}
+	if branch := runGit(t, work, "branch", "--show-current"); branch != "main" {
+		t.Errorf("left on branch %q, want main", branch)
+	}
+	if status := runGit(t, work, "status", "--porcelain"); status != "?? build.log" {
+		t.Errorf("status = %q, want only the unrelated untracked file", status)
+	}
+
+	// Writing fails partway through the patches. The branch left by the
+	// failed push has diverged from main, so it is deleted first.
+	runGit(t, work, "branch", "-D", DefaultGitBranch)
+	plan.Patches = append(plan.Patches, model.Patch{Path: "x.yml", Operation: "rename"})
+	if _, err := NewGitApplier(GitConfig{}).Apply(context.Background(), plan); err == nil || !strings.Contains(err.Error(), "unknown patch operation") {
+		t.Fatalf("Apply() error = %v, want unknown operation error", err)
+	}
+	if branch := runGit(t, work, "branch", "--show-current"); branch != "main" {
+		t.Errorf("left on branch %q, want main", branch)
+	}
+	if status := runGit(t, work, "status", "--porcelain"); status != "?? build.log" {
+		t.Errorf("status = %q, want only the unrelated untracked file", status)
+	}
+}
+
+func TestGitApplierCommitArgs(t *testing.T) {
+	plan := model.RemediationPlan{PRTitle: "ci: fix"}
+	tests := []struct {
+		name string
+		cfg  GitConfig
+		want []string
+	}{
+		{"plan title", GitConfig{}, []string{"commit", "--message", "ci: fix"}},
+		{"custom message", GitConfig{CommitMessage: "chore: ci"}, []string{"commit", "--message", "chore: ci"}},
+		{"signed", GitConfig{Sign: true}, []string{"commit", "--message", "ci: fix", "--gpg-sign"}},
+		{"signed with key", GitConfig{Sign: true, SigningKey: "ABC123"}, []string{"commit", "--message", "ci: fix", "--gpg-sign=ABC123"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewGitApplier(tt.cfg).commitArgs(plan); !slices.Equal(got, tt.want) {
+				t.Errorf("commitArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
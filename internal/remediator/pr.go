@@ -0,0 +1,398 @@
+package remediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v84/github"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// DefaultPRBranch is the head branch remediation pull requests are opened from.
+const DefaultPRBranch = "pipelineconductor/remediation"
+
+// PRConfig configures remediation pull requests.
+type PRConfig struct {
+	Branch        string // Head branch; defaults to DefaultPRBranch
+	CommitMessage string // Defaults to the PR title
+	Labels        []string
+	Reviewers     []string // User logins
+	TeamReviewers []string // Team slugs
+	Assignees     []string
+	Draft         bool
+}
+
+// PRApplier applies remediation plans as pull requests. Patches are
+// committed through the Git Data API, so no local checkout is needed.
+type PRApplier struct {
+	client *github.Client
+	Config PRConfig
+}
+
+// NewPRApplier creates a pull request applier using the given GitHub client.
+func NewPRApplier(client *github.Client, cfg PRConfig) *PRApplier {
+	if cfg.Branch == "" {
+		cfg.Branch = DefaultPRBranch
+	}
+	return &PRApplier{client: client, Config: cfg}
+}
+
+// NewPlan builds a remediation plan from generated workflow files. New
+// files are created; existing files get their in-place update, and are
+// left out when they need none.
+func NewPlan(repo model.Repo, files []GeneratedFile) model.RemediationPlan {
+	plan := model.RemediationPlan{Repo: repo}
+	for _, f := range files {
+		if !f.IsNew {
+			if f.Update != nil {
+				plan.Patches = append(plan.Patches, *f.Update)
+			}
+			continue
+		}
+		plan.Patches = append(plan.Patches, model.Patch{
+			Path:      f.RelativePath,
+			Operation: "create",
+			Content:   f.Content,
+		})
+	}
+	plan.PRTitle = PRTitle(plan)
+	plan.PRBody = PRBody(plan)
+	return plan
+}
+
+// PRTitle generates a pull request title for a plan.
+func PRTitle(plan model.RemediationPlan) string {
+	if len(plan.Patches) == 1 {
+		return fmt.Sprintf("ci: %s %s", plan.Patches[0].Operation, plan.Patches[0].Path)
+	}
+	return fmt.Sprintf("ci: remediate CI compliance (%d files)", len(plan.Patches))
+}
+
+// PRBody generates a pull request body listing a plan's patches.
+func PRBody(plan model.RemediationPlan) string {
+	var sb strings.Builder
+	sb.WriteString("This pull request was opened by PipelineConductor to bring the repository's CI configuration into compliance.\n\n")
+	sb.WriteString("**Changes:**\n\n")
+	for _, p := range plan.Patches {
+		sb.WriteString(fmt.Sprintf("- %s `%s`\n", p.Operation, p.Path))
+	}
+	return sb.String()
+}
+
+// Apply commits the plan's patches to the configured branch, or to the
+// plan's Branch when set, and opens a pull request against the
+// repository's default branch. An existing branch is reset to the new
+// commit, and an open pull request from it is reused. Dry-run plans make
+// no API calls. The returned result records the failure when err is
+// non-nil.
+func (a *PRApplier) Apply(ctx context.Context, plan model.RemediationPlan) (*model.RemediationResult, error) {
+	result := &model.RemediationResult{Repo: plan.Repo, DryRun: plan.DryRun}
+	if plan.DryRun {
+		result.Success = true
+		return result, nil
+	}
+	if plan.Branch != "" {
+		a = &PRApplier{client: a.client, Config: a.Config}
+		a.Config.Branch = plan.Branch
+	}
+
+	pr, err := a.apply(ctx, plan, result)
+	if err != nil {
+		err = fmt.Errorf("applying remediation to %s: %w", plan.Repo.FullName, err)
+		result.Error = err.Error()
+		return result, err
+	}
+
+	result.Success = true
+	result.PRURL = pr.GetHTMLURL()
+	result.PRNumber = pr.GetNumber()
+	result.Branch = a.Config.Branch
+	return result, nil
+}
+
+// apply opens the pull request, recording the base and remediation
+// commits and the pre-change file contents in result.
+func (a *PRApplier) apply(ctx context.Context, plan model.RemediationPlan, result *model.RemediationResult) (*github.PullRequest, error) {
+	if len(plan.Patches) == 0 {
+		return nil, errors.New("plan has no patches")
+	}
+	owner, name := plan.Repo.Owner, plan.Repo.Name
+	base := baseBranch(plan.Repo)
+	title := plan.PRTitle
+	if title == "" {
+		title = PRTitle(plan)
+	}
+	body := plan.PRBody
+	if body == "" {
+		body = PRBody(plan)
+	}
+
+	ref, _, err := a.client.Git.GetRef(ctx, owner, name, "heads/"+base)
+	if err != nil {
+		return nil, fmt.Errorf("getting base branch %s: %w", base, err)
+	}
+	result.BaseSHA = ref.GetObject().GetSHA()
+
+	if result.Previous, err = a.snapshot(ctx, owner, name, result.BaseSHA, plan.Patches); err != nil {
+		return nil, err
+	}
+	if result.CommitSHA, err = a.commit(ctx, owner, name, result.BaseSHA, plan.Patches, a.commitMessage(title)); err != nil {
+		return nil, err
+	}
+	if err := a.updateBranch(ctx, owner, name, result.CommitSHA); err != nil {
+		return nil, err
+	}
+
+	pr, err := a.openPR(ctx, owner, name, base, title, body)
+	if err != nil {
+		return nil, err
+	}
+	number := pr.GetNumber()
+
+	if len(a.Config.Labels) > 0 {
+		if _, _, err := a.client.Issues.AddLabelsToIssue(ctx, owner, name, number, a.Config.Labels); err != nil {
+			return nil, fmt.Errorf("adding labels: %w", err)
+		}
+	}
+	if len(a.Config.Reviewers) > 0 || len(a.Config.TeamReviewers) > 0 {
+		reviewers := github.ReviewersRequest{Reviewers: a.Config.Reviewers, TeamReviewers: a.Config.TeamReviewers}
+		if _, _, err := a.client.PullRequests.RequestReviewers(ctx, owner, name, number, reviewers); err != nil {
+			return nil, fmt.Errorf("requesting reviewers: %w", err)
+		}
+	}
+	if len(a.Config.Assignees) > 0 {
+		if _, _, err := a.client.Issues.AddAssignees(ctx, owner, name, number, a.Config.Assignees); err != nil {
+			return nil, fmt.Errorf("adding assignees: %w", err)
+		}
+	}
+
+	return pr, nil
+}
+
+// baseBranch returns the branch remediation pull requests target.
+func baseBranch(repo model.Repo) string {
+	if repo.DefaultBranch == "" {
+		return "main"
+	}
+	return repo.DefaultBranch
+}
+
+func (a *PRApplier) commitMessage(title string) string {
+	if a.Config.CommitMessage != "" {
+		return a.Config.CommitMessage
+	}
+	return title
+}
+
+// snapshot returns the contents of the patched files at commit sha.
+func (a *PRApplier) snapshot(ctx context.Context, owner, name, sha string, patches []model.Patch) ([]model.FileSnapshot, error) {
+	snapshots := make([]model.FileSnapshot, 0, len(patches))
+	for _, p := range patches {
+		file, _, resp, err := a.client.Repositories.GetContents(ctx, owner, name, p.Path, &github.RepositoryContentGetOptions{Ref: sha})
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			snapshots = append(snapshots, model.FileSnapshot{Path: p.Path})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", p.Path, err)
+		}
+		if file == nil {
+			return nil, fmt.Errorf("reading %s: not a file", p.Path)
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", p.Path, err)
+		}
+		snapshots = append(snapshots, model.FileSnapshot{Path: p.Path, Existed: true, Content: content})
+	}
+	return snapshots, nil
+}
+
+// commit creates a commit on top of parentSHA containing the patches and
+// returns its SHA.
+func (a *PRApplier) commit(ctx context.Context, owner, name, parentSHA string, patches []model.Patch, message string) (string, error) {
+	parent, _, err := a.client.Git.GetCommit(ctx, owner, name, parentSHA)
+	if err != nil {
+		return "", fmt.Errorf("getting base commit: %w", err)
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(patches))
+	for _, p := range patches {
+		entry := &github.TreeEntry{
+			Path: github.Ptr(p.Path),
+			Mode: github.Ptr("100644"),
+			Type: github.Ptr("blob"),
+		}
+		switch p.Operation {
+		case "create", "update":
+			entry.Content = github.Ptr(p.Content)
+		case "delete":
+			// A nil SHA and content removes the path from the tree.
+		default:
+			return "", fmt.Errorf("unknown patch operation %q for %s", p.Operation, p.Path)
+		}
+		entries = append(entries, entry)
+	}
+
+	tree, _, err := a.client.Git.CreateTree(ctx, owner, name, parent.GetTree().GetSHA(), entries)
+	if err != nil {
+		return "", fmt.Errorf("creating tree: %w", err)
+	}
+
+	commit, _, err := a.client.Git.CreateCommit(ctx, owner, name, github.Commit{
+		Message: github.Ptr(message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: github.Ptr(parentSHA)}},
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating commit: %w", err)
+	}
+	return commit.GetSHA(), nil
+}
+
+// updateBranch points the head branch at sha, creating it if needed.
+func (a *PRApplier) updateBranch(ctx context.Context, owner, name, sha string) error {
+	ref := "heads/" + a.Config.Branch
+	_, resp, err := a.client.Git.GetRef(ctx, owner, name, ref)
+	switch {
+	case err == nil:
+		if _, _, err := a.client.Git.UpdateRef(ctx, owner, name, ref, github.UpdateRef{SHA: sha, Force: github.Ptr(true)}); err != nil {
+			return fmt.Errorf("updating branch %s: %w", a.Config.Branch, err)
+		}
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		if _, _, err := a.client.Git.CreateRef(ctx, owner, name, github.CreateRef{Ref: "refs/" + ref, SHA: sha}); err != nil {
+			return fmt.Errorf("creating branch %s: %w", a.Config.Branch, err)
+		}
+	default:
+		return fmt.Errorf("getting branch %s: %w", a.Config.Branch, err)
+	}
+	return nil
+}
+
+// openPR opens the pull request, or returns the open one from the head
+// branch if there is one.
+func (a *PRApplier) openPR(ctx context.Context, owner, name, base, title, body string) (*github.PullRequest, error) {
+	existing, _, err := a.client.PullRequests.List(ctx, owner, name, &github.PullRequestListOptions{
+		State: "open",
+		Head:  owner + ":" + a.Config.Branch,
+		Base:  base,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing pull requests: %w", err)
+	}
+	if len(existing) > 0 {
+		// Keep the reused pull request's description in line with the
+		// changes now pushed to its branch.
+		pr, _, err := a.client.PullRequests.Edit(ctx, owner, name, existing[0].GetNumber(), &github.PullRequest{
+			Title: github.Ptr(title),
+			Body:  github.Ptr(body),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("updating pull request #%d: %w", existing[0].GetNumber(), err)
+		}
+		return pr, nil
+	}
+
+	pr, _, err := a.client.PullRequests.Create(ctx, owner, name, &github.NewPullRequest{
+		Title: github.Ptr(title),
+		Head:  github.Ptr(a.Config.Branch),
+		Base:  github.Ptr(base),
+		Body:  github.Ptr(body),
+		Draft: github.Ptr(a.Config.Draft),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating pull request: %w", err)
+	}
+	return pr, nil
+}
+
+// PRState returns the state of a pull request: "open", "merged" or "closed".
+func (a *PRApplier) PRState(ctx context.Context, repo model.Repo, number int) (string, error) {
+	pr, _, err := a.client.PullRequests.Get(ctx, repo.Owner, repo.Name, number)
+	if err != nil {
+		return "", fmt.Errorf("getting pull request %s#%d: %w", repo.FullName, number, err)
+	}
+	if pr.GetMerged() {
+		return model.PRStateMerged, nil
+	}
+	return pr.GetState(), nil
+}
+
+// PRStatus is the reconciled state of a pull request.
+type PRStatus struct {
+	State         string // model.PRStateOpen, PRStateMerged or PRStateClosed
+	ChecksFailing bool
+	Conflicts     bool
+}
+
+// PRStatus returns the state of a pull request and, while it is open,
+// whether its checks are failing and whether it conflicts with its base.
+func (a *PRApplier) PRStatus(ctx context.Context, repo model.Repo, number int) (*PRStatus, error) {
+	pr, _, err := a.client.PullRequests.Get(ctx, repo.Owner, repo.Name, number)
+	if err != nil {
+		return nil, fmt.Errorf("getting pull request %s#%d: %w", repo.FullName, number, err)
+	}
+	status := &PRStatus{State: pr.GetState()}
+	if pr.GetMerged() {
+		status.State = model.PRStateMerged
+	}
+	if status.State != model.PRStateOpen {
+		return status, nil
+	}
+
+	status.Conflicts = pr.GetMergeableState() == "dirty"
+	sha := pr.GetHead().GetSHA()
+
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		runs, resp, err := a.client.Checks.ListCheckRunsForRef(ctx, repo.Owner, repo.Name, sha, opts)
+		if err != nil {
+			return nil, fmt.Errorf("listing check runs for %s#%d: %w", repo.FullName, number, err)
+		}
+		for _, run := range runs.CheckRuns {
+			switch run.GetConclusion() {
+			case "failure", "timed_out", "action_required", "startup_failure":
+				status.ChecksFailing = true
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	combined, _, err := a.client.Repositories.GetCombinedStatus(ctx, repo.Owner, repo.Name, sha, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting commit status for %s#%d: %w", repo.FullName, number, err)
+	}
+	if combined.GetState() == "failure" {
+		status.ChecksFailing = true
+	}
+
+	return status, nil
+}
+
+// ClosePR closes a pull request, leaving comment on it if non-empty, and
+// deletes its head branch.
+func (a *PRApplier) ClosePR(ctx context.Context, repo model.Repo, number int, branch, comment string) error {
+	if comment != "" {
+		if _, _, err := a.client.Issues.CreateComment(ctx, repo.Owner, repo.Name, number, &github.IssueComment{Body: github.Ptr(comment)}); err != nil {
+			return fmt.Errorf("commenting on %s#%d: %w", repo.FullName, number, err)
+		}
+	}
+	if _, _, err := a.client.PullRequests.Edit(ctx, repo.Owner, repo.Name, number, &github.PullRequest{State: github.Ptr(model.PRStateClosed)}); err != nil {
+		return fmt.Errorf("closing %s#%d: %w", repo.FullName, number, err)
+	}
+	if branch == "" {
+		return nil
+	}
+	resp, err := a.client.Git.DeleteRef(ctx, repo.Owner, repo.Name, "heads/"+branch)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusUnprocessableEntity && resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("deleting branch %s: %w", branch, err)
+	}
+	return nil
+}
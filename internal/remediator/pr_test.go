@@ -0,0 +1,339 @@
+package remediator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v84/github"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// fakeGitHub is an httptest GitHub fake. Routes map "METHOD /path" to a
+// JSON response; unknown routes return 404. Links map routes to the Link
+// header of their response, for pagination. Requests are recorded in
+// order.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	routes   map[string]string
+	links    map[string]string
+	requests []string
+	bodies   map[string]json.RawMessage
+}
+
+func newFakeGitHub(t *testing.T, routes map[string]string) (*fakeGitHub, *github.Client) {
+	t.Helper()
+	f := &fakeGitHub{routes: routes, bodies: make(map[string]json.RawMessage)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+
+		f.mu.Lock()
+		f.requests = append(f.requests, key)
+		if len(body) > 0 {
+			f.bodies[key] = body
+		}
+		// Routes with a query string take precedence over the bare path.
+		route := key + "?" + r.URL.RawQuery
+		resp, ok := f.routes[route]
+		if !ok {
+			route = key
+			resp, ok = f.routes[route]
+		}
+		link := f.links[route]
+		f.mu.Unlock()
+
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if link != "" {
+			w.Header().Set("Link", link)
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = baseURL
+	return f, client
+}
+
+func (f *fakeGitHub) called(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Contains(f.requests, key)
+}
+
+func (f *fakeGitHub) body(t *testing.T, key string, v any) {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := json.Unmarshal(f.bodies[key], v); err != nil {
+		t.Fatalf("decoding %s body: %v", key, err)
+	}
+}
+
+// gitDataRoutes returns the Git Data API routes for committing on top of main.
+func gitDataRoutes() map[string]string {
+	return map[string]string{
+		"GET /repos/testorg/repo1/git/ref/heads/main": `{"ref":"refs/heads/main","object":{"sha":"base"}}`,
+		"GET /repos/testorg/repo1/git/commits/base":   `{"sha":"base","tree":{"sha":"basetree"}}`,
+		"POST /repos/testorg/repo1/git/trees":         `{"sha":"newtree"}`,
+		"POST /repos/testorg/repo1/git/commits":       `{"sha":"newcommit"}`,
+		"GET /repos/testorg/repo1/pulls":              `[]`,
+	}
+}
+
+func testPlan() model.RemediationPlan {
+	return model.RemediationPlan{
+		Repo: model.Repo{Owner: "testorg", Name: "repo1", FullName: "testorg/repo1", DefaultBranch: "main"},
+		Patches: []model.Patch{
+			{Path: ".github/workflows/go-ci.yaml", Operation: "create", Content: "name: Go CI\n"},
+			{Path: ".github/workflows/ci.yml", Operation: "delete"},
+		},
+	}
+}
+
+func TestPRApplierApply(t *testing.T) {
+	routes := gitDataRoutes()
+	routes["POST /repos/testorg/repo1/git/refs"] = `{"ref":"refs/heads/pipelineconductor/remediation"}`
+	routes["POST /repos/testorg/repo1/pulls"] = `{"number":42,"html_url":"https://github.com/testorg/repo1/pull/42"}`
+	routes["POST /repos/testorg/repo1/issues/42/labels"] = `[]`
+	routes["POST /repos/testorg/repo1/pulls/42/requested_reviewers"] = `{"number":42}`
+	routes["POST /repos/testorg/repo1/issues/42/assignees"] = `{"number":42}`
+	routes["GET /repos/testorg/repo1/contents/.github/workflows/ci.yml"] = `{"type":"file","encoding":"base64","content":"bmFtZTogQ0kK"}`
+	fake, client := newFakeGitHub(t, routes)
+
+	applier := NewPRApplier(client, PRConfig{
+		Labels:        []string{"ci"},
+		Reviewers:     []string{"alice"},
+		TeamReviewers: []string{"platform"},
+		Assignees:     []string{"bob"},
+	})
+	result, err := applier.Apply(context.Background(), testPlan())
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !result.Success || result.PRNumber != 42 || result.PRURL != "https://github.com/testorg/repo1/pull/42" {
+		t.Errorf("result = %+v", result)
+	}
+	wantPrevious := []model.FileSnapshot{
+		{Path: ".github/workflows/go-ci.yaml"},
+		{Path: ".github/workflows/ci.yml", Existed: true, Content: "name: CI\n"},
+	}
+	if result.BaseSHA != "base" || result.CommitSHA != "newcommit" || !slices.Equal(result.Previous, wantPrevious) {
+		t.Errorf("recorded base %q, commit %q, previous %+v", result.BaseSHA, result.CommitSHA, result.Previous)
+	}
+
+	var tree struct {
+		BaseTree string `json:"base_tree"`
+		Tree     []struct {
+			Path    string  `json:"path"`
+			SHA     *string `json:"sha"`
+			Content *string `json:"content"`
+		} `json:"tree"`
+	}
+	fake.body(t, "POST /repos/testorg/repo1/git/trees", &tree)
+	if tree.BaseTree != "basetree" || len(tree.Tree) != 2 {
+		t.Fatalf("tree = %+v", tree)
+	}
+	if tree.Tree[0].Content == nil || *tree.Tree[0].Content != "name: Go CI\n" {
+		t.Errorf("create entry = %+v", tree.Tree[0])
+	}
+	if tree.Tree[1].SHA != nil || tree.Tree[1].Content != nil {
+		t.Errorf("delete entry = %+v, want null sha", tree.Tree[1])
+	}
+
+	var ref github.CreateRef
+	fake.body(t, "POST /repos/testorg/repo1/git/refs", &ref)
+	if ref.Ref != "refs/heads/pipelineconductor/remediation" || ref.SHA != "newcommit" {
+		t.Errorf("ref = %+v", ref)
+	}
+
+	var pr github.NewPullRequest
+	fake.body(t, "POST /repos/testorg/repo1/pulls", &pr)
+	if pr.GetTitle() != "ci: remediate CI compliance (2 files)" || pr.GetBase() != "main" || pr.GetHead() != DefaultPRBranch {
+		t.Errorf("pull request = %+v", pr)
+	}
+
+	var reviewers github.ReviewersRequest
+	fake.body(t, "POST /repos/testorg/repo1/pulls/42/requested_reviewers", &reviewers)
+	if !slices.Equal(reviewers.Reviewers, []string{"alice"}) || !slices.Equal(reviewers.TeamReviewers, []string{"platform"}) {
+		t.Errorf("reviewers = %+v", reviewers)
+	}
+	if !fake.called("POST /repos/testorg/repo1/issues/42/labels") || !fake.called("POST /repos/testorg/repo1/issues/42/assignees") {
+		t.Error("labels or assignees were not added")
+	}
+}
+
+func TestPRApplierApplyExistingBranch(t *testing.T) {
+	routes := gitDataRoutes()
+	routes["GET /repos/testorg/repo1/git/ref/heads/fix-ci"] = `{"ref":"refs/heads/fix-ci","object":{"sha":"old"}}`
+	routes["PATCH /repos/testorg/repo1/git/refs/heads/fix-ci"] = `{"ref":"refs/heads/fix-ci","object":{"sha":"newcommit"}}`
+	routes["GET /repos/testorg/repo1/pulls"] = `[{"number":7,"html_url":"https://github.com/testorg/repo1/pull/7","title":"old"}]`
+	routes["PATCH /repos/testorg/repo1/pulls/7"] = `{"number":7,"html_url":"https://github.com/testorg/repo1/pull/7"}`
+	fake, client := newFakeGitHub(t, routes)
+
+	plan := testPlan()
+	result, err := NewPRApplier(client, PRConfig{Branch: "fix-ci"}).Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.PRNumber != 7 {
+		t.Errorf("PRNumber = %d, want existing PR 7", result.PRNumber)
+	}
+
+	var edit github.PullRequest
+	fake.body(t, "PATCH /repos/testorg/repo1/pulls/7", &edit)
+	if edit.GetTitle() != PRTitle(plan) || edit.GetBody() != PRBody(plan) {
+		t.Errorf("edit = %q / %q, want the new plan's title and body", edit.GetTitle(), edit.GetBody())
+	}
+
+	var update github.UpdateRef
+	fake.body(t, "PATCH /repos/testorg/repo1/git/refs/heads/fix-ci", &update)
+	if update.SHA != "newcommit" || update.Force == nil || !*update.Force {
+		t.Errorf("update = %+v, want forced update to newcommit", update)
+	}
+	if fake.called("POST /repos/testorg/repo1/pulls") {
+		t.Error("opened a second pull request")
+	}
+}
+
+func TestPRApplierApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    func() model.RemediationPlan
+		wantErr bool
+	}{
+		{"dry run", func() model.RemediationPlan { p := testPlan(); p.DryRun = true; return p }, false},
+		{"no patches", func() model.RemediationPlan { p := testPlan(); p.Patches = nil; return p }, true},
+		{"missing base branch", func() model.RemediationPlan { p := testPlan(); p.Repo.DefaultBranch = "develop"; return p }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeGitHub(t, gitDataRoutes())
+			result, err := NewPRApplier(client, PRConfig{}).Apply(context.Background(), tt.plan())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (result.Success || result.Error == "") {
+				t.Errorf("result = %+v, want recorded failure", result)
+			}
+			if !tt.wantErr && len(fake.requests) > 0 {
+				t.Errorf("dry run made requests: %v", fake.requests)
+			}
+		})
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	plan := NewPlan(model.Repo{FullName: "testorg/repo1"}, []GeneratedFile{
+		{RelativePath: ".github/workflows/go-ci.yaml", Content: "ci", IsNew: true},
+	})
+	if len(plan.Patches) != 1 || plan.Patches[0].Operation != "create" {
+		t.Fatalf("patches = %+v", plan.Patches)
+	}
+	if plan.PRTitle != "ci: create .github/workflows/go-ci.yaml" {
+		t.Errorf("PRTitle = %q", plan.PRTitle)
+	}
+}
+
+func TestPRApplierPRStatus(t *testing.T) {
+	repo := model.Repo{Owner: "testorg", Name: "repo1", FullName: "testorg/repo1"}
+	tests := []struct {
+		name   string
+		routes map[string]string
+		links  map[string]string
+		want   PRStatus
+	}{
+		{
+			name: "merged",
+			routes: map[string]string{
+				"GET /repos/testorg/repo1/pulls/5": `{"number":5,"state":"closed","merged":true}`,
+			},
+			want: PRStatus{State: model.PRStateMerged},
+		},
+		{
+			name: "open with failing checks and conflicts",
+			routes: map[string]string{
+				"GET /repos/testorg/repo1/pulls/5":                `{"number":5,"state":"open","mergeable_state":"dirty","head":{"sha":"abc"}}`,
+				"GET /repos/testorg/repo1/commits/abc/check-runs": `{"total_count":2,"check_runs":[{"conclusion":"success"},{"conclusion":"failure"}]}`,
+				"GET /repos/testorg/repo1/commits/abc/status":     `{"state":"success"}`,
+			},
+			want: PRStatus{State: model.PRStateOpen, ChecksFailing: true, Conflicts: true},
+		},
+		{
+			name: "open with failing commit status",
+			routes: map[string]string{
+				"GET /repos/testorg/repo1/pulls/5":                `{"number":5,"state":"open","mergeable_state":"clean","head":{"sha":"abc"}}`,
+				"GET /repos/testorg/repo1/commits/abc/check-runs": `{"total_count":0,"check_runs":[]}`,
+				"GET /repos/testorg/repo1/commits/abc/status":     `{"state":"failure"}`,
+			},
+			want: PRStatus{State: model.PRStateOpen, ChecksFailing: true},
+		},
+		{
+			name: "open with failing check run on a later page",
+			routes: map[string]string{
+				"GET /repos/testorg/repo1/pulls/5":                                    `{"number":5,"state":"open","mergeable_state":"clean","head":{"sha":"abc"}}`,
+				"GET /repos/testorg/repo1/commits/abc/check-runs?per_page=100":        `{"total_count":101,"check_runs":[{"conclusion":"success"}]}`,
+				"GET /repos/testorg/repo1/commits/abc/check-runs?page=2&per_page=100": `{"total_count":101,"check_runs":[{"conclusion":"failure"}]}`,
+				"GET /repos/testorg/repo1/commits/abc/status":                         `{"state":"success"}`,
+			},
+			links: map[string]string{
+				"GET /repos/testorg/repo1/commits/abc/check-runs?per_page=100": `<https://api.github.com/repos/testorg/repo1/commits/abc/check-runs?page=2&per_page=100>; rel="next"`,
+			},
+			want: PRStatus{State: model.PRStateOpen, ChecksFailing: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeGitHub(t, tt.routes)
+			fake.links = tt.links
+			got, err := NewPRApplier(client, PRConfig{}).PRStatus(context.Background(), repo, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("PRStatus() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPRApplierClosePR(t *testing.T) {
+	f, client := newFakeGitHub(t, map[string]string{
+		"POST /repos/testorg/repo1/issues/5/comments": `{"id":1}`,
+		"PATCH /repos/testorg/repo1/pulls/5":          `{"number":5,"state":"closed"}`,
+	})
+	repo := model.Repo{Owner: "testorg", Name: "repo1", FullName: "testorg/repo1"}
+
+	// The branch is already gone; the 404 is ignored.
+	if err := NewPRApplier(client, PRConfig{}).ClosePR(context.Background(), repo, 5, "fix", "stale"); err != nil {
+		t.Fatal(err)
+	}
+	var edit github.PullRequest
+	f.body(t, "PATCH /repos/testorg/repo1/pulls/5", &edit)
+	if edit.GetState() != "closed" {
+		t.Errorf("edit = %+v, want state closed", edit)
+	}
+	if !f.called("DELETE /repos/testorg/repo1/git/refs/heads/fix") {
+		t.Error("branch not deleted")
+	}
+}
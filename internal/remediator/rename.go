@@ -0,0 +1,109 @@
+package remediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// FileGetter reads repository files. The collectors satisfy it. Errors
+// for missing files must match fs.ErrNotExist.
+type FileGetter interface {
+	GetFileContent(ctx context.Context, repo model.Repo, path string) (string, error)
+}
+
+// readmePaths are the README files whose badges are updated on rename,
+// in order of preference.
+var readmePaths = []string{"README.md", "readme.md", "README"}
+
+// NormalizeFilenames returns a plan that renames workflows matching a
+// required workflow, exactly or equivalently, but under a non-standard
+// filename to the expected one. Each rename is a delete patch for the old
+// path followed by a create patch for the new one. References to the old
+// path inside the workflow, such as its own path trigger, are updated,
+// and README status badges and links that point at the old file are
+// updated in a single README patch. Workflows whose expected path is
+// already taken are left alone. The plan has no patches when nothing
+// needs renaming.
+func NormalizeFilenames(ctx context.Context, files FileGetter, repo model.Repo, checks []model.WorkflowCheck) (model.RemediationPlan, error) {
+	plan := model.RemediationPlan{Repo: repo}
+
+	readmePath, readme, hasReadme := "", "", false
+	for _, p := range readmePaths {
+		content, err := files.GetFileContent(ctx, repo, p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return plan, fmt.Errorf("reading %s: %w", p, err)
+		}
+		readmePath, readme, hasReadme = p, content, true
+		break
+	}
+	updatedReadme := readme
+
+	renamed := make(map[string]bool)
+	for _, wc := range checks {
+		if !wc.Present || !wc.FilenameMismatch || wc.ActualWorkflow == "" || wc.ExpectedFilename == "" {
+			continue
+		}
+		if wc.MatchType != model.MatchTypeExact && wc.MatchType != model.MatchTypeEquivalent {
+			continue
+		}
+		oldPath := wc.ActualWorkflow
+		newPath := path.Join(path.Dir(oldPath), wc.ExpectedFilename)
+		if oldPath == newPath || renamed[oldPath] || renamed[newPath] {
+			continue
+		}
+		if _, err := files.GetFileContent(ctx, repo, newPath); err == nil {
+			continue
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return plan, fmt.Errorf("reading %s: %w", newPath, err)
+		}
+
+		content, err := files.GetFileContent(ctx, repo, oldPath)
+		if err != nil {
+			return plan, fmt.Errorf("reading %s: %w", oldPath, err)
+		}
+		plan.Patches = append(plan.Patches,
+			model.Patch{Path: oldPath, Operation: "delete"},
+			model.Patch{Path: newPath, Operation: "create", Content: replaceReference(content, oldPath, newPath)},
+		)
+		renamed[oldPath], renamed[newPath] = true, true
+
+		if hasReadme {
+			updatedReadme = replaceBadge(updatedReadme, path.Base(oldPath), path.Base(newPath))
+		}
+	}
+
+	if updatedReadme != readme {
+		plan.Patches = append(plan.Patches, model.Patch{
+			Path:      readmePath,
+			Operation: "update",
+			Content:   updatedReadme,
+			Diff:      UnifiedDiff(readmePath, readme, updatedReadme),
+		})
+	}
+	if len(plan.Patches) > 0 {
+		plan.PRTitle = "ci: rename workflows to standard filenames"
+		plan.PRBody = PRBody(plan)
+	}
+	return plan, nil
+}
+
+// replaceReference replaces whole-path occurrences of oldPath in content.
+func replaceReference(content, oldPath, newPath string) string {
+	re := regexp.MustCompile(regexp.QuoteMeta(oldPath) + `([^\w.-]|$)`)
+	return re.ReplaceAllString(content, newPath+"$1")
+}
+
+// replaceBadge points GitHub Actions badge and workflow URLs at a renamed
+// workflow file.
+func replaceBadge(readme, oldName, newName string) string {
+	re := regexp.MustCompile(`/actions/workflows/` + regexp.QuoteMeta(oldName) + `([^\w.-]|$)`)
+	return re.ReplaceAllString(readme, "/actions/workflows/"+newName+"$1")
+}
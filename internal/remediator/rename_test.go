@@ -0,0 +1,111 @@
+package remediator
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+type fakeFiles map[string]string
+
+func (f fakeFiles) GetFileContent(_ context.Context, _ model.Repo, path string) (string, error) {
+	if msg, ok := f["error:"+path]; ok {
+		return "", errors.New(msg)
+	}
+	if content, ok := f[path]; ok {
+		return content, nil
+	}
+	return "", fs.ErrNotExist
+}
+
+func TestNormalizeFilenames(t *testing.T) {
+	files := fakeFiles{
+		".github/workflows/ci.yml": `name: CI
+on:
+  push:
+    paths:
+      - "**.go"
+      - ".github/workflows/ci.yml"
+jobs:
+  ci:
+    uses: myorg/.github/.github/workflows/go-ci.yaml@main
+`,
+		".github/workflows/lint.yml":            "name: Lint\njobs: {}\n",
+		".github/workflows/codeql.yml":          "name: CodeQL\njobs: {}\n",
+		".github/workflows/go-sast-codeql.yaml": "name: existing\n",
+		"README.md": "[![CI](https://github.com/myorg/repo1/actions/workflows/ci.yml/badge.svg)](https://github.com/myorg/repo1/actions/workflows/ci.yml)\n" +
+			"[![Lint](https://github.com/myorg/repo1/actions/workflows/lint.yml/badge.svg)](https://github.com/myorg/repo1/actions/workflows/lint.yml?query=branch%3Amain)\n" +
+			"See .github/workflows/ci.yml.bak\n",
+	}
+	checks := []model.WorkflowCheck{
+		{WorkflowType: "go-ci", Present: true, MatchType: model.MatchTypeExact, ActualWorkflow: ".github/workflows/ci.yml", FilenameMismatch: true, ExpectedFilename: "go-ci.yaml"},
+		{WorkflowType: "go-lint", Present: true, MatchType: model.MatchTypeEquivalent, ActualWorkflow: ".github/workflows/lint.yml", FilenameMismatch: true, ExpectedFilename: "go-lint.yaml"},
+		// The expected path is taken, so this one is left alone.
+		{WorkflowType: "go-sast-codeql", Present: true, MatchType: model.MatchTypeEquivalent, ActualWorkflow: ".github/workflows/codeql.yml", FilenameMismatch: true, ExpectedFilename: "go-sast-codeql.yaml"},
+		{WorkflowType: "ts-ci", Present: true, MatchType: model.MatchTypeExact, ActualWorkflow: ".github/workflows/ts-ci.yaml", ExpectedFilename: "ts-ci.yaml"},
+		{WorkflowType: "ts-lint", MatchType: model.MatchTypeNone, ExpectedFilename: "ts-lint.yaml"},
+	}
+
+	plan, err := NormalizeFilenames(context.Background(), files, model.Repo{FullName: "myorg/repo1"}, checks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ops []string
+	for _, p := range plan.Patches {
+		ops = append(ops, p.Operation+" "+p.Path)
+	}
+	want := []string{
+		"delete .github/workflows/ci.yml",
+		"create .github/workflows/go-ci.yaml",
+		"delete .github/workflows/lint.yml",
+		"create .github/workflows/go-lint.yaml",
+		"update README.md",
+	}
+	if strings.Join(ops, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("patches:\n%s\nwant:\n%s", strings.Join(ops, "\n"), strings.Join(want, "\n"))
+	}
+
+	ci := plan.Patches[1].Content
+	if !strings.Contains(ci, `- ".github/workflows/go-ci.yaml"`) || strings.Contains(ci, "ci.yml") {
+		t.Errorf("self-reference not updated:\n%s", ci)
+	}
+
+	readme := plan.Patches[4].Content
+	for _, want := range []string{
+		"actions/workflows/go-ci.yaml/badge.svg)](https://github.com/myorg/repo1/actions/workflows/go-ci.yaml)",
+		"actions/workflows/go-lint.yaml/badge.svg)](https://github.com/myorg/repo1/actions/workflows/go-lint.yaml?query=",
+		"See .github/workflows/ci.yml.bak",
+	} {
+		if !strings.Contains(readme, want) {
+			t.Errorf("README missing %q:\n%s", want, readme)
+		}
+	}
+	if plan.Patches[4].Diff == "" || plan.PRTitle == "" {
+		t.Errorf("README patch diff %q, title %q", plan.Patches[4].Diff, plan.PRTitle)
+	}
+}
+
+func TestNormalizeFilenamesNothingToDo(t *testing.T) {
+	checks := []model.WorkflowCheck{{WorkflowType: "go-ci", Present: true, MatchType: model.MatchTypeExact, ActualWorkflow: ".github/workflows/go-ci.yaml"}}
+	plan, err := NormalizeFilenames(context.Background(), fakeFiles{}, model.Repo{}, checks)
+	if err != nil || len(plan.Patches) != 0 {
+		t.Errorf("NormalizeFilenames() = %+v, %v; want no patches", plan.Patches, err)
+	}
+}
+
+func TestNormalizeFilenamesReadErrors(t *testing.T) {
+	checks := []model.WorkflowCheck{{WorkflowType: "go-ci", Present: true, MatchType: model.MatchTypeExact, ActualWorkflow: ".github/workflows/ci.yml", FilenameMismatch: true, ExpectedFilename: "go-ci.yaml"}}
+	// A failed read is not taken to mean the file is missing.
+	for _, path := range []string{"README.md", ".github/workflows/go-ci.yaml"} {
+		files := fakeFiles{".github/workflows/ci.yml": "name: CI\njobs: {}\n", "error:" + path: "rate limited"}
+		plan, err := NormalizeFilenames(context.Background(), files, model.Repo{FullName: "myorg/repo1"}, checks)
+		if err == nil || !strings.Contains(err.Error(), "rate limited") || len(plan.Patches) != 0 {
+			t.Errorf("NormalizeFilenames() with %s unreadable = %+v, %v; want read error", path, plan.Patches, err)
+		}
+	}
+}
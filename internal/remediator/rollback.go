@@ -0,0 +1,202 @@
+package remediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// rollbackBranchSuffix is appended to the remediation branch to name the
+// branch rollback pull requests are opened from.
+const rollbackBranchSuffix = "-rollback"
+
+// LocalResult records the files the generator wrote to a repository's
+// local checkout, with their pre-change contents, so they can be restored
+// with RestoreLocal.
+func LocalResult(repo model.Repo, files []GeneratedFile, dryRun bool) *model.RemediationResult {
+	result := &model.RemediationResult{Repo: repo, Success: true, DryRun: dryRun}
+	for _, f := range files {
+		result.Previous = append(result.Previous, model.FileSnapshot{
+			Path:    filepath.ToSlash(f.RelativePath),
+			Existed: !f.IsNew,
+			Content: f.Previous,
+		})
+	}
+	return result
+}
+
+// RevertPlan returns the plan that restores the files changed by an
+// applied remediation to their recorded pre-change contents. Files the
+// remediation created are deleted.
+func RevertPlan(applied *model.RemediationResult) model.RemediationPlan {
+	plan := model.RemediationPlan{Repo: applied.Repo, DryRun: applied.DryRun}
+	for _, snap := range applied.Previous {
+		if snap.Existed {
+			plan.Patches = append(plan.Patches, model.Patch{Path: snap.Path, Operation: "update", Content: snap.Content})
+		} else {
+			plan.Patches = append(plan.Patches, model.Patch{Path: snap.Path, Operation: "delete"})
+		}
+	}
+
+	plan.PRTitle = "ci: revert PipelineConductor remediation"
+	if applied.PRNumber != 0 {
+		plan.PRTitle += fmt.Sprintf(" (#%d)", applied.PRNumber)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("This pull request was opened by PipelineConductor to roll back a remediation")
+	if applied.CommitSHA != "" {
+		sb.WriteString(fmt.Sprintf(" applied in %s", applied.CommitSHA))
+	}
+	sb.WriteString(". It restores the files to their contents before the remediation.\n\n")
+	sb.WriteString("**Changes:**\n\n")
+	for _, p := range plan.Patches {
+		verb := "restore"
+		if p.Operation == "delete" {
+			verb = "delete"
+		}
+		sb.WriteString(fmt.Sprintf("- %s `%s`\n", verb, p.Path))
+	}
+	plan.PRBody = sb.String()
+	return plan
+}
+
+// RestoreLocal rolls back a remediation applied to a local checkout by
+// restoring the recorded pre-change contents. Files the remediation
+// created are removed. The returned result records the failure when err
+// is non-nil.
+func RestoreLocal(applied *model.RemediationResult) (*model.RemediationResult, error) {
+	result := &model.RemediationResult{Repo: applied.Repo, Rollback: true, DryRun: applied.DryRun}
+	if err := restoreLocal(applied); err != nil {
+		err = fmt.Errorf("rolling back %s: %w", applied.Repo.FullName, err)
+		result.Error = err.Error()
+		return result, err
+	}
+	result.Success = true
+	result.Previous = applied.Previous
+	return result, nil
+}
+
+func restoreLocal(applied *model.RemediationResult) error {
+	root := applied.Repo.LocalPath
+	if root == "" {
+		return errors.New("repo has no local path")
+	}
+	if applied.DryRun {
+		return nil
+	}
+	for _, snap := range applied.Previous {
+		path := filepath.Join(root, filepath.FromSlash(snap.Path))
+		if !snap.Existed {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("removing %s: %w", snap.Path, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", snap.Path, err)
+		}
+		if err := os.WriteFile(path, []byte(snap.Content), 0644); err != nil { //nolint:gosec // G306: workflow files need to be readable
+			return fmt.Errorf("restoring %s: %w", snap.Path, err)
+		}
+	}
+	return nil
+}
+
+// Rollback undoes a remediation applied as a pull request. An open pull
+// request is closed and its branch deleted. A merged one is reverted by a
+// pull request that restores the recorded pre-change contents, unless the
+// files have changed since, as reverting them would discard those edits. A
+// pull request closed without merging needs no rollback. The returned result
+// records the failure when err is non-nil.
+func (a *PRApplier) Rollback(ctx context.Context, applied *model.RemediationResult) (*model.RemediationResult, error) {
+	result := &model.RemediationResult{Repo: applied.Repo, Rollback: true, DryRun: applied.DryRun}
+	if applied.DryRun {
+		result.Success = true
+		return result, nil
+	}
+	if err := a.rollback(ctx, applied, result); err != nil {
+		err = fmt.Errorf("rolling back %s: %w", applied.Repo.FullName, err)
+		result.Error = err.Error()
+		return result, err
+	}
+	result.Success = true
+	return result, nil
+}
+
+func (a *PRApplier) rollback(ctx context.Context, applied *model.RemediationResult, result *model.RemediationResult) error {
+	if applied.PRNumber == 0 {
+		return errors.New("no pull request recorded")
+	}
+	state, err := a.PRState(ctx, applied.Repo, applied.PRNumber)
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case model.PRStateOpen:
+		comment := "Closing this remediation pull request: the remediation was rolled back."
+		return a.ClosePR(ctx, applied.Repo, applied.PRNumber, applied.Branch, comment)
+	case model.PRStateMerged:
+		if len(applied.Previous) == 0 {
+			return errors.New("no pre-change contents recorded")
+		}
+		if err := a.checkDrift(ctx, applied); err != nil {
+			return err
+		}
+		revert := &PRApplier{client: a.client, Config: a.Config}
+		// Name the revert branch after the remediation's own branch, which
+		// may differ from the configured one.
+		revert.Config.Branch = a.Config.Branch + rollbackBranchSuffix
+		if applied.Branch != "" {
+			revert.Config.Branch = applied.Branch + rollbackBranchSuffix
+		}
+		revert.Config.CommitMessage = ""
+		pr, err := revert.apply(ctx, RevertPlan(applied), result)
+		if err != nil {
+			return err
+		}
+		result.PRURL = pr.GetHTMLURL()
+		result.PRNumber = pr.GetNumber()
+		result.Branch = revert.Config.Branch
+	}
+	return nil
+}
+
+// checkDrift returns an error when a file changed by a merged remediation
+// no longer matches its contents in the remediation commit.
+func (a *PRApplier) checkDrift(ctx context.Context, applied *model.RemediationResult) error {
+	if applied.CommitSHA == "" {
+		return errors.New("no remediation commit recorded")
+	}
+	owner, name := applied.Repo.Owner, applied.Repo.Name
+	patches := make([]model.Patch, 0, len(applied.Previous))
+	for _, snap := range applied.Previous {
+		patches = append(patches, model.Patch{Path: snap.Path})
+	}
+
+	want, err := a.snapshot(ctx, owner, name, applied.CommitSHA, patches)
+	if err != nil {
+		return err
+	}
+	got, err := a.snapshot(ctx, owner, name, baseBranch(applied.Repo), patches)
+	if err != nil {
+		return err
+	}
+	var drifted []string
+	for i := range want {
+		if want[i] != got[i] {
+			drifted = append(drifted, want[i].Path)
+		}
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("files changed since the remediation was merged: %s", strings.Join(drifted, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package remediator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+func TestRestoreLocal(t *testing.T) {
+	repoDir := t.TempDir()
+	workflowsDir := filepath.Join(repoDir, ".github", "workflows")
+	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lintPath := filepath.Join(workflowsDir, "go-lint.yaml")
+	if err := os.WriteFile(lintPath, []byte("name: old lint\n"), 0644); err != nil { //nolint:gosec // test fixture
+		t.Fatal(err)
+	}
+
+	repo := model.Repo{Owner: "testorg", Name: "repo1", FullName: "testorg/repo1", LocalPath: repoDir}
+	gen := NewGenerator(GeneratorConfig{RefRepo: "testorg/.github"})
+	files, err := gen.GenerateForRepo(repo, []model.MissingWorkflow{{WorkflowType: "go-ci"}, {WorkflowType: "go-lint"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied := LocalResult(repo, files, false)
+	result, err := RestoreLocal(applied)
+	if err != nil {
+		t.Fatalf("RestoreLocal() error = %v", err)
+	}
+	if !result.Success || !result.Rollback {
+		t.Errorf("result = %+v", result)
+	}
+
+	if _, err := os.Stat(filepath.Join(workflowsDir, "go-ci.yaml")); !os.IsNotExist(err) {
+		t.Error("created go-ci.yaml was not removed")
+	}
+	if content, err := os.ReadFile(lintPath); err != nil || string(content) != "name: old lint\n" {
+		t.Errorf("go-lint.yaml = %q, %v; want original content", content, err)
+	}
+
+	if _, err := RestoreLocal(&model.RemediationResult{Repo: model.Repo{FullName: "testorg/repo2"}}); err == nil {
+		t.Error("RestoreLocal() without a local path expected error")
+	}
+}
+
+func appliedPR() *model.RemediationResult {
+	return &model.RemediationResult{
+		Repo:      model.Repo{Owner: "testorg", Name: "repo1", FullName: "testorg/repo1", DefaultBranch: "main"},
+		Success:   true,
+		PRNumber:  42,
+		Branch:    DefaultPRBranch,
+		CommitSHA: "applied",
+		Previous: []model.FileSnapshot{
+			{Path: ".github/workflows/go-ci.yaml"},
+			{Path: ".github/workflows/ci.yml", Existed: true, Content: "name: CI\n"},
+		},
+	}
+}
+
+func TestPRApplierRollbackOpen(t *testing.T) {
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/testorg/repo1/pulls/42":            `{"number":42,"state":"open"}`,
+		"POST /repos/testorg/repo1/issues/42/comments": `{"id":1}`,
+		"PATCH /repos/testorg/repo1/pulls/42":          `{"number":42,"state":"closed"}`,
+	})
+
+	result, err := NewPRApplier(client, PRConfig{}).Rollback(context.Background(), appliedPR())
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if !result.Success || !result.Rollback || result.PRNumber != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if !fake.called("DELETE /repos/testorg/repo1/git/refs/heads/" + DefaultPRBranch) {
+		t.Error("remediation branch not deleted")
+	}
+}
+
+func TestPRApplierRollbackMerged(t *testing.T) {
+	routes := gitDataRoutes()
+	routes["GET /repos/testorg/repo1/pulls/42"] = `{"number":42,"state":"closed","merged":true}`
+	routes["POST /repos/testorg/repo1/git/refs"] = `{"ref":"refs/heads/pipelineconductor/remediation-rollback"}`
+	routes["POST /repos/testorg/repo1/pulls"] = `{"number":43,"html_url":"https://github.com/testorg/repo1/pull/43"}`
+	// The created file is unchanged since the remediation commit.
+	routes["GET /repos/testorg/repo1/contents/.github/workflows/go-ci.yaml"] = `{"type":"file","encoding":"base64","content":"bmFtZTogR28gQ0kK"}`
+	fake, client := newFakeGitHub(t, routes)
+
+	// The remediation was applied with a different branch configured.
+	cfg := PRConfig{Branch: "ci/other", CommitMessage: "ci: remediate"}
+	result, err := NewPRApplier(client, cfg).Rollback(context.Background(), appliedPR())
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if !result.Rollback || result.PRNumber != 43 || result.Branch != DefaultPRBranch+"-rollback" || result.CommitSHA != "newcommit" {
+		t.Errorf("result = %+v", result)
+	}
+	var ref struct {
+		Ref string `json:"ref"`
+	}
+	fake.body(t, "POST /repos/testorg/repo1/git/refs", &ref)
+	if ref.Ref != "refs/heads/"+DefaultPRBranch+"-rollback" {
+		t.Errorf("revert branch = %q, want one named after the remediation branch", ref.Ref)
+	}
+
+	var tree struct {
+		Tree []struct {
+			Path    string  `json:"path"`
+			Content *string `json:"content"`
+		} `json:"tree"`
+	}
+	fake.body(t, "POST /repos/testorg/repo1/git/trees", &tree)
+	if len(tree.Tree) != 2 || tree.Tree[0].Content != nil || tree.Tree[1].Content == nil || *tree.Tree[1].Content != "name: CI\n" {
+		t.Errorf("tree = %+v, want go-ci.yaml deleted and ci.yml restored", tree)
+	}
+
+	var commit struct {
+		Message string `json:"message"`
+	}
+	fake.body(t, "POST /repos/testorg/repo1/git/commits", &commit)
+	if commit.Message != "ci: revert PipelineConductor remediation (#42)" {
+		t.Errorf("commit message = %q", commit.Message)
+	}
+}
+
+func TestPRApplierRollbackDrifted(t *testing.T) {
+	routes := gitDataRoutes()
+	routes["GET /repos/testorg/repo1/pulls/42"] = `{"number":42,"state":"closed","merged":true}`
+	routes["GET /repos/testorg/repo1/contents/.github/workflows/go-ci.yaml?ref=applied"] = `{"type":"file","encoding":"base64","content":"bmFtZTogR28gQ0kK"}`
+	routes["GET /repos/testorg/repo1/contents/.github/workflows/go-ci.yaml?ref=main"] = `{"type":"file","encoding":"base64","content":"bmFtZTogR28gQ0kgIyBlZGl0ZWQK"}`
+	fake, client := newFakeGitHub(t, routes)
+
+	result, err := NewPRApplier(client, PRConfig{}).Rollback(context.Background(), appliedPR())
+	if err == nil || !strings.Contains(err.Error(), ".github/workflows/go-ci.yaml") {
+		t.Fatalf("Rollback() error = %v, want go-ci.yaml drifted", err)
+	}
+	if result.Success || result.Error == "" {
+		t.Errorf("result = %+v, want failure recorded", result)
+	}
+	if fake.called("POST /repos/testorg/repo1/git/trees") {
+		t.Error("revert commit created for a drifted file")
+	}
+}
+
+func TestPRApplierRollbackClosed(t *testing.T) {
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/testorg/repo1/pulls/42": `{"number":42,"state":"closed"}`,
+	})
+
+	result, err := NewPRApplier(client, PRConfig{}).Rollback(context.Background(), appliedPR())
+	if err != nil || !result.Success {
+		t.Fatalf("Rollback() = %+v, %v", result, err)
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("requests = %v, want only the pull request lookup", fake.requests)
+	}
+}
@@ -0,0 +1,300 @@
+package remediator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-github/v84/github"
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRefTemplateDir is the reference repository directory that holds
+// workflow templates, as used for GitHub starter workflows.
+const DefaultRefTemplateDir = "workflow-templates"
+
+// defaultBranchPlaceholder is GitHub's starter workflow placeholder for
+// the repository's default branch. It is replaced in plain templates.
+const defaultBranchPlaceholder = "$default-branch"
+
+// ErrNotTemplate is returned by ParseTemplate for files that have no
+// front matter and are not workflows.
+var ErrNotTemplate = errors.New("not a workflow template")
+
+// templateLanguages maps filename prefixes to the languages reported by
+// the collectors, following the recipes' <language>-<action>.yaml names.
+var templateLanguages = map[string]string{
+	"go":      "Go",
+	"ts":      "TypeScript",
+	"crystal": "Crystal",
+	"python":  "Python",
+	"swift":   "Swift",
+}
+
+// templateMeta is the front matter of a workflow template.
+type templateMeta struct {
+	Type        string   `yaml:"type"`
+	Name        string   `yaml:"name"`
+	Language    string   `yaml:"language"`
+	Filename    string   `yaml:"filename"`
+	Description string   `yaml:"description"`
+	Variables   []string `yaml:"variables"`
+	Paths       []string `yaml:"paths"`
+	Delims      []string `yaml:"delims"`
+}
+
+// ParseTemplate parses a workflow template file. Templates start with
+// YAML front matter between "---" lines giving the type, name, language,
+// output filename, description, required TemplateData variables and
+// source path filters, and optionally the template delimiters:
+//
+//	---
+//	type: go-ci
+//	language: Go
+//	variables: [GoVersion]
+//	delims: ["[[", "]]"]
+//	---
+//	name: Go CI
+//	...
+//
+// The body is a text/template rendered with TemplateData. Missing
+// metadata is taken from the file name, so go-ci.yaml has type go-ci and
+// language Go. Files without front matter, such as the recipes, are used
+// as-is apart from GitHub's $default-branch placeholder, the trigger
+// branches, which are set to the default and release branches, and the
+// configured path filters; those that are not workflows return
+// ErrNotTemplate.
+func ParseTemplate(filename string, content []byte) (*WorkflowTemplate, error) {
+	base := path.Base(filename)
+	wt := &WorkflowTemplate{Filename: base, Type: strings.TrimSuffix(base, path.Ext(base))}
+
+	meta, body, ok, err := splitFrontMatter(content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing front matter of %s: %w", filename, err)
+	}
+	if ok {
+		if meta.Type != "" {
+			wt.Type = meta.Type
+		}
+		if meta.Filename != "" {
+			wt.Filename = meta.Filename
+		}
+		wt.Name = meta.Name
+		wt.Language = meta.Language
+		wt.Description = meta.Description
+		wt.Variables = meta.Variables
+		wt.Paths = meta.Paths
+		switch len(meta.Delims) {
+		case 0:
+		case 2:
+			wt.LeftDelim, wt.RightDelim = meta.Delims[0], meta.Delims[1]
+		default:
+			return nil, fmt.Errorf("template %s: delims must be a pair", filename)
+		}
+		for _, v := range wt.Variables {
+			if f, ok := reflect.TypeFor[TemplateData]().FieldByName(v); !ok || f.Type.Kind() != reflect.String {
+				return nil, fmt.Errorf("template %s: unknown variable %q", filename, v)
+			}
+		}
+	} else {
+		var workflow struct {
+			Name string `yaml:"name"`
+			Jobs any    `yaml:"jobs"`
+		}
+		if err := yaml.Unmarshal(content, &workflow); err != nil || workflow.Jobs == nil {
+			return nil, fmt.Errorf("%s: %w", filename, ErrNotTemplate)
+		}
+		wt.Name = workflow.Name
+		wt.Raw = true
+	}
+	wt.Template = string(body)
+
+	if wt.Name == "" {
+		wt.Name = wt.Type
+	}
+	if wt.Language == "" {
+		prefix, _, _ := strings.Cut(wt.Type, "-")
+		wt.Language = templateLanguages[prefix]
+	}
+	return wt, nil
+}
+
+// splitFrontMatter separates front matter from the template body. ok is
+// false when there is no front matter.
+func splitFrontMatter(content []byte) (meta templateMeta, body []byte, ok bool, err error) {
+	rest, found := bytes.CutPrefix(content, []byte("---\n"))
+	if !found {
+		return meta, content, false, nil
+	}
+	for offset := 0; offset < len(rest); {
+		line, _, _ := bytes.Cut(rest[offset:], []byte("\n"))
+		next := offset + len(line) + 1
+		if string(bytes.TrimRight(line, "\r")) == "---" {
+			if err := yaml.Unmarshal(rest[:offset], &meta); err != nil {
+				return meta, nil, false, err
+			}
+			return meta, rest[min(next, len(rest)):], true, nil
+		}
+		offset = next
+	}
+	return meta, nil, false, errors.New("unterminated front matter")
+}
+
+// LoadTemplates parses every .yaml and .yml file in fsys, such as
+// os.DirFS("recipes"). Files that are not templates are skipped.
+func LoadTemplates(fsys fs.FS) ([]*WorkflowTemplate, error) {
+	var templates []*WorkflowTemplate
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isYAML(p) {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		wt, err := ParseTemplate(p, content)
+		if errors.Is(err, ErrNotTemplate) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		templates = append(templates, wt)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading templates: %w", err)
+	}
+	return templates, nil
+}
+
+// LoadTemplatesDir loads the templates in a directory and its
+// subdirectories.
+func LoadTemplatesDir(dir string) ([]*WorkflowTemplate, error) {
+	return LoadTemplates(os.DirFS(dir))
+}
+
+// LoadRefRepoTemplates loads the templates in dir of the reference
+// repository (owner/repo) at ref. An empty dir uses DefaultRefTemplateDir.
+func LoadRefRepoTemplates(ctx context.Context, client *github.Client, refRepo, ref, dir string) ([]*WorkflowTemplate, error) {
+	owner, name, ok := strings.Cut(refRepo, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid reference repository %q", refRepo)
+	}
+	if dir == "" {
+		dir = DefaultRefTemplateDir
+	}
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+
+	_, entries, _, err := client.Repositories.GetContents(ctx, owner, name, dir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("listing templates in %s/%s: %w", refRepo, dir, err)
+	}
+	var templates []*WorkflowTemplate
+	for _, entry := range entries {
+		if entry.GetType() != "file" || !isYAML(entry.GetName()) {
+			continue
+		}
+		file, _, _, err := client.Repositories.GetContents(ctx, owner, name, entry.GetPath(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("reading template %s: %w", entry.GetPath(), err)
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("decoding template %s: %w", entry.GetPath(), err)
+		}
+		wt, err := ParseTemplate(entry.GetPath(), []byte(content))
+		if errors.Is(err, ErrNotTemplate) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, wt)
+	}
+	return templates, nil
+}
+
+func isYAML(name string) bool {
+	ext := path.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// missingVariables returns the template's required variables that are
+// empty in data.
+func (wt *WorkflowTemplate) missingVariables(data TemplateData) []string {
+	var missing []string
+	v := reflect.ValueOf(data)
+	for _, name := range wt.Variables {
+		if f := v.FieldByName(name); !f.IsValid() || f.IsZero() {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// projectManifests are the files that mark a language's project root.
+var projectManifests = map[string]string{
+	"Go":         "go.mod",
+	"TypeScript": "package.json",
+}
+
+// projectDir returns the slash-separated directory under root holding the
+// language's project, or "" when it is at the root or not found.
+// Monorepo subfolders are searched two levels deep, shallowest first.
+func projectDir(root, language string) string {
+	manifest, ok := projectManifests[language]
+	if root == "" || !ok || fileExists(filepath.Join(root, manifest)) {
+		return ""
+	}
+	level := []string{""}
+	for range 2 {
+		var next []string
+		for _, dir := range level {
+			entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				name := e.Name()
+				if !e.IsDir() || strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "testdata" {
+					continue
+				}
+				sub := path.Join(dir, name)
+				if fileExists(filepath.Join(root, filepath.FromSlash(sub), manifest)) {
+					return sub
+				}
+				next = append(next, sub)
+			}
+		}
+		level = next
+	}
+	return ""
+}
+
+// goVersion returns the go directive of a go.mod file, or "" if there is
+// none.
+func goVersion(goModPath string) string {
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return ""
+	}
+	f, err := modfile.Parse(goModPath, content, nil)
+	if err != nil || f.Go == nil {
+		return ""
+	}
+	return f.Go.Version
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,209 @@
+package remediator
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+const goReleaseTemplate = `---
+type: go-release
+name: Go Release
+filename: release.yaml
+description: Release with GoReleaser
+variables: [GoVersion]
+delims: ["[[", "]]"]
+---
+name: Go Release
+on:
+  push:
+    tags: ["v*"]
+jobs:
+  release:
+    uses: [[.RefRepo]]/.github/workflows/go-release.yaml@[[.RefBranch]]
+    with:
+      go-version: "[[.GoVersion]]"
+      tag: ${{ github.ref_name }}
+`
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     WorkflowTemplate
+		wantErr  error
+	}{
+		{
+			name:     "front matter",
+			filename: "templates/release.tmpl.yaml",
+			content:  goReleaseTemplate,
+			want: WorkflowTemplate{
+				Name: "Go Release", Filename: "release.yaml", Language: "Go", Type: "go-release",
+				Description: "Release with GoReleaser", Variables: []string{"GoVersion"}, LeftDelim: "[[", RightDelim: "]]",
+			},
+		},
+		{
+			name:     "metadata from filename",
+			filename: "ts-test.yaml",
+			content:  "---\n---\nname: x\n",
+			want:     WorkflowTemplate{Name: "ts-test", Filename: "ts-test.yaml", Language: "TypeScript", Type: "ts-test"},
+		},
+		{
+			name:     "plain workflow",
+			filename: "go/go-ci.yaml",
+			content:  "name: Go CI\non: push\njobs:\n  ci:\n    uses: org/.github/.github/workflows/go-ci.yaml@main\n",
+			want:     WorkflowTemplate{Name: "Go CI", Filename: "go-ci.yaml", Language: "Go", Type: "go-ci", Raw: true},
+		},
+		{name: "not a workflow", filename: "notes.yaml", content: "# just comments\n", wantErr: ErrNotTemplate},
+		{name: "unknown variable", filename: "x.yaml", content: "---\nvariables: [Nope]\n---\n", wantErr: errAny},
+		{name: "bad delims", filename: "x.yaml", content: "---\ndelims: [\"[[\"]\n---\n", wantErr: errAny},
+		{name: "unterminated", filename: "x.yaml", content: "---\ntype: x\n", wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTemplate(tt.filename, []byte(tt.content))
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr != errAny && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseTemplate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got.Template = ""
+			if got.Name != tt.want.Name || got.Filename != tt.want.Filename || got.Language != tt.want.Language ||
+				got.Type != tt.want.Type || got.Description != tt.want.Description || !slices.Equal(got.Variables, tt.want.Variables) ||
+				got.LeftDelim != tt.want.LeftDelim || got.RightDelim != tt.want.RightDelim || got.Raw != tt.want.Raw {
+				t.Errorf("ParseTemplate() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// errAny matches any error in table tests.
+var errAny = errors.New("any error")
+
+func TestLoadTemplatesDirRecipes(t *testing.T) {
+	templates, err := LoadTemplatesDir(filepath.Join("..", "..", "recipes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, wt := range templates {
+		types = append(types, wt.Type)
+		if !wt.Raw {
+			t.Errorf("recipe %s should be raw", wt.Type)
+		}
+	}
+	slices.Sort(types)
+	if want := []string{"go-ci", "go-lint", "ts-ci", "ts-lint"}; !slices.Equal(types, want) {
+		t.Errorf("types = %v, want %v", types, want)
+	}
+}
+
+func TestLoadRefRepoTemplates(t *testing.T) {
+	_, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/myorg/.github/contents/workflow-templates": `[
+			{"type":"file","name":"go-ci.yaml","path":"workflow-templates/go-ci.yaml"},
+			{"type":"file","name":"go-ci.properties.json","path":"workflow-templates/go-ci.properties.json"}
+		]`,
+		"GET /repos/myorg/.github/contents/workflow-templates/go-ci.yaml": `{"type":"file","encoding":"base64",` +
+			`"content":"bmFtZTogR28gQ0kKb246CiAgcHVzaDoKICAgIGJyYW5jaGVzOiBbJGRlZmF1bHQtYnJhbmNoXQpqb2JzOgogIGNpOgogICAgcnVucy1vbjogdWJ1bnR1LWxhdGVzdAo="}`,
+	})
+
+	templates, err := LoadRefRepoTemplates(context.Background(), client, "myorg/.github", "main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].Type != "go-ci" || !templates[0].Raw {
+		t.Fatalf("templates = %+v", templates)
+	}
+
+	gen := NewGenerator(GeneratorConfig{RefRepo: "myorg/.github"})
+	content, err := gen.renderTemplate(templates[0], TemplateData{DefaultBranch: "trunk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "branches: [trunk]") {
+		t.Errorf("$default-branch not replaced:\n%s", content)
+	}
+
+	if _, err := LoadRefRepoTemplates(context.Background(), client, "invalid", "main", ""); err == nil {
+		t.Error("LoadRefRepoTemplates(invalid) expected error")
+	}
+}
+
+func TestGeneratorUserTemplates(t *testing.T) {
+	repoDir := t.TempDir()
+	writeFile(t, filepath.Join(repoDir, "go.mod"), "module example.com/m\n\ngo 1.24.2\n")
+	writeFile(t, filepath.Join(repoDir, "web", "package.json"), "{}\n")
+
+	wt, err := ParseTemplate("release.yaml", []byte(goReleaseTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := NewGenerator(GeneratorConfig{RefRepo: "myorg/.github", DryRun: true})
+	gen.Register(wt)
+
+	repo := model.Repo{Owner: "myorg", Name: "app", FullName: "myorg/app", LocalPath: repoDir}
+	files, err := gen.GenerateForRepo(repo, []model.MissingWorkflow{{WorkflowType: "go-release"}, {WorkflowType: "ts-ci"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("generated %d files, want 2", len(files))
+	}
+
+	release := files[0].Content
+	for _, want := range []string{
+		"uses: myorg/.github/.github/workflows/go-release.yaml@main",
+		`go-version: "1.24.2"`,
+		"tag: ${{ github.ref_name }}",
+	} {
+		if !strings.Contains(release, want) {
+			t.Errorf("release workflow missing %q:\n%s", want, release)
+		}
+	}
+	if files[0].RelativePath != filepath.Join(".github", "workflows", "release.yaml") {
+		t.Errorf("RelativePath = %q", files[0].RelativePath)
+	}
+	if !strings.Contains(files[1].Content, `working-directory: "web"`) {
+		t.Errorf("ts-ci missing working-directory:\n%s", files[1].Content)
+	}
+
+	// Without a go.mod, the required GoVersion is missing and the template is skipped.
+	repo.LocalPath = t.TempDir()
+	files, err = gen.GenerateForRepo(repo, []model.MissingWorkflow{{WorkflowType: "go-release"}})
+	if err != nil || len(files) != 0 {
+		t.Errorf("GenerateForRepo() = %d files, %v; want template skipped", len(files), err)
+	}
+}
+
+func TestProjectDir(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "services", "api", "go.mod"), "module api\n")
+	writeFile(t, filepath.Join(root, "node_modules", "x", "package.json"), "{}\n")
+	writeFile(t, filepath.Join(root, "frontend", "package.json"), "{}\n")
+
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"Go", "services/api"},
+		{"TypeScript", "frontend"},
+		{"Crystal", ""},
+	}
+	for _, tt := range tests {
+		if got := projectDir(root, tt.language); got != tt.want {
+			t.Errorf("projectDir(%s) = %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
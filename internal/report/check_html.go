@@ -450,7 +450,7 @@ const htmlTemplate = `<!DOCTYPE html>
                         <td>
                             {{range .RequiredWorkflows}}
                             {{if .Present}}
-                            <span class="badge {{if eq .MatchType "exact"}}badge-green{{else}}badge-yellow{{end}}">{{.WorkflowType}}</span>
+                            <span class="badge {{if .Inactive}}badge-gray{{else if eq .MatchType "exact"}}badge-green{{else}}badge-yellow{{end}}">{{.WorkflowType}}</span>
                             {{else}}
                             <span class="badge badge-red">{{.WorkflowType}}</span>
                             {{end}}
@@ -461,6 +461,9 @@ const htmlTemplate = `<!DOCTYPE html>
                             <span class="badge badge-red">Missing: {{.WorkflowType}}</span>
                             {{end}}
                             {{range .RequiredWorkflows}}
+                            {{if .Inactive}}
+                            <span class="badge badge-gray" title="{{.InactiveReason}}">Inactive: {{.WorkflowType}}</span>
+                            {{end}}
                             {{if .FilenameMismatch}}
                             <span class="badge badge-yellow">Filename: {{.WorkflowType}}</span>
                             {{end}}
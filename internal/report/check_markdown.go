@@ -31,6 +31,9 @@ func (f *CheckMarkdownFormatter) Format(result *model.CheckResult) ([]byte, erro
 	sb.WriteString(fmt.Sprintf("| Non-Compliant | %d |\n", result.Summary.NonCompliant))
 	sb.WriteString(fmt.Sprintf("| Skipped | %d |\n", result.Summary.Skipped))
 	sb.WriteString(fmt.Sprintf("| Errors | %d |\n", result.Summary.Errors))
+	if result.Summary.InactiveWorkflows > 0 {
+		sb.WriteString(fmt.Sprintf("| Inactive Workflows | %d |\n", result.Summary.InactiveWorkflows))
+	}
 	sb.WriteString("\n")
 
 	// By Language
@@ -98,7 +101,9 @@ func (f *CheckMarkdownFormatter) writeRepoDetail(sb *strings.Builder, repo model
 		sb.WriteString("|----------|---------|----------|-------|\n")
 		for _, wf := range repo.RequiredWorkflows {
 			present := "❌"
-			if wf.Present {
+			if wf.Inactive {
+				present = "⏸️"
+			} else if wf.Present {
 				present = "✅"
 			}
 			reusable := "No"
@@ -110,6 +115,25 @@ func (f *CheckMarkdownFormatter) writeRepoDetail(sb *strings.Builder, repo model
 		}
 		sb.WriteString("\n")
 
+		// Show inactive workflows
+		var inactive []model.WorkflowCheck
+		for _, wf := range repo.RequiredWorkflows {
+			if wf.Inactive {
+				inactive = append(inactive, wf)
+			}
+		}
+		if len(inactive) > 0 {
+			sb.WriteString("**⏸️ Present but Inactive:**\n\n")
+			for _, wf := range inactive {
+				sb.WriteString(fmt.Sprintf("- `%s`: `%s` %s", wf.WorkflowType, wf.ActualWorkflow, wf.InactiveReason))
+				if wf.LastRunAt != "" {
+					sb.WriteString(fmt.Sprintf(" (last run %s)", wf.LastRunAt))
+				}
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n")
+		}
+
 		// Show filename mismatch warnings
 		var mismatches []model.WorkflowCheck
 		for _, wf := range repo.RequiredWorkflows {
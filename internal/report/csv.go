@@ -26,6 +26,7 @@ func (f *CSVFormatter) Format(result *model.ComplianceResult) ([]byte, error) {
 		"skipped",
 		"skip_reason",
 		"scan_time_ms",
+		"profile",
 	}
 	if err := w.Write(header); err != nil {
 		return nil, err
@@ -52,6 +53,7 @@ func (f *CSVFormatter) Format(result *model.ComplianceResult) ([]byte, error) {
 			skipped,
 			repo.SkipReason,
 			itoa64(repo.ScanTimeMs),
+			repo.Profile,
 		}
 		if err := w.Write(row); err != nil {
 			return nil, err
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// WriteExplanationText writes a human-readable policy explanation for CLI output.
+func WriteExplanationText(w io.Writer, exp *model.PolicyExplanation) error {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Repository: %s\n", exp.Repo))
+	sb.WriteString(fmt.Sprintf("Action:     %s\n", exp.Action))
+	sb.WriteString(fmt.Sprintf("Principal:  %s\n", exp.Principal))
+	sb.WriteString(fmt.Sprintf("Decision:   %s\n", strings.ToUpper(exp.Decision)))
+	if ids := exp.DeterminingPolicies(); len(ids) > 0 {
+		sb.WriteString(fmt.Sprintf("Determined by: %s\n", strings.Join(ids, ", ")))
+	} else if exp.Decision == model.DecisionDeny {
+		sb.WriteString("Determined by: default deny (no permit policy satisfied)\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("Context:\n")
+	keys := make([]string, 0, len(exp.Context))
+	for k := range exp.Context {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		value, err := json.Marshal(exp.Context[k])
+		if err != nil {
+			return err
+		}
+		sb.WriteString(fmt.Sprintf("  %s = %s\n", k, value))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("Policies:\n")
+	for _, p := range exp.Policies {
+		marker := " "
+		if p.Determining {
+			marker = "*"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s [%s] %s\n", marker, p.ID, p.Effect, traceStatus(p)))
+		scope := "no match"
+		if p.ScopeMatched {
+			scope = "match"
+		}
+		sb.WriteString(fmt.Sprintf("    scope (%s): %s\n", scope, p.Scope))
+		for _, c := range p.Conditions {
+			sb.WriteString(fmt.Sprintf("    %s { %s } => %s\n", c.Kind, c.Expr, conditionStatus(c)))
+		}
+		if p.Error != "" {
+			sb.WriteString(fmt.Sprintf("    error: %s\n", p.Error))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeExplanationsMarkdown writes policy traces for denied actions.
+func writeExplanationsMarkdown(sb *strings.Builder, exps []model.PolicyExplanation) {
+	var denied []model.PolicyExplanation
+	for _, exp := range exps {
+		if exp.Decision == model.DecisionDeny {
+			denied = append(denied, exp)
+		}
+	}
+	if len(denied) == 0 {
+		return
+	}
+
+	sb.WriteString("**Policy Trace:**\n\n")
+	for _, exp := range denied {
+		determinedBy := "default deny"
+		if ids := exp.DeterminingPolicies(); len(ids) > 0 {
+			determinedBy = strings.Join(ids, ", ")
+		}
+		sb.WriteString(fmt.Sprintf("- `%s` denied (determined by: %s)\n", exp.Action, determinedBy))
+		for _, p := range exp.Policies {
+			if !p.ScopeMatched {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("  - `%s` (%s): %s\n", p.ID, p.Effect, traceStatus(p)))
+			for _, c := range p.Conditions {
+				sb.WriteString(fmt.Sprintf("    - %s `%s` → %s\n", c.Kind, c.Expr, conditionStatus(c)))
+			}
+		}
+	}
+	sb.WriteString("\n")
+}
+
+func traceStatus(p model.PolicyTrace) string {
+	switch {
+	case p.Error != "":
+		return "error"
+	case !p.ScopeMatched:
+		return "not applicable"
+	case p.Satisfied:
+		return "satisfied"
+	default:
+		return "not satisfied"
+	}
+}
+
+func conditionStatus(c model.ConditionTrace) string {
+	if c.Error != "" {
+		return "error: " + c.Error
+	}
+	if c.Value == nil {
+		return "unknown"
+	}
+	result := "fail"
+	if c.Passed {
+		result = "pass"
+	}
+	return fmt.Sprintf("%t (%s)", *c.Value, result)
+}
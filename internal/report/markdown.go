@@ -36,6 +36,8 @@ func (f *MarkdownFormatter) Format(result *model.ComplianceResult) ([]byte, erro
 	sb.WriteString(fmt.Sprintf("| Compliance Rate | %.1f%% |\n", result.Summary.ComplianceRate))
 	sb.WriteString("\n")
 
+	writeCIHealthMarkdown(&sb, result.Repos)
+
 	// Organizations
 	if len(result.Config.Orgs) > 0 {
 		sb.WriteString("## Organizations\n\n")
@@ -72,6 +74,14 @@ func (f *MarkdownFormatter) Format(result *model.ComplianceResult) ([]byte, erro
 			continue
 		}
 
+		if repo.Profile != "" {
+			sb.WriteString(fmt.Sprintf("**Profile:** %s", repo.Profile))
+			if repo.ProfileSource != "" {
+				sb.WriteString(fmt.Sprintf(" (%s)", repo.ProfileSource))
+			}
+			sb.WriteString("\n\n")
+		}
+
 		// Violations
 		if len(repo.Violations) > 0 {
 			sb.WriteString("**Violations:**\n\n")
@@ -85,6 +95,25 @@ func (f *MarkdownFormatter) Format(result *model.ComplianceResult) ([]byte, erro
 			sb.WriteString("\n")
 		}
 
+		// Vulnerabilities
+		if len(repo.Vulnerabilities) > 0 {
+			sb.WriteString("**Vulnerabilities:**\n\n")
+			sb.WriteString("| Advisory | Severity | Package | Version | Fixed In |\n")
+			sb.WriteString("|----------|----------|---------|---------|----------|\n")
+			for _, v := range repo.Vulnerabilities {
+				fixed := v.FixedVersion
+				if fixed == "" {
+					fixed = "-"
+				}
+				sb.WriteString(fmt.Sprintf("| %s %s | %s | %s | %s | %s |\n",
+					severityToIcon(v.Severity), v.ID, v.Severity, v.Package, v.Version, fixed))
+			}
+			sb.WriteString("\n")
+		}
+
+		// Policy traces
+		writeExplanationsMarkdown(&sb, repo.Explanations)
+
 		// Warnings
 		if len(repo.Warnings) > 0 {
 			sb.WriteString("**Warnings:**\n\n")
@@ -105,6 +134,39 @@ func (f *MarkdownFormatter) Format(result *model.ComplianceResult) ([]byte, erro
 	return []byte(sb.String()), nil
 }
 
+// writeCIHealthMarkdown writes a table of workflow run health for repos
+// with collected run history.
+func writeCIHealthMarkdown(sb *strings.Builder, repos []model.RepoResult) {
+	var rows []model.RepoResult
+	for _, repo := range repos {
+		if repo.CIHealth != nil && repo.CIHealth.Runs > 0 {
+			rows = append(rows, repo)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	sb.WriteString("## CI Health\n\n")
+	sb.WriteString("| Repository | Branch | Runs | Pass Rate | Last Run | Last Success | Median Duration | Flaky Runs |\n")
+	sb.WriteString("|------------|--------|------|-----------|----------|--------------|-----------------|------------|\n")
+	for _, repo := range rows {
+		h := repo.CIHealth
+		lastRun := "❌"
+		if h.LastRunPassed {
+			lastRun = "✅"
+		}
+		lastSuccess := "never"
+		if !h.LastSuccessAt.IsZero() {
+			lastSuccess = h.LastSuccessAt.Format("2006-01-02")
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %d | %.0f%% | %s | %s | %s | %d |\n",
+			repo.Repo.FullName, h.Branch, h.Runs, h.PassRate, lastRun, lastSuccess,
+			(time.Duration(h.MedianDurationMs) * time.Millisecond).Round(time.Second), h.FlakyRuns))
+	}
+	sb.WriteString("\n")
+}
+
 func severityToIcon(s model.Severity) string {
 	switch s {
 	case model.SeverityCritical:
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// WriteRemediationStatusText writes the status of tracked remediation pull
+// requests for `remediate status` output.
+func WriteRemediationStatusText(w io.Writer, status *model.RemediationStatus) error {
+	var sb strings.Builder
+
+	for _, pr := range status.PRs {
+		sb.WriteString(fmt.Sprintf("%s#%d  %s\n", pr.Repo.FullName, pr.Number, remediationState(pr)))
+		sb.WriteString(fmt.Sprintf("  URL:       %s\n", pr.URL))
+		sb.WriteString(fmt.Sprintf("  Branch:    %s\n", pr.Branch))
+		if len(pr.WorkflowTypes) > 0 {
+			sb.WriteString(fmt.Sprintf("  Workflows: %s\n", strings.Join(pr.WorkflowTypes, ", ")))
+		}
+		if pr.Campaign != "" {
+			sb.WriteString(fmt.Sprintf("  Campaign:  %s\n", pr.Campaign))
+		}
+		sb.WriteString(fmt.Sprintf("  Opened:    %s\n", pr.OpenedAt.Format("2006-01-02")))
+	}
+	if len(status.PRs) > 0 {
+		sb.WriteString("\n")
+	}
+
+	sum := status.Summary()
+	sb.WriteString(fmt.Sprintf("Total: %d  Open: %d  Merged: %d  Closed: %d  Checks failing: %d  Conflicts: %d\n",
+		sum.Total, sum.Open, sum.Merged, sum.Closed, sum.ChecksFailing, sum.Conflicts))
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// remediationState describes a tracked pull request's state, including
+// failing checks and conflicts for open pull requests.
+func remediationState(pr model.TrackedPR) string {
+	state := pr.State
+	switch {
+	case pr.State == model.PRStateClosed && pr.ClosedReason != "":
+		state += " (" + pr.ClosedReason + ")"
+	case pr.State == model.PRStateOpen:
+		var issues []string
+		if pr.ChecksFailing {
+			issues = append(issues, "checks failing")
+		}
+		if pr.Conflicts {
+			issues = append(issues, "conflicts")
+		}
+		if len(issues) > 0 {
+			state += " (" + strings.Join(issues, ", ") + ")"
+		}
+	}
+	return state
+}
@@ -164,8 +164,8 @@ func TestBuilderGenerateCSV(t *testing.T) {
 
 	csv := string(output)
 	// Check header
-	if !strings.Contains(csv, "repo,org,compliant") {
-		t.Error("CSV output missing header")
+	if !strings.HasPrefix(csv, "repo,org,compliant,violation_count,") || !strings.Contains(csv, ",scan_time_ms,profile\n") {
+		t.Errorf("CSV header columns out of order:\n%s", csv)
 	}
 	// Check data
 	if !strings.Contains(csv, "testorg/repo1") {
@@ -200,3 +200,219 @@ func TestBuilderGenerateUnsupportedFormat(t *testing.T) {
 		t.Error("Generate() with unsupported format should return error")
 	}
 }
+
+func sampleExplanation() model.PolicyExplanation {
+	value := false
+	return model.PolicyExplanation{
+		Repo:      "testorg/repo2",
+		Action:    "merge",
+		Principal: `CISystem::"pipelineconductor"`,
+		Decision:  model.DecisionDeny,
+		Context:   map[string]any{"lastRunPassed": false},
+		Policies: []model.PolicyTrace{
+			{
+				ID:           "builtin/require-tests",
+				Effect:       "permit",
+				Scope:        `principal, action == Action::"merge", resource`,
+				ScopeMatched: true,
+				Conditions: []model.ConditionTrace{
+					{Kind: model.ConditionKindWhen, Expr: "context.lastRunPassed == true", Value: &value},
+				},
+			},
+		},
+	}
+}
+
+func TestWriteExplanationText(t *testing.T) {
+	exp := sampleExplanation()
+
+	var buf bytes.Buffer
+	if err := WriteExplanationText(&buf, &exp); err != nil {
+		t.Fatalf("WriteExplanationText() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Decision:   DENY",
+		"default deny",
+		"lastRunPassed = false",
+		"builtin/require-tests [permit] not satisfied",
+		"when { context.lastRunPassed == true } => false (fail)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteExplanationText() output missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestBuilderGenerateMarkdownWithExplanations(t *testing.T) {
+	result := sampleResult()
+	result.Repos[1].Explanations = []model.PolicyExplanation{sampleExplanation()}
+
+	output, err := NewBuilder().Generate(result, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	md := string(output)
+	if !strings.Contains(md, "**Policy Trace:**") {
+		t.Error("Markdown output missing policy trace section")
+	}
+	if !strings.Contains(md, "`context.lastRunPassed == true` → false (fail)") {
+		t.Errorf("Markdown output missing condition trace\n%s", md)
+	}
+}
+
+func TestBuilderGenerateMarkdownWithProfile(t *testing.T) {
+	result := sampleResult()
+	result.Repos[0].Profile = "legacy"
+	result.Repos[0].ProfileSource = "topic"
+
+	output, err := NewBuilder().Generate(result, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(output), "**Profile:** legacy (topic)") {
+		t.Errorf("Markdown output missing profile line\n%s", output)
+	}
+}
+
+func TestBuilderGenerateVulnerabilities(t *testing.T) {
+	result := sampleResult()
+	result.Repos[0].Vulnerabilities = []model.Vulnerability{
+		{
+			ID:           "GHSA-1234-abcd-5678",
+			Summary:      "Prototype pollution",
+			Severity:     model.SeverityCritical,
+			Package:      "lodash",
+			Version:      "4.17.15",
+			Ecosystem:    model.EcosystemNPM,
+			File:         "package-lock.json",
+			FixedVersion: "4.17.21",
+		},
+	}
+	builder := NewBuilder()
+
+	md, err := builder.Generate(result, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(md), "| 🔴 GHSA-1234-abcd-5678 | critical | lodash | 4.17.15 | 4.17.21 |") {
+		t.Errorf("Markdown output missing vulnerability row\n%s", md)
+	}
+
+	sarif, err := builder.Generate(result, FormatSARIF)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, want := range []string{
+		`"ruleId": "GHSA-1234-abcd-5678"`,
+		`"helpUri": "https://osv.dev/vulnerability/GHSA-1234-abcd-5678"`,
+		`"uri": "package-lock.json"`,
+		"Upgrade lodash to 4.17.21",
+	} {
+		if !strings.Contains(string(sarif), want) {
+			t.Errorf("SARIF output missing %s", want)
+		}
+	}
+}
+
+func TestBuilderGenerateMarkdownCIHealth(t *testing.T) {
+	result := sampleResult()
+	result.Repos[1].CIHealth = &model.CIHealth{
+		Branch: "main",
+		RunStats: model.RunStats{
+			Runs:             20,
+			Passed:           15,
+			Failed:           5,
+			PassRate:         75,
+			LastSuccessAt:    time.Date(2025, 1, 14, 8, 0, 0, 0, time.UTC),
+			MedianDurationMs: 185_000,
+			FlakyRuns:        3,
+		},
+	}
+
+	output, err := NewBuilder().Generate(result, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	md := string(output)
+	if !strings.Contains(md, "## CI Health") {
+		t.Error("Markdown output missing CI health section")
+	}
+	if !strings.Contains(md, "| testorg/repo2 | main | 20 | 75% | ❌ | 2025-01-14 | 3m5s | 3 |") {
+		t.Errorf("Markdown output missing CI health row\n%s", md)
+	}
+}
+
+func TestCheckMarkdownInactiveWorkflows(t *testing.T) {
+	result := &model.CheckResult{
+		Summary: model.CheckSummary{TotalRepos: 1, PartialRepos: 1, InactiveWorkflows: 1},
+		Repos: []model.RepoCheckResult{{
+			FullName:        "testorg/svc",
+			Languages:       []string{"Go"},
+			ComplianceLevel: model.ComplianceLevelPartial,
+			RequiredWorkflows: []model.WorkflowCheck{{
+				WorkflowType:   "go-ci",
+				Present:        true,
+				UsesReusable:   true,
+				MatchType:      model.MatchTypeInactive,
+				ActualWorkflow: ".github/workflows/go-ci.yaml",
+				Inactive:       true,
+				InactiveReason: "disabled by GitHub after repository inactivity",
+			}},
+		}},
+	}
+
+	output, err := (&CheckMarkdownFormatter{}).Format(result)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	md := string(output)
+	for _, want := range []string{
+		"| Inactive Workflows | 1 |",
+		"| go-ci | ⏸️ | Yes | inactive |",
+		"- `go-ci`: `.github/workflows/go-ci.yaml` disabled by GitHub after repository inactivity",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown output missing %q\n%s", want, md)
+		}
+	}
+}
+
+func TestWriteRemediationStatusText(t *testing.T) {
+	status := &model.RemediationStatus{PRs: []model.TrackedPR{
+		{
+			Repo:          model.Repo{FullName: "org/repo1"},
+			Number:        7,
+			URL:           "https://github.com/org/repo1/pull/7",
+			Branch:        "pipelineconductor/remediation",
+			WorkflowTypes: []string{"go-ci", "go-lint"},
+			Campaign:      "q3",
+			State:         model.PRStateOpen,
+			ChecksFailing: true,
+			Conflicts:     true,
+		},
+		{Repo: model.Repo{FullName: "org/repo2"}, Number: 3, State: model.PRStateClosed, ClosedReason: "stale"},
+		{Repo: model.Repo{FullName: "org/repo3"}, Number: 9, State: model.PRStateMerged},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteRemediationStatusText(&buf, status); err != nil {
+		t.Fatalf("WriteRemediationStatusText() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"org/repo1#7  open (checks failing, conflicts)",
+		"Workflows: go-ci, go-lint",
+		"Campaign:  q3",
+		"org/repo2#3  closed (stale)",
+		"org/repo3#9  merged",
+		"Total: 3  Open: 1  Merged: 1  Closed: 1  Checks failing: 1  Conflicts: 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteRemediationStatusText() output missing %q\n%s", want, out)
+		}
+	}
+}
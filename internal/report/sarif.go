@@ -194,6 +194,10 @@ func (f *SARIFFormatter) Format(result *model.ComplianceResult) ([]byte, error)
 
 			results = append(results, sarifResult)
 		}
+
+		for _, vuln := range repo.Vulnerabilities {
+			results = append(results, vulnerabilityResult(repo, vuln, rulesMap, ruleIndex))
+		}
 	}
 
 	// Convert rules map to slice
@@ -220,6 +224,74 @@ func (f *SARIFFormatter) Format(result *model.ComplianceResult) ([]byte, error)
 	return json.MarshalIndent(sarif, "", "  ")
 }
 
+// vulnerabilityResult creates a result for a vulnerable dependency, using
+// the advisory ID as the rule ID.
+func vulnerabilityResult(repo model.RepoResult, vuln model.Vulnerability, rulesMap map[string]*SARIFRule, ruleIndex map[string]int) SARIFResult {
+	if _, ok := rulesMap[vuln.ID]; !ok {
+		description := vuln.Summary
+		if description == "" {
+			description = vuln.ID
+		}
+		rulesMap[vuln.ID] = &SARIFRule{
+			ID:   vuln.ID,
+			Name: "dependencies/vulnerability",
+			ShortDescription: SARIFMultiformatMessage{
+				Text: description,
+			},
+			DefaultConfig: &SARIFRuleConfig{
+				Level: severityToSARIFLevel(vuln.Severity),
+			},
+			HelpURI: "https://osv.dev/vulnerability/" + vuln.ID,
+		}
+		ruleIndex[vuln.ID] = len(rulesMap) - 1
+	}
+
+	message := fmt.Sprintf("[%s] %s@%s is affected by %s", repo.Repo.FullName, vuln.Package, vuln.Version, vuln.ID)
+	if vuln.Summary != "" {
+		message += ": " + vuln.Summary
+	}
+
+	result := SARIFResult{
+		RuleID:    vuln.ID,
+		RuleIndex: ruleIndex[vuln.ID],
+		Level:     severityToSARIFLevel(vuln.Severity),
+		Message: SARIFMessage{
+			Text: message,
+		},
+		Locations: []SARIFLocation{
+			{
+				LogicalLocations: []SARIFLogicalLocation{
+					{
+						Name:               repo.Repo.Name,
+						FullyQualifiedName: repo.Repo.FullName,
+						Kind:               "repository",
+					},
+				},
+			},
+		},
+	}
+
+	if vuln.File != "" {
+		result.Locations[0].PhysicalLocation = &SARIFPhysicalLocation{
+			ArtifactLocation: SARIFArtifactLocation{
+				URI: vuln.File,
+			},
+		}
+	}
+
+	if vuln.FixedVersion != "" {
+		result.Fixes = []SARIFFix{
+			{
+				Description: SARIFMessage{
+					Text: fmt.Sprintf("Upgrade %s to %s", vuln.Package, vuln.FixedVersion),
+				},
+			},
+		}
+	}
+
+	return result
+}
+
 func severityToSARIFLevel(s model.Severity) string {
 	switch s {
 	case model.SeverityCritical, model.SeverityHigh:
@@ -0,0 +1,254 @@
+// Package tracker tracks remediation pull requests after they are opened.
+package tracker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plexusone/pipelineconductor/internal/remediator"
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+// StatusSource reports the state of pull requests. remediator.PRApplier
+// satisfies it.
+type StatusSource interface {
+	PRStatus(ctx context.Context, repo model.Repo, number int) (*remediator.PRStatus, error)
+}
+
+// Closer closes pull requests. remediator.PRApplier satisfies it.
+type Closer interface {
+	ClosePR(ctx context.Context, repo model.Repo, number int, branch, comment string) error
+}
+
+// Applier applies remediation plans. remediator.PRApplier satisfies it.
+type Applier interface {
+	Apply(ctx context.Context, plan model.RemediationPlan) (*model.RemediationResult, error)
+}
+
+// Planner regenerates the remediation plan for a tracked pull request.
+type Planner func(ctx context.Context, pr model.TrackedPR) (model.RemediationPlan, error)
+
+// Tracker stores remediation pull requests and reconciles their state.
+type Tracker struct {
+	Status model.RemediationStatus
+	file   string
+	now    func() time.Time
+}
+
+// New creates an empty tracker saved to file.
+func New(file string) *Tracker {
+	return &Tracker{file: file, now: time.Now}
+}
+
+// Load reads a tracker from file. A missing file yields an empty tracker.
+func Load(file string) (*Tracker, error) {
+	t := New(file)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading remediation status: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.Status); err != nil {
+		return nil, fmt.Errorf("parsing remediation status %s: %w", file, err)
+	}
+	return t, nil
+}
+
+// Save writes the tracked pull requests to the tracker's file. The file
+// is replaced atomically so an interrupted save leaves it intact.
+func (t *Tracker) Save() error {
+	if t.file == "" {
+		return errors.New("tracker has no file")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.file), ".remediation-*.json")
+	if err != nil {
+		return fmt.Errorf("saving remediation status: %w", err)
+	}
+	werr := t.WriteJSON(tmp)
+	cerr := tmp.Close()
+	if err := errors.Join(werr, cerr); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("saving remediation status: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), t.file); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("saving remediation status: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON exports the tracked pull requests as JSON.
+func (t *Tracker) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t.Status)
+}
+
+// Record starts tracking the pull request opened for plan. Recording the
+// same pull request again updates it. Failed and dry-run results are
+// ignored.
+func (t *Tracker) Record(plan model.RemediationPlan, result *model.RemediationResult, campaign string) {
+	if result == nil || !result.Success || result.DryRun || result.PRNumber == 0 {
+		return
+	}
+	now := t.now()
+	pr := model.TrackedPR{
+		Repo:          plan.Repo,
+		Number:        result.PRNumber,
+		URL:           result.PRURL,
+		Branch:        result.Branch,
+		WorkflowTypes: WorkflowTypes(plan),
+		Campaign:      campaign,
+		PatchHash:     PatchHash(plan),
+		State:         model.PRStateOpen,
+		OpenedAt:      now,
+		UpdatedAt:     now,
+	}
+
+	if i := t.index(plan.Repo.FullName, result.PRNumber); i >= 0 {
+		pr.OpenedAt = t.Status.PRs[i].OpenedAt
+		if pr.Campaign == "" {
+			pr.Campaign = t.Status.PRs[i].Campaign
+		}
+		t.Status.PRs[i] = pr
+	} else {
+		t.Status.PRs = append(t.Status.PRs, pr)
+	}
+	t.Status.UpdatedAt = now
+}
+
+func (t *Tracker) index(fullName string, number int) int {
+	return slices.IndexFunc(t.Status.PRs, func(pr model.TrackedPR) bool {
+		return pr.Repo.FullName == fullName && pr.Number == number
+	})
+}
+
+// Reconcile refreshes the state, check status and conflicts of every open
+// pull request.
+func (t *Tracker) Reconcile(ctx context.Context, src StatusSource) error {
+	var errs []error
+	for i := range t.Status.PRs {
+		pr := &t.Status.PRs[i]
+		if pr.State != model.PRStateOpen {
+			continue
+		}
+		status, err := src.PRStatus(ctx, pr.Repo, pr.Number)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pr.State = status.State
+		pr.ChecksFailing = status.ChecksFailing
+		pr.Conflicts = status.Conflicts
+		pr.UpdatedAt = t.now()
+	}
+	t.Status.UpdatedAt = t.now()
+	return errors.Join(errs...)
+}
+
+// CloseStale closes open pull requests opened more than maxAge ago and
+// returns them.
+func (t *Tracker) CloseStale(ctx context.Context, closer Closer, maxAge time.Duration) ([]model.TrackedPR, error) {
+	cutoff := t.now().Add(-maxAge)
+	var closed []model.TrackedPR
+	for i := range t.Status.PRs {
+		pr := &t.Status.PRs[i]
+		if pr.State != model.PRStateOpen || !pr.OpenedAt.Before(cutoff) {
+			continue
+		}
+		comment := fmt.Sprintf("Closing this remediation pull request: it has been open for more than %d days. "+
+			"PipelineConductor will open a new one if the repository is still non-compliant.", int(maxAge.Hours()/24))
+		if err := closer.ClosePR(ctx, pr.Repo, pr.Number, pr.Branch, comment); err != nil {
+			return closed, err
+		}
+		pr.State = model.PRStateClosed
+		pr.ClosedReason = "stale"
+		pr.UpdatedAt = t.now()
+		closed = append(closed, *pr)
+	}
+	return closed, nil
+}
+
+// Rebase regenerates the plan of every open pull request and re-applies
+// it on the pull request's branch when the patches changed, because the
+// reference templates were updated, or when the pull request conflicts
+// with its base. Applying resets the branch onto the current default
+// branch, so the existing pull request is updated in place. If the
+// applier opens a different pull request instead, that one is recorded
+// and the tracked one is left as it was. It returns the updated pull
+// requests.
+func (t *Tracker) Rebase(ctx context.Context, planner Planner, applier Applier) ([]model.TrackedPR, error) {
+	var rebased []model.TrackedPR
+	for i, n := 0, len(t.Status.PRs); i < n; i++ {
+		pr := &t.Status.PRs[i]
+		if pr.State != model.PRStateOpen {
+			continue
+		}
+		plan, err := planner(ctx, *pr)
+		if err != nil {
+			return rebased, fmt.Errorf("planning %s#%d: %w", pr.Repo.FullName, pr.Number, err)
+		}
+		hash := PatchHash(plan)
+		if hash == pr.PatchHash && !pr.Conflicts {
+			continue
+		}
+		plan.Branch = pr.Branch
+		result, err := applier.Apply(ctx, plan)
+		if err != nil {
+			return rebased, err
+		}
+		if result.PRNumber != pr.Number {
+			// Record may grow t.Status.PRs, so pr is not used after it.
+			t.Record(plan, result, pr.Campaign)
+			if j := t.index(plan.Repo.FullName, result.PRNumber); j >= 0 {
+				rebased = append(rebased, t.Status.PRs[j])
+			}
+			continue
+		}
+		pr.PatchHash = hash
+		pr.WorkflowTypes = WorkflowTypes(plan)
+		pr.Conflicts = false
+		pr.UpdatedAt = t.now()
+		rebased = append(rebased, *pr)
+	}
+	return rebased, nil
+}
+
+// PatchHash returns a hash of a plan's patches.
+func PatchHash(plan model.RemediationPlan) string {
+	patches := slices.Clone(plan.Patches)
+	slices.SortFunc(patches, func(a, b model.Patch) int { return strings.Compare(a.Path, b.Path) })
+	h := sha256.New()
+	for _, p := range patches {
+		fmt.Fprintf(h, "%s %s %d\n%s", p.Operation, p.Path, len(p.Content), p.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// WorkflowTypes returns the workflow types a plan fixes, taken from the
+// names of the workflow files it changes.
+func WorkflowTypes(plan model.RemediationPlan) []string {
+	var types []string
+	for _, p := range plan.Patches {
+		if path.Dir(p.Path) != ".github/workflows" {
+			continue
+		}
+		name := path.Base(p.Path)
+		types = append(types, strings.TrimSuffix(name, path.Ext(name)))
+	}
+	return types
+}
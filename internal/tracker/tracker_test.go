@@ -0,0 +1,204 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/plexusone/pipelineconductor/internal/remediator"
+	"github.com/plexusone/pipelineconductor/pkg/model"
+)
+
+var now = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testTracker(t *testing.T) *Tracker {
+	t.Helper()
+	tr := New(filepath.Join(t.TempDir(), "remediation.json"))
+	tr.now = func() time.Time { return now }
+	return tr
+}
+
+func testPlan(name, content string) model.RemediationPlan {
+	return model.RemediationPlan{
+		Repo: model.Repo{Owner: "org", Name: name, FullName: "org/" + name},
+		Patches: []model.Patch{
+			{Path: ".github/workflows/go-lint.yaml", Operation: "create", Content: content},
+			{Path: ".github/workflows/go-ci.yaml", Operation: "create", Content: "ci"},
+			{Path: "CODEOWNERS", Operation: "create", Content: "* @org/team"},
+		},
+	}
+}
+
+func opened(number int) *model.RemediationResult {
+	return &model.RemediationResult{Success: true, PRNumber: number, PRURL: "https://example.com", Branch: "fix"}
+}
+
+func TestRecord(t *testing.T) {
+	tr := testTracker(t)
+	plan := testPlan("repo1", "lint")
+
+	tr.Record(plan, &model.RemediationResult{Success: true, DryRun: true}, "")
+	tr.Record(plan, &model.RemediationResult{Error: "boom"}, "")
+	if len(tr.Status.PRs) != 0 {
+		t.Fatalf("recorded dry-run or failed results: %+v", tr.Status.PRs)
+	}
+
+	tr.Record(plan, opened(4), "q3")
+	now = now.Add(time.Hour)
+	tr.Record(plan, opened(4), "")
+	if len(tr.Status.PRs) != 1 {
+		t.Fatalf("PRs = %+v, want one upserted PR", tr.Status.PRs)
+	}
+	pr := tr.Status.PRs[0]
+	if pr.Campaign != "q3" || pr.State != model.PRStateOpen || pr.Branch != "fix" || pr.OpenedAt.Equal(pr.UpdatedAt) {
+		t.Errorf("PR = %+v", pr)
+	}
+	if want := []string{"go-lint", "go-ci"}; !slices.Equal(pr.WorkflowTypes, want) {
+		t.Errorf("WorkflowTypes = %v, want %v", pr.WorkflowTypes, want)
+	}
+
+	if err := tr.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(tr.file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Status.PRs) != 1 || loaded.Status.PRs[0].PatchHash != pr.PatchHash {
+		t.Errorf("loaded = %+v", loaded.Status)
+	}
+
+	var buf bytes.Buffer
+	if err := tr.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var exported model.RemediationStatus
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil || exported.PRs[0].Number != 4 {
+		t.Errorf("WriteJSON() = %s, %v", buf.String(), err)
+	}
+
+	if empty, err := Load(filepath.Join(t.TempDir(), "missing.json")); err != nil || len(empty.Status.PRs) != 0 {
+		t.Errorf("Load(missing) = %+v, %v", empty, err)
+	}
+}
+
+type fakeGitHub struct {
+	status   map[int]remediator.PRStatus
+	closed   []int
+	applied  []string
+	branches []string
+	// numbers holds the pull request number Apply reports per repository.
+	numbers map[string]int
+}
+
+func (f *fakeGitHub) PRStatus(_ context.Context, _ model.Repo, number int) (*remediator.PRStatus, error) {
+	s := f.status[number]
+	return &s, nil
+}
+
+func (f *fakeGitHub) ClosePR(_ context.Context, _ model.Repo, number int, _, _ string) error {
+	f.closed = append(f.closed, number)
+	return nil
+}
+
+func (f *fakeGitHub) Apply(_ context.Context, plan model.RemediationPlan) (*model.RemediationResult, error) {
+	f.applied = append(f.applied, plan.Repo.FullName)
+	f.branches = append(f.branches, plan.Branch)
+	return &model.RemediationResult{Success: true, PRNumber: f.numbers[plan.Repo.FullName], Branch: plan.Branch}, nil
+}
+
+func TestReconcileAndCloseStale(t *testing.T) {
+	tr := testTracker(t)
+	tr.Record(testPlan("repo1", "lint"), opened(1), "")
+	tr.Record(testPlan("repo2", "lint"), opened(2), "")
+	tr.Record(testPlan("repo3", "lint"), opened(3), "")
+	tr.Status.PRs[2].OpenedAt = now.Add(-40 * 24 * time.Hour)
+
+	gh := &fakeGitHub{status: map[int]remediator.PRStatus{
+		1: {State: model.PRStateMerged},
+		2: {State: model.PRStateOpen, ChecksFailing: true, Conflicts: true},
+		3: {State: model.PRStateOpen},
+	}}
+	ctx := context.Background()
+	if err := tr.Reconcile(ctx, gh); err != nil {
+		t.Fatal(err)
+	}
+	want := model.RemediationStatusSummary{Total: 3, Open: 2, Merged: 1, ChecksFailing: 1, Conflicts: 1}
+	if got := tr.Status.Summary(); got != want {
+		t.Errorf("Summary() = %+v, want %+v", got, want)
+	}
+
+	closed, err := tr.CloseStale(ctx, gh, 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closed) != 1 || closed[0].Number != 3 || !slices.Equal(gh.closed, []int{3}) {
+		t.Fatalf("closed = %+v, github closed %v", closed, gh.closed)
+	}
+	if pr := tr.Status.PRs[2]; pr.State != model.PRStateClosed || pr.ClosedReason != "stale" {
+		t.Errorf("stale PR = %+v", pr)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	tr := testTracker(t)
+	tr.Record(testPlan("repo1", "lint"), opened(1), "")
+	tr.Record(testPlan("repo2", "lint"), opened(2), "")
+	tr.Record(testPlan("repo3", "lint"), opened(3), "")
+	tr.Status.PRs[1].Conflicts = true
+	tr.Status.PRs[2].State = model.PRStateMerged
+
+	// The reference template for go-lint changed for repo1 only.
+	planner := func(_ context.Context, pr model.TrackedPR) (model.RemediationPlan, error) {
+		if pr.Number == 1 {
+			return testPlan(pr.Repo.Name, "lint v2"), nil
+		}
+		return testPlan(pr.Repo.Name, "lint"), nil
+	}
+	gh := &fakeGitHub{numbers: map[string]int{"org/repo1": 1, "org/repo2": 2}}
+	rebased, err := tr.Rebase(context.Background(), planner, gh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"org/repo1", "org/repo2"}; !slices.Equal(gh.applied, want) || len(rebased) != 2 {
+		t.Fatalf("applied = %v, want %v", gh.applied, want)
+	}
+	if want := []string{"fix", "fix"}; !slices.Equal(gh.branches, want) {
+		t.Errorf("applied on branches %v, want %v", gh.branches, want)
+	}
+	if tr.Status.PRs[0].PatchHash != PatchHash(testPlan("repo1", "lint v2")) || tr.Status.PRs[1].Conflicts {
+		t.Errorf("PRs = %+v", tr.Status.PRs)
+	}
+}
+
+func TestRebaseOpensNewPR(t *testing.T) {
+	tr := testTracker(t)
+	tr.Record(testPlan("repo1", "lint"), opened(1), "q3")
+	tr.Status.PRs[0].Conflicts = true
+
+	planner := func(_ context.Context, pr model.TrackedPR) (model.RemediationPlan, error) {
+		return testPlan(pr.Repo.Name, "lint v2"), nil
+	}
+	// The branch's pull request was closed, so applying opens #9.
+	gh := &fakeGitHub{numbers: map[string]int{"org/repo1": 9}}
+	rebased, err := tr.Rebase(context.Background(), planner, gh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rebased) != 1 || rebased[0].Number != 9 {
+		t.Fatalf("rebased = %+v, want #9", rebased)
+	}
+	if len(tr.Status.PRs) != 2 {
+		t.Fatalf("PRs = %+v, want the old and new pull request", tr.Status.PRs)
+	}
+	if old := tr.Status.PRs[0]; old.PatchHash != PatchHash(testPlan("repo1", "lint")) || !old.Conflicts {
+		t.Errorf("old PR = %+v, want it unchanged", old)
+	}
+	if pr := tr.Status.PRs[1]; pr.PatchHash != PatchHash(testPlan("repo1", "lint v2")) || pr.Campaign != "q3" || pr.Branch != "fix" {
+		t.Errorf("new PR = %+v", pr)
+	}
+}
@@ -20,6 +20,9 @@ type CheckSummary struct {
 	Errors         int                       `json:"errors"`
 	ComplianceRate float64                   `json:"complianceRate"`
 	ByLanguage     []LanguageComplianceStats `json:"byLanguage"`
+	// InactiveWorkflows counts required workflows that are present but
+	// inactive.
+	InactiveWorkflows int `json:"inactiveWorkflows"`
 }
 
 // LanguageComplianceStats provides per-language compliance breakdown.
@@ -36,6 +39,7 @@ type RepoCheckResult struct {
 	Name              string            `json:"name"`
 	FullName          string            `json:"fullName"`
 	HTMLURL           string            `json:"htmlUrl"`
+	DefaultBranch     string            `json:"defaultBranch,omitempty"`
 	Languages         []string          `json:"languages"`
 	Compliant         bool              `json:"compliant"`
 	ComplianceLevel   string            `json:"complianceLevel"`
@@ -61,6 +65,13 @@ type WorkflowCheck struct {
 	FilenameMismatch bool   `json:"filenameMismatch,omitempty"`
 	ExpectedFilename string `json:"expectedFilename,omitempty"`
 	ActualFilename   string `json:"actualFilename,omitempty"`
+	// Inactive is set when the only matching workflow is disabled or has
+	// not run within the configured window. MatchType is then
+	// MatchTypeInactive.
+	Inactive       bool   `json:"inactive,omitempty"`
+	InactiveReason string `json:"inactiveReason,omitempty"`
+	State          string `json:"state,omitempty"`
+	LastRunAt      string `json:"lastRunAt,omitempty"`
 }
 
 // WorkflowInfo provides information about an existing workflow in a repository.
@@ -89,6 +100,8 @@ type CheckConfig struct {
 	RefBranch string   `json:"refBranch"`
 	Languages []string `json:"languages"`
 	Strict    bool     `json:"strict"`
+	// InactiveAfterDays is the run window for inactivity; 0 disables it.
+	InactiveAfterDays int `json:"inactiveAfterDays,omitempty"`
 }
 
 // ComplianceLevel constants.
@@ -104,6 +117,9 @@ const (
 	MatchTypeEquivalent = "equivalent"
 	MatchTypePartial    = "partial"
 	MatchTypeNone       = "none"
+	// MatchTypeInactive marks a workflow that is present but disabled or
+	// not running.
+	MatchTypeInactive = "inactive"
 )
 
 // SeverityLevel constants for missing workflows.
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// Dependency ecosystems.
+const (
+	EcosystemGo  = "go"
+	EcosystemNPM = "npm"
+)
+
+// Dependency is a single declared dependency of a repository.
+type Dependency struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	// Direct is false for indirect Go requirements and transitive npm packages.
+	Direct bool `json:"direct"`
+	// File is the manifest or lockfile the version was read from.
+	File string `json:"file,omitempty"`
+
+	// Freshness, filled in by dependency analysis.
+	Latest      string    `json:"latest,omitempty"`
+	VersionTime time.Time `json:"versionTime,omitempty"`
+	LatestTime  time.Time `json:"latestTime,omitempty"`
+	// DaysBehind is the number of days between the release of the used
+	// version and the release of the latest version.
+	DaysBehind int    `json:"daysBehind"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Outdated reports whether a newer version than the one in use is
+// available. Versions are compared as semantic versions, so a
+// pseudo-version of a commit after the latest release, or a version newer
+// than the registry's latest, is not outdated. Versions that are not
+// semantic versions are outdated when they differ.
+func (d Dependency) Outdated() bool {
+	if d.Latest == "" || d.Latest == d.Version {
+		return false
+	}
+	current, latest := semverOf(d.Version), semverOf(d.Latest)
+	if semver.IsValid(current) && semver.IsValid(latest) {
+		return semver.Compare(current, latest) < 0
+	}
+	return true
+}
+
+// semverOf adds the "v" prefix that semver expects to npm versions.
+func semverOf(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// Vulnerability is a known advisory affecting a dependency of a repository.
+type Vulnerability struct {
+	// ID is the advisory identifier, such as GO-2024-2687 or GHSA-xxxx-xxxx-xxxx.
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Severity Severity `json:"severity"`
+
+	Package   string `json:"package"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	File      string `json:"file,omitempty"`
+	// FixedVersion is the lowest release that fixes the advisory, if any.
+	FixedVersion string `json:"fixedVersion,omitempty"`
+}
+
+// Dependency update tools.
+const (
+	UpdateToolRenovate   = "renovate"
+	UpdateToolDependabot = "dependabot"
+)
+
+// UpdateConfig is a parsed Renovate or Dependabot configuration.
+type UpdateConfig struct {
+	Tool string `json:"tool"`
+	File string `json:"file"`
+	// Ecosystems lists the covered ecosystems using Dependabot's
+	// package-ecosystem names (gomod, npm, github-actions, ...).
+	Ecosystems []string `json:"ecosystems,omitempty"`
+	// AllEcosystems is set for Renovate configs that do not restrict
+	// enabledManagers, since Renovate then updates every ecosystem it finds.
+	AllEcosystems bool     `json:"allEcosystems"`
+	Automerge     bool     `json:"automerge"`
+	Schedule      bool     `json:"schedule"`
+	Extends       []string `json:"extends,omitempty"`
+	// Error is set when the file exists but cannot be parsed.
+	Error string `json:"error,omitempty"`
+}
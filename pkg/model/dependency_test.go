@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestDependencyOutdated(t *testing.T) {
+	tests := []struct {
+		version string
+		latest  string
+		want    bool
+	}{
+		{"v1.2.0", "v1.4.0", true},
+		{"v1.4.0", "v1.4.0", false},
+		{"v1.4.0", "", false},
+		// A pseudo-version of a commit after the latest release.
+		{"v1.4.1-0.20260101000000-abcdef123456", "v1.4.0", false},
+		{"v0.0.0-20200101000000-abcdef123456", "v1.4.0", true},
+		// The registry's latest is older than the version in use.
+		{"v2.0.0-rc.1", "v1.9.0", false},
+		{"18.3.1", "18.2.0", false},
+		{"18.2.0", "18.3.1", true},
+		{"next", "18.3.1", true},
+	}
+	for _, tt := range tests {
+		d := Dependency{Version: tt.version, Latest: tt.latest}
+		if got := d.Outdated(); got != tt.want {
+			t.Errorf("Dependency{%s, latest %s}.Outdated() = %v, want %v", tt.version, tt.latest, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package model
+
+// Decision constants for policy explanations.
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+)
+
+// Condition kind constants for policy explanations.
+const (
+	ConditionKindWhen   = "when"
+	ConditionKindUnless = "unless"
+)
+
+// PolicyExplanation describes how a policy decision was reached for a
+// single repository and action.
+type PolicyExplanation struct {
+	Repo      string         `json:"repo"`
+	Action    string         `json:"action"`
+	Principal string         `json:"principal"`
+	Decision  string         `json:"decision"`
+	Context   map[string]any `json:"context"`
+	Policies  []PolicyTrace  `json:"policies"`
+}
+
+// PolicyTrace records how a single policy evaluated against a request.
+type PolicyTrace struct {
+	ID           string           `json:"id"`
+	Effect       string           `json:"effect"`
+	Scope        string           `json:"scope"`
+	ScopeMatched bool             `json:"scopeMatched"`
+	Conditions   []ConditionTrace `json:"conditions,omitempty"`
+	// Satisfied is true when the scope matched and every condition passed.
+	Satisfied bool `json:"satisfied"`
+	// Determining is true when the policy contributed to the final decision.
+	Determining bool   `json:"determining"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ConditionTrace records how a single when/unless clause evaluated.
+type ConditionTrace struct {
+	Kind string `json:"kind"`
+	Expr string `json:"expr"`
+	// Value is the result of the clause expression; nil if evaluation failed.
+	Value *bool `json:"value,omitempty"`
+	// Passed is true when the clause does not prevent the policy from applying.
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DeterminingPolicies returns the IDs of policies that determined the decision.
+func (e *PolicyExplanation) DeterminingPolicies() []string {
+	var ids []string
+	for _, p := range e.Policies {
+		if p.Determining {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
@@ -1,5 +1,7 @@
 package model
 
+import "strconv"
+
 // PolicyContext is the canonical context passed to Cedar for policy evaluation.
 type PolicyContext struct {
 	Repo             RepoContext             `json:"repo"`
@@ -8,6 +10,7 @@ type PolicyContext struct {
 	Dependencies     DependenciesContext     `json:"dependencies"`
 	BranchProtection BranchProtectionContext `json:"branchProtection"`
 	Compliance       ComplianceContext       `json:"compliance"`
+	Files            FilesContext            `json:"files"`
 }
 
 // RepoContext contains repository information for policy evaluation.
@@ -29,6 +32,18 @@ type CIContext struct {
 	RequiredChecks       []string `json:"requiredChecks"`
 	LastRunPassed        bool     `json:"lastRunPassed"`
 	OSMatrix             []string `json:"osMatrix"`
+	// ProducedChecks are the check names workflow jobs report.
+	ProducedChecks []string `json:"producedChecks"`
+	// MissingRequiredChecks are required checks no workflow produces.
+	MissingRequiredChecks []string `json:"missingRequiredChecks"`
+	// UnrequiredJobs are important jobs whose checks are not required.
+	UnrequiredJobs []string `json:"unrequiredJobs"`
+	// Run history on the default branch.
+	HasRunHistory        bool `json:"hasRunHistory"`
+	RunPassRate          int  `json:"runPassRate"`
+	FlakyRunCount        int  `json:"flakyRunCount"`
+	MedianRunSeconds     int  `json:"medianRunSeconds"`
+	DaysSinceLastSuccess int  `json:"daysSinceLastSuccess"`
 }
 
 // GoContext contains Go-specific information for policy evaluation.
@@ -44,16 +59,35 @@ type DependenciesContext struct {
 	HasRenovate          bool `json:"hasRenovate"`
 	HasDependabot        bool `json:"hasDependabot"`
 	OldestDependencyDays int  `json:"oldestDependencyDays"`
+	DependencyCount      int  `json:"dependencyCount"`
+	OutdatedCount        int  `json:"outdatedCount"`
 	HasVulnerabilities   bool `json:"hasVulnerabilities"`
 	VulnerabilityCount   int  `json:"vulnerabilityCount"`
+	// UpdateEcosystems lists ecosystems covered by Renovate or Dependabot.
+	UpdateEcosystems []string `json:"updateEcosystems"`
+	// UncoveredEcosystems lists ecosystems the repository's languages
+	// need that no update tool covers.
+	UncoveredEcosystems []string `json:"uncoveredEcosystems"`
+	UpdateAutomerge     bool     `json:"updateAutomerge"`
+	UpdateSchedule      bool     `json:"updateSchedule"`
+	ExtendsOrgPreset    bool     `json:"extendsOrgPreset"`
 }
 
 // BranchProtectionContext contains branch protection info for policy evaluation.
 type BranchProtectionContext struct {
-	Enabled             bool `json:"enabled"`
-	RequireReviews      bool `json:"requireReviews"`
-	RequireStatusChecks bool `json:"requireStatusChecks"`
-	EnforceAdmins       bool `json:"enforceAdmins"`
+	Enabled                       bool     `json:"enabled"`
+	RequireReviews                bool     `json:"requireReviews"`
+	RequiredReviewers             int      `json:"requiredReviewers"`
+	RequireStatusChecks           bool     `json:"requireStatusChecks"`
+	RequiredStatusChecks          []string `json:"requiredStatusChecks"`
+	EnforceAdmins                 bool     `json:"enforceAdmins"`
+	RequireSignedCommits          bool     `json:"requireSignedCommits"`
+	RequireLinearHistory          bool     `json:"requireLinearHistory"`
+	AllowForcePushes              bool     `json:"allowForcePushes"`
+	AllowDeletions                bool     `json:"allowDeletions"`
+	RequireCodeOwnerReviews       bool     `json:"requireCodeOwnerReviews"`
+	DismissStaleReviews           bool     `json:"dismissStaleReviews"`
+	RequireConversationResolution bool     `json:"requireConversationResolution"`
 }
 
 // ComplianceContext contains workflow compliance information for policy evaluation.
@@ -76,19 +110,39 @@ type ComplianceContext struct {
 	ExactMatchCount int `json:"exactMatchCount"`
 	// EquivalentMatchCount is the number of equivalent matches (same function, not reusable)
 	EquivalentMatchCount int `json:"equivalentMatchCount"`
+	// InactiveWorkflows lists required workflow types that are present but
+	// disabled or not running
+	InactiveWorkflows []string `json:"inactiveWorkflows"`
 	// RefRepo is the reference repository for compliance checking
 	RefRepo string `json:"refRepo"`
 }
 
+// FilesContext contains repository file hygiene information for policy evaluation.
+type FilesContext struct {
+	HasCodeowners   bool `json:"hasCodeowners"`
+	CodeownersValid bool `json:"codeownersValid"`
+	HasLicense      bool `json:"hasLicense"`
+	// LicenseSPDX is the detected SPDX identifier, empty if unrecognized.
+	LicenseSPDX        string `json:"licenseSpdx"`
+	HasSecurityPolicy  bool   `json:"hasSecurityPolicy"`
+	HasGolangciConfig  bool   `json:"hasGolangciConfig"`
+	GolangciExtendsOrg bool   `json:"golangciExtendsOrg"`
+	// MissingReadmeBadges lists workflow files without a README status badge.
+	MissingReadmeBadges []string `json:"missingReadmeBadges"`
+	HasEditorconfig     bool     `json:"hasEditorconfig"`
+}
+
 // Profile defines a named CI/CD configuration profile.
 type Profile struct {
-	Name        string        `json:"name" yaml:"name"`
-	Description string        `json:"description" yaml:"description"`
-	Go          ProfileGo     `json:"go" yaml:"go"`
-	OS          []string      `json:"os" yaml:"os"`
-	Checks      ProfileChecks `json:"checks" yaml:"checks"`
-	Lint        ProfileLint   `json:"lint" yaml:"lint"`
-	Test        ProfileTest   `json:"test" yaml:"test"`
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description" yaml:"description"`
+	Extends     []string          `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Go          ProfileGo         `json:"go" yaml:"go"`
+	OS          []string          `json:"os" yaml:"os"`
+	Checks      ProfileChecks     `json:"checks" yaml:"checks"`
+	Lint        ProfileLint       `json:"lint" yaml:"lint"`
+	Test        ProfileTest       `json:"test" yaml:"test"`
+	Evaluation  ProfileEvaluation `json:"evaluation" yaml:"evaluation"`
 }
 
 // ProfileGo contains Go-specific profile settings.
@@ -112,3 +166,33 @@ type ProfileTest struct {
 	Coverage bool `json:"coverage" yaml:"coverage"`
 	Race     bool `json:"race" yaml:"race"`
 }
+
+// ProfileEvaluation configures which actions and principals are evaluated
+// against Cedar policies. Empty lists fall back to the engine defaults.
+type ProfileEvaluation struct {
+	Actions    []string    `json:"actions,omitempty" yaml:"actions,omitempty"`
+	Principals []Principal `json:"principals,omitempty" yaml:"principals,omitempty"`
+}
+
+// Principal identifies an actor (CI system, bot, user, or team) whose
+// actions are evaluated against policies.
+type Principal struct {
+	Type string `json:"type" yaml:"type"`
+	ID   string `json:"id" yaml:"id"`
+	// Teams lists team IDs the principal belongs to, enabling
+	// `principal in Team::"name"` conditions.
+	Teams []string `json:"teams,omitempty" yaml:"teams,omitempty"`
+}
+
+// Principal type constants.
+const (
+	PrincipalTypeCISystem = "CISystem"
+	PrincipalTypeBot      = "Bot"
+	PrincipalTypeUser     = "User"
+	PrincipalTypeTeam     = "Team"
+)
+
+// String returns the Cedar entity reference for the principal.
+func (p Principal) String() string {
+	return p.Type + "::" + strconv.Quote(p.ID)
+}
@@ -47,6 +47,67 @@ type BranchProtection struct {
 	RequireSignedCommits bool     `json:"requireSignedCommits"`
 	AllowForcePushes     bool     `json:"allowForcePushes"`
 	AllowDeletions       bool     `json:"allowDeletions"`
+	RequireLinearHistory bool     `json:"requireLinearHistory"`
+	// RequireCodeOwnerReviews requires approval from code owners.
+	RequireCodeOwnerReviews bool `json:"requireCodeOwnerReviews"`
+	// DismissStaleReviews dismisses approvals when new commits are pushed.
+	DismissStaleReviews bool `json:"dismissStaleReviews"`
+	// RequireConversationResolution requires review threads to be resolved.
+	RequireConversationResolution bool `json:"requireConversationResolution"`
+	// BypassActors lists actors allowed to bypass ruleset rules.
+	BypassActors []BypassActor `json:"bypassActors,omitempty"`
+	// Rules records where each effective rule comes from: classic branch
+	// protection or a repository, organization, or enterprise ruleset.
+	Rules []ProtectionRule `json:"rules,omitempty"`
+	// Warnings note rulesets that could not be read. BypassActors may then
+	// be incomplete, and EnforceAdmins is not derived from them.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Protection rule sources.
+const (
+	ProtectionSourceClassic      = "classic"
+	ProtectionSourceRepository   = "Repository"
+	ProtectionSourceOrganization = "Organization"
+	ProtectionSourceEnterprise   = "Enterprise"
+)
+
+// ProtectionRule is a single effective protection rule and its source.
+type ProtectionRule struct {
+	// Rule is the rule type, e.g. "pull_request", "required_status_checks",
+	// "required_signatures", "required_linear_history", "non_fast_forward",
+	// or "deletion".
+	Rule string `json:"rule"`
+	// SourceType is "classic" or the ruleset source type.
+	SourceType string `json:"sourceType"`
+	// Source is the repository or organization that owns the ruleset.
+	Source      string `json:"source,omitempty"`
+	RulesetID   int64  `json:"rulesetId,omitempty"`
+	RulesetName string `json:"rulesetName,omitempty"`
+}
+
+// BypassActor is an actor allowed to bypass a ruleset.
+type BypassActor struct {
+	// Type is the actor type, e.g. "OrganizationAdmin", "RepositoryRole",
+	// "Team", "Integration", or "DeployKey".
+	Type string `json:"type"`
+	ID   int64  `json:"id,omitempty"`
+	// Mode is "always", "pull_request", or "exempt".
+	Mode        string `json:"mode,omitempty"`
+	RulesetID   int64  `json:"rulesetId"`
+	RulesetName string `json:"rulesetName,omitempty"`
+}
+
+// RuleSources returns the sources of a rule type, e.g. ["classic",
+// "Organization"], in the order they were recorded.
+func (bp *BranchProtection) RuleSources(rule string) []string {
+	var sources []string
+	for _, r := range bp.Rules {
+		if r.Rule == rule {
+			sources = append(sources, r.SourceType)
+		}
+	}
+	return sources
 }
 
 // Matches returns true if the repo matches the filter criteria.
@@ -23,14 +23,19 @@ type ScanSummary struct {
 
 // RepoResult is the compliance result for a single repository.
 type RepoResult struct {
-	Repo       Repo        `json:"repo"`
-	Compliant  bool        `json:"compliant"`
-	Violations []Violation `json:"violations,omitempty"`
-	Warnings   []Warning   `json:"warnings,omitempty"`
-	Skipped    bool        `json:"skipped"`
-	SkipReason string      `json:"skipReason,omitempty"`
-	Error      string      `json:"error,omitempty"`
-	ScanTimeMs int64       `json:"scanTimeMs"`
+	Repo            Repo                `json:"repo"`
+	Compliant       bool                `json:"compliant"`
+	Violations      []Violation         `json:"violations,omitempty"`
+	Warnings        []Warning           `json:"warnings,omitempty"`
+	Explanations    []PolicyExplanation `json:"explanations,omitempty"`
+	Profile         string              `json:"profile,omitempty"`
+	ProfileSource   string              `json:"profileSource,omitempty"`
+	Vulnerabilities []Vulnerability     `json:"vulnerabilities,omitempty"`
+	CIHealth        *CIHealth           `json:"ciHealth,omitempty"`
+	Skipped         bool                `json:"skipped"`
+	SkipReason      string              `json:"skipReason,omitempty"`
+	Error           string              `json:"error,omitempty"`
+	ScanTimeMs      int64               `json:"scanTimeMs"`
 }
 
 // Violation represents a policy violation.
@@ -79,6 +84,8 @@ type RemediationPlan struct {
 	PRTitle string  `json:"prTitle"`
 	PRBody  string  `json:"prBody"`
 	DryRun  bool    `json:"dryRun"`
+	// Branch, when set, overrides the applier's configured branch.
+	Branch string `json:"branch,omitempty"`
 }
 
 // Patch represents a file change.
@@ -95,8 +102,91 @@ type RemediationResult struct {
 	Success  bool   `json:"success"`
 	PRURL    string `json:"prUrl,omitempty"`
 	PRNumber int    `json:"prNumber,omitempty"`
+	Branch   string `json:"branch,omitempty"`
 	Error    string `json:"error,omitempty"`
 	DryRun   bool   `json:"dryRun"`
+	// BaseSHA is the commit the changes were applied on top of, and
+	// CommitSHA the commit that contains them.
+	BaseSHA   string `json:"baseSha,omitempty"`
+	CommitSHA string `json:"commitSha,omitempty"`
+	// Previous holds the pre-change contents of every patched file, so the
+	// remediation can be rolled back.
+	Previous []FileSnapshot `json:"previous,omitempty"`
+	// Rollback marks the result of rolling a remediation back.
+	Rollback bool `json:"rollback,omitempty"`
+}
+
+// FileSnapshot is the content of a file before a remediation changed it.
+type FileSnapshot struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+	Content string `json:"content,omitempty"`
+}
+
+// Remediation pull request states.
+const (
+	PRStateOpen   = "open"
+	PRStateMerged = "merged"
+	PRStateClosed = "closed"
+)
+
+// TrackedPR is a remediation pull request whose status is tracked.
+type TrackedPR struct {
+	Repo          Repo     `json:"repo"`
+	Number        int      `json:"number"`
+	URL           string   `json:"url"`
+	Branch        string   `json:"branch"`
+	WorkflowTypes []string `json:"workflowTypes,omitempty"`
+	Campaign      string   `json:"campaign,omitempty"`
+	// PatchHash identifies the patch content the pull request was opened
+	// with. A different hash for a regenerated plan means the reference
+	// templates changed.
+	PatchHash     string    `json:"patchHash,omitempty"`
+	State         string    `json:"state"`
+	ChecksFailing bool      `json:"checksFailing,omitempty"`
+	Conflicts     bool      `json:"conflicts,omitempty"`
+	OpenedAt      time.Time `json:"openedAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	ClosedReason  string    `json:"closedReason,omitempty"`
+}
+
+// RemediationStatus is the tracked state of remediation pull requests.
+type RemediationStatus struct {
+	UpdatedAt time.Time   `json:"updatedAt"`
+	PRs       []TrackedPR `json:"prs"`
+}
+
+// RemediationStatusSummary counts tracked pull requests by state.
+type RemediationStatusSummary struct {
+	Total         int `json:"total"`
+	Open          int `json:"open"`
+	Merged        int `json:"merged"`
+	Closed        int `json:"closed"`
+	ChecksFailing int `json:"checksFailing"`
+	Conflicts     int `json:"conflicts"`
+}
+
+// Summary counts the tracked pull requests. Failing checks and conflicts
+// are only counted for open pull requests.
+func (s *RemediationStatus) Summary() RemediationStatusSummary {
+	sum := RemediationStatusSummary{Total: len(s.PRs)}
+	for _, pr := range s.PRs {
+		switch pr.State {
+		case PRStateOpen:
+			sum.Open++
+			if pr.ChecksFailing {
+				sum.ChecksFailing++
+			}
+			if pr.Conflicts {
+				sum.Conflicts++
+			}
+		case PRStateMerged:
+			sum.Merged++
+		case PRStateClosed:
+			sum.Closed++
+		}
+	}
+	return sum
 }
 
 // IsCompliant returns true if there are no violations.
@@ -7,6 +7,8 @@ import (
 
 // Workflow represents a CI/CD workflow configuration.
 type Workflow struct {
+	// ID is the GitHub Actions workflow ID, used to list its runs.
+	ID                   int64                 `json:"id,omitempty"`
 	Name                 string                `json:"name"`
 	Path                 string                `json:"path"`
 	Content              string                `json:"content,omitempty"`
@@ -49,6 +51,15 @@ type MatrixConfig struct {
 	Include       []map[string]string `json:"include,omitempty"`
 	Exclude       []map[string]string `json:"exclude,omitempty"`
 	FailFast      bool                `json:"failFast"`
+	// Dimensions lists every matrix key and its values in declaration order,
+	// which determines how GitHub names the expanded jobs.
+	Dimensions []MatrixDimension `json:"dimensions,omitempty"`
+}
+
+// MatrixDimension is a single matrix key and its values.
+type MatrixDimension struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
 }
 
 // ReusableWorkflowRef represents a reference to a reusable workflow.
@@ -60,18 +71,66 @@ type ReusableWorkflowRef struct {
 	FullRef string `json:"fullRef"`
 }
 
-// WorkflowRun represents a workflow execution.
+// WorkflowRun represents a workflow execution. RunAttempt is greater than 1
+// when the run was re-run.
 type WorkflowRun struct {
-	ID         int64     `json:"id"`
-	WorkflowID int64     `json:"workflowId"`
-	Name       string    `json:"name"`
-	Status     string    `json:"status"`
-	Conclusion string    `json:"conclusion"`
-	Branch     string    `json:"branch"`
-	HeadSHA    string    `json:"headSha"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
-	HTMLURL    string    `json:"htmlUrl"`
+	ID           int64     `json:"id"`
+	WorkflowID   int64     `json:"workflowId"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	Branch       string    `json:"branch"`
+	HeadSHA      string    `json:"headSha"`
+	RunAttempt   int       `json:"runAttempt,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	RunStartedAt time.Time `json:"runStartedAt,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	HTMLURL      string    `json:"htmlUrl"`
+}
+
+// Duration returns how long the latest attempt of the run took.
+func (r WorkflowRun) Duration() time.Duration {
+	start := r.RunStartedAt
+	if start.IsZero() {
+		start = r.CreatedAt
+	}
+	if start.IsZero() || !r.UpdatedAt.After(start) {
+		return 0
+	}
+	return r.UpdatedAt.Sub(start)
+}
+
+// RunStats summarizes completed workflow runs.
+type RunStats struct {
+	Runs   int `json:"runs"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// PassRate is the percentage of passed runs among passed and failed
+	// runs. Cancelled and skipped runs are not counted.
+	PassRate         float64   `json:"passRate"`
+	LastRunPassed    bool      `json:"lastRunPassed"`
+	LastRunAt        time.Time `json:"lastRunAt,omitempty"`
+	LastSuccessAt    time.Time `json:"lastSuccessAt,omitempty"`
+	MedianDurationMs int64     `json:"medianDurationMs"`
+	// FlakyRuns counts successful runs of a commit that had already failed,
+	// either as a re-run attempt or a new run on the same SHA.
+	FlakyRuns int `json:"flakyRuns"`
+}
+
+// WorkflowHealth is the run history summary of one workflow.
+type WorkflowHealth struct {
+	Workflow string `json:"workflow"`
+	Path     string `json:"path"`
+	RunStats
+}
+
+// CIHealth is the run history summary of a repository's workflows on one
+// branch.
+type CIHealth struct {
+	Branch      string           `json:"branch"`
+	CollectedAt time.Time        `json:"collectedAt"`
+	Workflows   []WorkflowHealth `json:"workflows"`
+	RunStats
 }
 
 // ParseReusableWorkflowRef parses a reusable workflow reference string.